@@ -15,8 +15,11 @@ import (
 	"github.com/BigKAA/dephealth-ui/internal/cache"
 	"github.com/BigKAA/dephealth-ui/internal/config"
 	"github.com/BigKAA/dephealth-ui/internal/grafana"
+	"github.com/BigKAA/dephealth-ui/internal/httpclient"
 	"github.com/BigKAA/dephealth-ui/internal/logging"
+	"github.com/BigKAA/dephealth-ui/internal/notify"
 	"github.com/BigKAA/dephealth-ui/internal/server"
+	"github.com/BigKAA/dephealth-ui/internal/snapshot"
 	"github.com/BigKAA/dephealth-ui/internal/topology"
 )
 
@@ -44,22 +47,49 @@ func main() {
 	logger.Info("starting dephealth-ui",
 		"listen", cfg.Server.Listen,
 		"prometheus", cfg.Datasources.Prometheus.URL,
-		"alertmanager", cfg.Datasources.Alertmanager.URL,
+		"prometheusSources", len(cfg.Datasources.PrometheusSources),
+		"alertmanager", cfg.Datasources.Alertmanager.Endpoints(),
 	)
 
 	// Check Grafana dashboard availability at startup.
 	checkGrafanaDashboards(cfg, logger)
 
-	promClient := topology.NewPrometheusClient(topology.PrometheusConfig{
-		URL:      cfg.Datasources.Prometheus.URL,
-		Username: cfg.Datasources.Prometheus.Username,
-		Password: cfg.Datasources.Prometheus.Password,
-	})
+	var sources []topology.Source
+	for _, ds := range cfg.Datasources.PrometheusList() {
+		transport, err := httpclient.NewTransport(ds.HTTPClientConfig)
+		if err != nil {
+			logger.Error("failed to configure prometheus client", "source", ds.Name, "error", err)
+			os.Exit(1)
+		}
+		sources = append(sources, topology.Source{
+			Name: ds.Name,
+			Prom: topology.NewPrometheusClient(topology.PrometheusConfig{
+				URL:            ds.URL,
+				Username:       ds.Username,
+				Password:       ds.Password,
+				LatencyWindow:  cfg.Topology.Latency.Window,
+				Retry:          cfg.Datasources.Resilience.Retry,
+				CircuitBreaker: cfg.Datasources.Resilience.CircuitBreaker,
+				Transport:      transport,
+				Tenant:         ds.Tenant,
+				Metrics:        cfg.Metrics,
+			}),
+		})
+	}
 
+	alertMapping := newAlertMapping(cfg.Alerts)
+	amTransport, err := httpclient.NewTransport(cfg.Datasources.Alertmanager.HTTPClientConfig)
+	if err != nil {
+		logger.Error("failed to configure alertmanager client", "error", err)
+		os.Exit(1)
+	}
 	amClient := alerts.NewClient(alerts.Config{
-		URL:      cfg.Datasources.Alertmanager.URL,
-		Username: cfg.Datasources.Alertmanager.Username,
-		Password: cfg.Datasources.Alertmanager.Password,
+		URL:       cfg.Datasources.Alertmanager.URL,
+		URLs:      cfg.Datasources.Alertmanager.URLs,
+		Username:  cfg.Datasources.Alertmanager.Username,
+		Password:  cfg.Datasources.Alertmanager.Password,
+		Transport: amTransport,
+		Mapping:   alertMapping,
 	})
 
 	grafanaCfg := topology.GrafanaConfig{
@@ -71,7 +101,16 @@ func main() {
 		LinksStatusDashUID:    cfg.Grafana.Dashboards.LinksStatus,
 	}
 
-	builder := topology.NewGraphBuilder(promClient, amClient, grafanaCfg, cfg.Cache.TTL, cfg.Topology.Lookback, logger, cfg.Alerts.SeverityLevels)
+	builder := topology.NewGraphBuilder(sources[0].Prom, amClient, grafanaCfg, cfg.Cache.TTL, cfg.Topology.Lookback, logger, cfg.Alerts.SeverityLevels)
+	builder.SetSources(sources)
+	builder.SetLatencyThresholds(topology.LatencyThresholds{
+		Percentile: cfg.Topology.Latency.Percentile,
+		ByType:     cfg.Topology.Latency.DegradedThresholds,
+	})
+	builder.SetThresholdRules(cfg.Topology.Thresholds)
+	builder.SetFlapping(cfg.Topology.Flapping)
+	builder.SetQueryBudgets(cfg.Topology.Queries)
+	builder.SetAlertMapping(alertMapping)
 
 	topologyCache := cache.New(cfg.Cache.TTL)
 
@@ -81,7 +120,32 @@ func main() {
 		os.Exit(1)
 	}
 
-	srv := server.New(cfg, logger, builder, promClient, amClient, topologyCache, authenticator)
+	srv := server.New(cfg, logger, builder, amClient, topologyCache, authenticator)
+
+	if cfg.Snapshots.Enabled {
+		rules := make([]snapshot.DownsampleRule, len(cfg.Snapshots.Downsampling))
+		for i, r := range cfg.Snapshots.Downsampling {
+			rules[i] = snapshot.DownsampleRule{After: r.After, Resolution: r.Resolution}
+		}
+		store, err := snapshot.Open(cfg.Snapshots.Dir, snapshot.Options{
+			Interval:     cfg.Snapshots.Interval,
+			Retention:    cfg.Snapshots.Retention,
+			Downsampling: rules,
+		})
+		if err != nil {
+			logger.Error("failed to open snapshot store", "error", err)
+			os.Exit(1)
+		}
+		srv.SetSnapshotStore(store)
+		logger.Info("topology snapshot store enabled", "dir", cfg.Snapshots.Dir,
+			"snapshots", store.Len(), "interval", cfg.Snapshots.Interval, "retention", cfg.Snapshots.Retention)
+	}
+
+	if len(cfg.Notifications.Webhooks) > 0 {
+		srv.SetNotifier(notify.New(cfg.Notifications, logger))
+		logger.Info("webhook notifications enabled", "webhooks", len(cfg.Notifications.Webhooks),
+			"batchWindow", cfg.Notifications.BatchWindow)
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
@@ -92,6 +156,32 @@ func main() {
 	}
 }
 
+// newAlertMapping converts the alerts.mapping config section to an
+// alerts.Mapping. Empty service labels fall back to the built-in mapping.
+func newAlertMapping(cfg config.AlertsConfig) alerts.Mapping {
+	m := cfg.Mapping
+	if len(m.ServiceLabels) == 0 {
+		return alerts.DefaultMapping()
+	}
+	mapping := alerts.Mapping{
+		ServiceLabels:    m.ServiceLabels,
+		DependencyLabels: m.DependencyLabels,
+		NamespaceLabels:  m.NamespaceLabels,
+		HostLabels:       m.HostLabels,
+		PortLabels:       m.PortLabels,
+		EndpointLabels:   m.EndpointLabels,
+		SeverityLabel:    cfg.SeverityLabel,
+	}
+	for _, r := range m.StateRules {
+		mapping.StateRules = append(mapping.StateRules, alerts.StateRule{
+			AlertName: r.AlertName,
+			Matchers:  r.Matchers,
+			State:     r.State,
+		})
+	}
+	return mapping
+}
+
 // checkGrafanaDashboards validates configured Grafana dashboards at startup.
 // Unavailable dashboards are cleared from the config so downstream code
 // (which checks for empty UID) hides the corresponding links.
@@ -100,11 +190,18 @@ func checkGrafanaDashboards(cfg *config.Config, logger *slog.Logger) {
 		return
 	}
 
+	transport, err := httpclient.NewTransport(cfg.Grafana.HTTPClientConfig)
+	if err != nil {
+		logger.Warn("invalid grafana client settings, hiding all dashboard links", "error", err)
+		cfg.Grafana.Dashboards = config.DashboardsConfig{}
+		return
+	}
 	checker := grafana.NewChecker(grafana.Config{
-		BaseURL:  cfg.Grafana.BaseURL,
-		Token:    cfg.Grafana.Token,
-		Username: cfg.Grafana.Username,
-		Password: cfg.Grafana.Password,
+		BaseURL:   cfg.Grafana.BaseURL,
+		Token:     cfg.Grafana.Token,
+		Username:  cfg.Grafana.Username,
+		Password:  cfg.Grafana.Password,
+		Transport: transport,
 	})
 
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
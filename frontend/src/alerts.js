@@ -63,13 +63,22 @@ export function updateAlertDrawer(alerts, levels) {
 }
 
 /**
- * Update alert count badge on header button
+ * Whether an alert is silenced or inhibited in AlertManager
+ * @param {Object} alert
+ * @returns {boolean}
+ */
+export function isAcknowledged(alert) {
+  return alert.state === 'silenced' || alert.state === 'inhibited';
+}
+
+/**
+ * Update alert count badge on header button (active alerts only)
  */
 function updateBadge() {
   const badge = document.getElementById('alert-badge');
   if (!badge) return;
 
-  const count = alertsData.length;
+  const count = alertsData.filter((a) => !isAcknowledged(a)).length;
   if (count > 0) {
     badge.textContent = count > 99 ? '99+' : count;
     badge.classList.remove('hidden');
@@ -174,6 +183,23 @@ function createAlertItem(alert, color) {
 
   item.appendChild(meta);
 
+  // Acknowledged marker with silence details
+  if (isAcknowledged(alert)) {
+    item.classList.add('alert-acknowledged');
+    const ack = document.createElement('div');
+    ack.className = 'alert-ack';
+    if (alert.state === 'silenced') {
+      ack.textContent = t('alerts.silenced', {
+        createdBy: alert.silenceCreatedBy || '?',
+        endsAt: alert.silenceEndsAt ? new Date(alert.silenceEndsAt).toLocaleString() : '?',
+      });
+      if (alert.silenceId) ack.title = alert.silenceId;
+    } else {
+      ack.textContent = t('alerts.inhibited');
+    }
+    item.appendChild(ack);
+  }
+
   // Click to navigate to node
   if (alert.service) {
     item.style.cursor = 'pointer';
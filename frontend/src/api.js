@@ -117,6 +117,25 @@ export async function fetchInstances(serviceName) {
   return resp.json();
 }
 
+/**
+ * Create an AlertManager silence for a service, optionally narrowed to a
+ * dependency and namespace.
+ * @param {{service: string, dependency?: string, namespace?: string, duration: string, comment: string}} req
+ * @returns {Promise<{id: string, endsAt: string, createdBy: string}>}
+ */
+export async function createSilence(req) {
+  const resp = await authenticatedFetch('/api/v1/silences', {
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json' },
+    body: JSON.stringify(req),
+  });
+  if (!resp.ok) {
+    const body = await resp.json().catch(() => ({}));
+    throw new Error(body.error || `Silences API error: ${resp.status} ${resp.statusText}`);
+  }
+  return resp.json();
+}
+
 /**
  * Retry a function with exponential backoff.
  * @param {Function} fn - async function to retry
@@ -5,12 +5,14 @@ import { showToast } from './toast.js';
 import { openSidebar, openEdgeSidebar } from './sidebar.js';
 import { expandNamespace } from './grouping.js';
 import { isHistoryMode, getSelectedTime } from './timeline.js';
+import { createSilence } from './api.js';
 
 const $ = (sel) => document.querySelector(sel);
 
 let menuEl = null;
 let cyInstance = null;
 let grafanaConfig = null;
+let alertsEnabled = false;
 
 /**
  * Append historical time range to a Grafana URL when in history mode.
@@ -36,6 +38,24 @@ export function setContextMenuGrafanaConfig(config) {
   if (config && config.grafana) {
     grafanaConfig = config.grafana;
   }
+  alertsEnabled = !!(config && config.alerts && config.alerts.enabled);
+}
+
+/**
+ * Ask for duration and comment, then silence alerts matching the target.
+ * @param {{service: string, dependency?: string, namespace?: string}} target
+ */
+async function silenceAlerts(target) {
+  const duration = window.prompt(t('contextMenu.silenceDuration'), '2h');
+  if (!duration) return;
+  const comment = window.prompt(t('contextMenu.silenceComment'));
+  if (!comment) return;
+  try {
+    await createSilence({ ...target, duration, comment });
+    showToast(t('contextMenu.silenceCreated'), 'success');
+  } catch (err) {
+    showToast(t('contextMenu.silenceFailed', { error: err.message }), 'error');
+  }
 }
 
 /**
@@ -85,6 +105,14 @@ export function initContextMenu(cy) {
       });
     }
 
+    if (alertsEnabled && !isHistoryMode()) {
+      items.push({
+        label: t('contextMenu.silenceAlerts'),
+        icon: 'bi-bell-slash',
+        action: () => silenceAlerts({ service: data.label, namespace: data.namespace }),
+      });
+    }
+
     items.push({
       label: t('contextMenu.showDetails'),
       icon: 'bi-info-circle',
@@ -156,6 +184,15 @@ export function initContextMenu(cy) {
       });
     }
 
+    if (alertsEnabled && !isHistoryMode() && data.dependency) {
+      const source = edge.source().data();
+      items.push({
+        label: t('contextMenu.silenceAlerts'),
+        icon: 'bi-bell-slash',
+        action: () => silenceAlerts({ service: source.label, dependency: data.dependency, namespace: source.namespace }),
+      });
+    }
+
     items.push({
       label: t('contextMenu.showDetails'),
       icon: 'bi-info-circle',
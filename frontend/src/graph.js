@@ -5,6 +5,7 @@ import { isElementVisible } from './search.js';
 import { isEdgeLabelsEnabled } from './main.js';
 import { getNamespaceColor, getContrastTextColor, getStripeDataUri, extractNamespaceFromHost } from './namespace.js';
 import { isGroupingEnabled, buildCompoundElements, getGroupingDimension } from './grouping.js';
+import { isAcknowledged } from './alerts.js';
 import {
   hasSavedPositions, applySavedPositions, saveAutoPositions,
   pruneStalePositions, clearSavedPositions, clearManualFlags,
@@ -291,6 +292,7 @@ const cytoscapeStyles = [
         }
         const abbr = STATUS_ABBREVIATIONS[ele.data('status')] || '';
         if (abbr) parts.push(abbr);
+        if (ele.data('flapping')) parts.push('↯');
         const latency = ele.data('latency') || '';
         if (latency) parts.push(latency);
         return parts.join(' ');
@@ -304,6 +306,15 @@ const cytoscapeStyles = [
       cursor: 'pointer',
     },
   },
+  // Edges on a cycle of critical edges: cascade root causes are ambiguous there
+  {
+    selector: 'edge[?criticalCycle]',
+    style: {
+      'underlay-color': '#e040fb',
+      'underlay-opacity': 0.35,
+      'underlay-padding': 4,
+    },
+  },
   // Stale nodes get dashed border
   {
     selector: 'node[?stale]',
@@ -484,6 +495,23 @@ function updateAlertBadges(cy, container) {
     }));
   });
 
+  // Acknowledged (silenced/inhibited) alert badges (bottom-right corner)
+  cy.nodes('[ackCount > 0]').forEach((node) => {
+    if (!isElementVisible(node)) return;
+
+    const pos = node.renderedPosition();
+    const w = node.renderedWidth();
+    const h = node.renderedHeight();
+
+    container.appendChild(createBadge({
+      className: 'ack-badge',
+      x: pos.x + w / 2 - 10,
+      y: pos.y + h / 2 - 10,
+      scale: badgeScale,
+      text: `✓ ${node.data('ackCount')}`,
+    }));
+  });
+
   // Cascade warning badges (top-left corner, offset for namespace stripe)
   cy.nodes('[cascadeCount > 0]').forEach((node) => {
     if (!isElementVisible(node)) return;
@@ -696,12 +724,18 @@ export function renderGraph(cy, data, config) {
   const structureChanged = signature !== lastStructureSignature;
   lastStructureSignature = signature;
 
-  // Count alerts per node (service = source). Skip when AlertManager is disabled.
+  // Count alerts per node (service = source). Silenced and inhibited alerts
+  // are counted separately as acknowledged. Skip when AlertManager is disabled.
   const alertCounts = {};
+  const ackCounts = {};
   const alertsEnabled = config && config.alerts && config.alerts.enabled;
   if (alertsEnabled && data.alerts) {
     for (const a of data.alerts) {
-      alertCounts[a.service] = (alertCounts[a.service] || 0) + 1;
+      if (isAcknowledged(a)) {
+        ackCounts[a.service] = (ackCounts[a.service] || 0) + 1;
+      } else {
+        alertCounts[a.service] = (alertCounts[a.service] || 0) + 1;
+      }
     }
   }
 
@@ -715,6 +749,7 @@ export function renderGraph(cy, data, config) {
           ele.data('stale', node.stale || false);
           ele.data('alertCount', alertCounts[node.id] || 0);
           ele.data('alertSeverity', node.alertSeverity || undefined);
+          ele.data('ackCount', ackCounts[node.id] || 0);
         }
       }
       for (const edge of data.edges) {
@@ -730,8 +765,13 @@ export function renderGraph(cy, data, config) {
           ele.data('critical', edge.critical);
           ele.data('status', edge.status || undefined);
           ele.data('detail', edge.detail || undefined);
+          ele.data('flapping', edge.flapping || false);
+          ele.data('transitions', edge.transitions || 0);
+          ele.data('inCycle', edge.inCycle || false);
+          ele.data('criticalCycle', edge.criticalCycle || false);
           ele.data('alertCount', alertsEnabled ? (edge.alertCount || 0) : 0);
           ele.data('alertSeverity', alertsEnabled ? (edge.alertSeverity || undefined) : undefined);
+          ele.data('ackCount', alertsEnabled ? (edge.acknowledgedCount || 0) : 0);
         }
       }
     });
@@ -770,6 +810,7 @@ export function renderGraph(cy, data, config) {
         port: node.port || undefined,
         alertCount: alertsEnabled ? (alertCounts[node.id] || 0) : 0,
         alertSeverity: alertsEnabled ? (node.alertSeverity || undefined) : undefined,
+        ackCount: alertsEnabled ? (ackCounts[node.id] || 0) : 0,
         grafanaUrl: node.grafanaUrl || undefined,
         isEntry: node.isEntry || false,
       };
@@ -788,6 +829,7 @@ export function renderGraph(cy, data, config) {
           source: edge.source,
           target: edge.target,
           type: edge.type || undefined,
+          dependency: edge.dependency || undefined,
           latency: edge.latency,
           latencyRaw: edge.latencyRaw || 0,
           health: edge.health ?? -1,
@@ -796,8 +838,13 @@ export function renderGraph(cy, data, config) {
           critical: edge.critical,
           status: edge.status || undefined,
           detail: edge.detail || undefined,
+          flapping: edge.flapping || false,
+          transitions: edge.transitions || 0,
+          inCycle: edge.inCycle || false,
+          criticalCycle: edge.criticalCycle || false,
           alertCount: alertsEnabled ? (edge.alertCount || 0) : 0,
           alertSeverity: alertsEnabled ? (edge.alertSeverity || undefined) : undefined,
+          ackCount: alertsEnabled ? (edge.acknowledgedCount || 0) : 0,
           grafanaUrl: edge.grafanaUrl || undefined,
         },
       });
@@ -95,6 +95,7 @@ export default {
   'status.alerts': 'Alerts: {details}',
   'status.critical': '{count} critical',
   'status.warning': '{count} warning',
+  'status.acknowledged': '{count} acknowledged',
   'status.partialData': 'Partial data',
   'status.filtered': 'Filtered',
   'status.loading': 'Loading...',
@@ -156,6 +157,11 @@ export default {
   'sidebar.edge.latency': 'Latency',
   'sidebar.edge.status': 'Status',
   'sidebar.edge.detail': 'Detail',
+  'sidebar.edge.flapping': 'Flapping',
+  'sidebar.edge.flappingTransitions': '{count} status changes in the flap window',
+  'sidebar.edge.cycle': 'Dependency cycle',
+  'sidebar.edge.cycleCritical': 'Critical cycle — root cause is ambiguous',
+  'sidebar.edge.cycleNonCritical': 'Yes (through non-critical edges)',
   'sidebar.edge.critical': 'Critical',
   'sidebar.edge.criticalYes': 'Yes',
   'sidebar.edge.criticalNo': 'No',
@@ -172,6 +178,8 @@ export default {
   'alerts.service': 'Service: {name}',
   'alerts.dependency': 'Dependency: {name}',
   'alerts.unavailable': 'Connect AlertManager',
+  'alerts.silenced': '✓ Silenced by {createdBy} until {endsAt}',
+  'alerts.inhibited': '✓ Inhibited by another alert',
 
   // Tooltip
   'tooltip.state': 'State:',
@@ -229,6 +237,11 @@ export default {
   'contextMenu.expandNamespace': 'Expand Namespace',
   'contextMenu.copyNamespaceName': 'Copy Namespace Name',
   'contextMenu.namespaceCopied': 'Namespace name copied',
+  'contextMenu.silenceAlerts': 'Silence Alerts…',
+  'contextMenu.silenceDuration': 'Silence duration (e.g. 30m, 2h, 24h):',
+  'contextMenu.silenceComment': 'Comment (reason for the silence):',
+  'contextMenu.silenceCreated': 'Silence created',
+  'contextMenu.silenceFailed': 'Failed to create silence: {error}',
 
   // Timeline / History mode
   'toolbar.history': 'History mode',
@@ -239,6 +252,7 @@ export default {
   'status.viewing': 'Viewing {time} | {nodes} nodes, {edges} edges',
   'timeline.noData': 'No status changes in this range',
   'timeline.eventsError': 'Failed to load timeline events',
+  'timeline.outageDuration': 'Outage lasted {duration}',
   'timeline.copyUrl': 'Copy URL',
   'timeline.urlCopied': 'URL copied to clipboard',
 
@@ -95,6 +95,7 @@ export default {
   'status.alerts': 'Алерты: {details}',
   'status.critical': '{count} крит.',
   'status.warning': '{count} предупр.',
+  'status.acknowledged': '{count} подтв.',
   'status.partialData': 'Неполные данные',
   'status.filtered': 'Фильтр',
   'status.loading': 'Загрузка...',
@@ -158,6 +159,11 @@ export default {
   'sidebar.edge.criticalYes': 'Да',
   'sidebar.edge.status': 'Статус',
   'sidebar.edge.detail': 'Детали',
+  'sidebar.edge.flapping': 'Нестабильна',
+  'sidebar.edge.flappingTransitions': 'Смен статуса в окне: {count}',
+  'sidebar.edge.cycle': 'Цикл зависимостей',
+  'sidebar.edge.cycleCritical': 'Критический цикл — первопричина неоднозначна',
+  'sidebar.edge.cycleNonCritical': 'Да (через некритичные связи)',
   'sidebar.edge.criticalNo': 'Нет',
   'sidebar.edge.connectedNodes': 'Связанные узлы',
   'sidebar.edge.goToNode': 'Перейти к узлу',
@@ -172,6 +178,8 @@ export default {
   'alerts.service': 'Сервис: {name}',
   'alerts.dependency': 'Зависимость: {name}',
   'alerts.unavailable': 'Подключите AlertManager',
+  'alerts.silenced': '✓ Заглушён {createdBy} до {endsAt}',
+  'alerts.inhibited': '✓ Подавлен другим алертом',
 
   // Tooltip
   'tooltip.state': 'Состояние:',
@@ -229,6 +237,11 @@ export default {
   'contextMenu.expandNamespace': 'Развернуть namespace',
   'contextMenu.copyNamespaceName': 'Копировать имя namespace',
   'contextMenu.namespaceCopied': 'Имя namespace скопировано',
+  'contextMenu.silenceAlerts': 'Заглушить алерты…',
+  'contextMenu.silenceDuration': 'Длительность (например 30m, 2h, 24h):',
+  'contextMenu.silenceComment': 'Комментарий (причина):',
+  'contextMenu.silenceCreated': 'Тишина создана',
+  'contextMenu.silenceFailed': 'Не удалось создать тишину: {error}',
 
   // Timeline / History mode
   'toolbar.history': 'Режим истории',
@@ -239,6 +252,7 @@ export default {
   'status.viewing': 'Просмотр {time} | {nodes} узлов, {edges} связей',
   'timeline.noData': 'Нет изменений статуса за этот период',
   'timeline.eventsError': 'Не удалось загрузить события',
+  'timeline.outageDuration': 'Сбой длился {duration}',
   'timeline.copyUrl': 'Копировать URL',
   'timeline.urlCopied': 'URL скопирован в буфер обмена',
 
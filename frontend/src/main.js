@@ -12,7 +12,7 @@ import { initToolbar } from './toolbar.js';
 import { initTooltip } from './tooltip.js';
 import { initSidebar, updateSidebarData, setGrafanaConfig } from './sidebar.js';
 import { initSearch } from './search.js';
-import { initAlertDrawer, updateAlertDrawer, setAlertManagerAvailable } from './alerts.js';
+import { initAlertDrawer, updateAlertDrawer, setAlertManagerAvailable, isAcknowledged } from './alerts.js';
 import { initShortcuts } from './shortcuts.js';
 import { initI18n, t, setLanguage, getLanguage, updateI18nDom } from './i18n.js';
 import { getNamespaceColor, extractNamespaceFromHost } from './namespace.js';
@@ -96,11 +96,14 @@ function updateStatus(data) {
 
   const alertsEnabled = appConfig && appConfig.alerts && appConfig.alerts.enabled;
   if (alertsEnabled && data.alerts && data.alerts.length > 0) {
-    const critical = data.alerts.filter((a) => a.severity === 'critical').length;
-    const warning = data.alerts.filter((a) => a.severity === 'warning').length;
+    const active = data.alerts.filter((a) => !isAcknowledged(a));
+    const critical = active.filter((a) => a.severity === 'critical').length;
+    const warning = active.filter((a) => a.severity === 'warning').length;
+    const acknowledged = data.alerts.length - active.length;
     const parts = [];
     if (critical > 0) parts.push(t('status.critical', { count: critical }));
     if (warning > 0) parts.push(t('status.warning', { count: warning }));
+    if (acknowledged > 0) parts.push(t('status.acknowledged', { count: acknowledged }));
     text += ' | ' + t('status.alerts', { details: parts.join(', ') || data.alerts.length });
   }
 
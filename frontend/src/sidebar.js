@@ -731,6 +731,8 @@ function renderEdgeDetails(data) {
     { label: t('sidebar.state'), value: formatStateBadge(data.state, data.stale) },
     data.status && data.status !== 'ok' && { label: t('sidebar.edge.status'), value: formatStatusBadge(data.status) },
     data.status && data.status !== 'ok' && data.detail && { label: t('sidebar.edge.detail'), value: `<code>${escapeHtml(data.detail)}</code>` },
+    data.flapping && { label: t('sidebar.edge.flapping'), value: t('sidebar.edge.flappingTransitions', { count: data.transitions }) },
+    data.inCycle && { label: t('sidebar.edge.cycle'), value: data.criticalCycle ? t('sidebar.edge.cycleCritical') : t('sidebar.edge.cycleNonCritical') },
     data.type && { label: t('sidebar.edge.type'), value: escapeHtml(data.type) },
     { label: t('sidebar.edge.latency'), value: escapeHtml(data.stale ? '—' : (data.latency || '—')) },
     { label: t('sidebar.edge.critical'), value: data.critical ? t('sidebar.edge.criticalYes') : t('sidebar.edge.criticalNo') },
@@ -689,6 +689,38 @@ async function loadMarkers() {
   }
 }
 
+/**
+ * Describe a timeline event: the edge, the transition and, for recoveries,
+ * how long the outage lasted.
+ * @param {Object} ev - Event from /api/v1/timeline/events
+ * @returns {string}
+ */
+function formatEventTitle(ev) {
+  let edge = ev.service;
+  const target = ev.dependency || (ev.host ? `${ev.host}:${ev.port}` : '');
+  if (target) edge += ` \u2192 ${target}`;
+  let title = `${edge}: ${ev.fromState} \u2192 ${ev.toState}`;
+  if (ev.detail) title += ` (${ev.detail})`;
+  if (ev.outageDurationSeconds) {
+    title += `\n${t('timeline.outageDuration', { duration: formatOutageDuration(ev.outageDurationSeconds) })}`;
+  }
+  return title;
+}
+
+/**
+ * Format an outage duration in seconds as e.g. "1h 5m" or "45s".
+ * @param {number} seconds
+ * @returns {string}
+ */
+function formatOutageDuration(seconds) {
+  const s = Math.round(seconds);
+  const h = Math.floor(s / 3600);
+  const m = Math.floor((s % 3600) / 60);
+  if (h > 0) return m > 0 ? `${h}h ${m}m` : `${h}h`;
+  if (m > 0) return s % 60 > 0 ? `${m}m ${s % 60}s` : `${m}m`;
+  return `${s}s`;
+}
+
 function renderMarkers(events) {
   if (!markersEl || !rangeStart || !rangeEnd) return;
   const totalMs = rangeEnd.getTime() - rangeStart.getTime();
@@ -701,7 +733,7 @@ function renderMarkers(events) {
     const cls = ev.kind === 'degradation' ? 'marker-degradation'
       : ev.kind === 'recovery' ? 'marker-recovery'
         : 'marker-change';
-    const title = `${escapeHtml(ev.service)}: ${escapeHtml(ev.fromState)} \u2192 ${escapeHtml(ev.toState)}`;
+    const title = escapeHtml(formatEventTitle(ev));
     return `<div class="timeline-marker ${cls}" style="left:${pct}%" title="${title}" data-ts="${ts}"></div>`;
   }).join('');
 
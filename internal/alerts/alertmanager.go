@@ -1,41 +1,110 @@
 package alerts
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 // Alert represents a parsed alert from AlertManager mapped to topology entities.
 type Alert struct {
-	AlertName  string `json:"alertname"`
-	Service    string `json:"service"`    // job label (source service)
-	Dependency string `json:"dependency"` // dependency label (target)
-	Severity   string `json:"severity"`   // "critical", "warning", "info"
-	State      string `json:"state"`      // "firing"
-	Since      string `json:"since"`      // RFC3339 timestamp
-	Summary    string `json:"summary,omitempty"`
+	AlertName  string            `json:"alertname"`
+	Service    string            `json:"service"`    // job label (source service)
+	Dependency string            `json:"dependency"` // dependency label (target)
+	Namespace  string            `json:"namespace,omitempty"`
+	Host       string            `json:"host,omitempty"` // dependency endpoint, when the alert carries it
+	Port       string            `json:"port,omitempty"`
+	Severity   string            `json:"severity"` // "critical", "warning", "info"
+	State      string            `json:"state"`    // "firing", "silenced", "inhibited"
+	Since      string            `json:"since"`    // RFC3339 timestamp
+	Summary    string            `json:"summary,omitempty"`
+	Labels     map[string]string `json:"-"` // all AlertManager labels, for state rules
+	// Set for silenced alerts: the silence that expires last.
+	SilenceID        string `json:"silenceId,omitempty"`
+	SilenceCreatedBy string `json:"silenceCreatedBy,omitempty"`
+	SilenceEndsAt    string `json:"silenceEndsAt,omitempty"` // RFC3339 timestamp
+}
+
+// Alert states reported by AlertManager.
+const (
+	StateFiring    = "firing"
+	StateSilenced  = "silenced"
+	StateInhibited = "inhibited"
+)
+
+// Acknowledged reports whether the alert is silenced or inhibited, i.e.
+// someone already knows about it and it should not drive edge state.
+func (a Alert) Acknowledged() bool {
+	return a.State == StateSilenced || a.State == StateInhibited
 }
 
 // AlertManagerClient fetches active alerts from AlertManager.
+// When some endpoints fail but others respond, FetchAlerts returns the
+// alerts it could fetch together with a *PartialError.
 type AlertManagerClient interface {
 	FetchAlerts(ctx context.Context) ([]Alert, error)
 }
 
 // Config holds AlertManager connection settings.
+// URLs lists several AlertManager endpoints (e.g. an HA pair plus regional
+// instances); when empty, URL is used.
 type Config struct {
 	URL      string
+	URLs     []string
 	Username string
 	Password string
 	Timeout  time.Duration
+	// Transport carries authentication, TLS and proxy settings (nil = http.DefaultTransport).
+	Transport http.RoundTripper
+	// Mapping maps alert labels to topology entities; zero value means DefaultMapping.
+	Mapping Mapping
+}
+
+// EndpointError is a failure to fetch alerts from a single AlertManager endpoint.
+type EndpointError struct {
+	URL string
+	Err error
+}
+
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("alertmanager %s: %v", e.URL, e.Err)
+}
+
+func (e *EndpointError) Unwrap() error { return e.Err }
+
+// PartialError reports AlertManager endpoints that failed while at least
+// one other endpoint responded.
+type PartialError struct {
+	Endpoints []*EndpointError
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Endpoints))
+	for i, ep := range e.Endpoints {
+		msgs[i] = ep.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Endpoints))
+	for i, ep := range e.Endpoints {
+		errs[i] = ep
+	}
+	return errs
 }
 
 type client struct {
-	cfg    Config
-	http   *http.Client
+	cfg  Config
+	http *http.Client
 }
 
 // NewClient creates a new AlertManager client.
@@ -44,14 +113,18 @@ func NewClient(cfg Config) AlertManagerClient {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
+	if cfg.Mapping.ServiceLabels == nil {
+		cfg.Mapping = DefaultMapping()
+	}
 	return &client{
 		cfg:  cfg,
-		http: &http.Client{Timeout: timeout},
+		http: &http.Client{Timeout: timeout, Transport: cfg.Transport},
 	}
 }
 
 // amAlert represents AlertManager API v2 alert format.
 type amAlert struct {
+	Fingerprint string            `json:"fingerprint"`
 	Labels      map[string]string `json:"labels"`
 	Annotations map[string]string `json:"annotations"`
 	StartsAt    string            `json:"startsAt"`
@@ -59,19 +132,134 @@ type amAlert struct {
 }
 
 type amStatus struct {
-	State string `json:"state"` // "active", "suppressed", "unprocessed"
+	State       string   `json:"state"` // "active", "suppressed", "unprocessed"
+	SilencedBy  []string `json:"silencedBy"`
+	InhibitedBy []string `json:"inhibitedBy"`
+}
+
+// amSilence represents AlertManager API v2 silence format.
+type amSilence struct {
+	ID        string `json:"id"`
+	CreatedBy string `json:"createdBy"`
+	Comment   string `json:"comment"`
+	EndsAt    string `json:"endsAt"`
+}
+
+// endpoints returns the configured AlertManager URLs.
+func (c *client) endpoints() []string {
+	if len(c.cfg.URLs) > 0 {
+		return c.cfg.URLs
+	}
+	if c.cfg.URL != "" {
+		return []string{c.cfg.URL}
+	}
+	return nil
 }
 
 func (c *client) FetchAlerts(ctx context.Context) ([]Alert, error) {
-	if c.cfg.URL == "" {
+	urls := c.endpoints()
+	if len(urls) == 0 {
 		return nil, nil
 	}
+	if len(urls) == 1 {
+		amAlerts, silences, err := c.fetchEndpoint(ctx, urls[0])
+		if err != nil {
+			return nil, err
+		}
+		return mapAlerts(amAlerts, silences, c.cfg.Mapping), nil
+	}
+
+	results := make([][]amAlert, len(urls))
+	silenceResults := make([]map[string]amSilence, len(urls))
+	errs := make([]error, len(urls))
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], silenceResults[i], errs[i] = c.fetchEndpoint(ctx, u)
+		}()
+	}
+	wg.Wait()
 
-	url := c.cfg.URL + "/api/v2/alerts?active=true&silenced=false&inhibited=false"
+	var failed []*EndpointError
+	var merged [][]amAlert
+	silences := make(map[string]amSilence)
+	for i, u := range urls {
+		if errs[i] != nil {
+			failed = append(failed, &EndpointError{URL: u, Err: errs[i]})
+			continue
+		}
+		merged = append(merged, results[i])
+		for id, s := range silenceResults[i] {
+			silences[id] = s
+		}
+	}
+	if len(merged) == 0 {
+		// Not a *PartialError: there is nothing to serve.
+		return nil, fmt.Errorf("all alertmanager endpoints failed: %s", &PartialError{Endpoints: failed})
+	}
+
+	mapped := mapAlerts(dedupAlerts(merged...), silences, c.cfg.Mapping)
+	if len(failed) > 0 {
+		return mapped, &PartialError{Endpoints: failed}
+	}
+	return mapped, nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// fetchEndpoint fetches active alerts, including silenced and inhibited
+// ones, from a single AlertManager. When any alert is silenced, the
+// endpoint's silences are fetched too so alerts can name their silence;
+// failing that, silenced alerts carry only the silence ID.
+func (c *client) fetchEndpoint(ctx context.Context, baseURL string) ([]amAlert, map[string]amSilence, error) {
+	var amAlerts []amAlert
+	if err := c.get(ctx, baseURL+"/api/v2/alerts?active=true&silenced=true&inhibited=true", &amAlerts); err != nil {
+		return nil, nil, fmt.Errorf("fetching alerts: %w", err)
+	}
+
+	silenced := false
+	for _, a := range amAlerts {
+		if len(a.Status.SilencedBy) > 0 {
+			silenced = true
+			break
+		}
+	}
+	if !silenced {
+		return amAlerts, nil, nil
+	}
+
+	var amSilences []amSilence
+	if err := c.get(ctx, baseURL+"/api/v2/silences", &amSilences); err != nil {
+		return amAlerts, nil, nil
+	}
+	silences := make(map[string]amSilence, len(amSilences))
+	for _, s := range amSilences {
+		silences[s.ID] = s
+	}
+	return amAlerts, silences, nil
+}
+
+// get performs an authenticated GET request and decodes the JSON response into v.
+func (c *client) get(ctx context.Context, url string, v any) error {
+	return c.do(ctx, http.MethodGet, url, nil, v)
+}
+
+// errNotFound is returned by do for 404 responses.
+var errNotFound = errors.New("alertmanager returned 404")
+
+// do performs an authenticated request with an optional JSON body and
+// decodes a JSON response into v when v is non-nil.
+func (c *client) do(ctx context.Context, method, url string, body []byte, v any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return fmt.Errorf("creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
 	}
 
 	if c.cfg.Username != "" {
@@ -80,47 +268,112 @@ func (c *client) FetchAlerts(ctx context.Context) ([]Alert, error) {
 
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("fetching alerts: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	body, err := io.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return fmt.Errorf("reading response: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("alertmanager returned %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("alertmanager returned %d: %s", resp.StatusCode, string(respBody))
 	}
 
-	var amAlerts []amAlert
-	if err := json.Unmarshal(body, &amAlerts); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+	if v == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return fmt.Errorf("parsing response: %w", err)
 	}
+	return nil
+}
 
-	return mapAlerts(amAlerts), nil
+// dedupAlerts merges alert lists from several AlertManager endpoints.
+// Alerts are identified by fingerprint, or by their label set when the
+// fingerprint is missing; the first occurrence wins.
+func dedupAlerts(lists ...[]amAlert) []amAlert {
+	seen := make(map[string]bool)
+	var result []amAlert
+	for _, list := range lists {
+		for _, a := range list {
+			key := a.Fingerprint
+			if key == "" {
+				key = labelsKey(a.Labels)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// labelsKey returns a canonical string for a label set.
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var sb strings.Builder
+	sb.WriteString("{")
+	for _, k := range names {
+		fmt.Fprintf(&sb, "%s=%q,", k, labels[k])
+	}
+	sb.WriteString("}")
+	return sb.String()
 }
 
 // mapAlerts converts AlertManager alerts to topology-mapped alerts.
-// Only alerts with "job" and "dependency" labels are relevant.
-func mapAlerts(amAlerts []amAlert) []Alert {
+// Only alerts that the mapping can attach to an edge are relevant.
+// Silenced alerts are annotated with their longest-lasting silence.
+func mapAlerts(amAlerts []amAlert, silences map[string]amSilence, mapping Mapping) []Alert {
 	var result []Alert
 	for _, a := range amAlerts {
-		job := a.Labels["job"]
-		dep := a.Labels["dependency"]
-		if job == "" || dep == "" {
+		alert, ok := mapping.Map(a.Labels)
+		if !ok {
 			continue
 		}
-
-		result = append(result, Alert{
-			AlertName:  a.Labels["alertname"],
-			Service:    job,
-			Dependency: dep,
-			Severity:   a.Labels["severity"],
-			State:      "firing",
-			Since:      a.StartsAt,
-			Summary:    a.Annotations["summary"],
-		})
+		switch {
+		case len(a.Status.SilencedBy) > 0:
+			alert.State = StateSilenced
+			s := latestSilence(a.Status.SilencedBy, silences)
+			alert.SilenceID = s.ID
+			alert.SilenceCreatedBy = s.CreatedBy
+			alert.SilenceEndsAt = s.EndsAt
+		case len(a.Status.InhibitedBy) > 0:
+			alert.State = StateInhibited
+		default:
+			alert.State = StateFiring
+		}
+		alert.Since = a.StartsAt
+		alert.Summary = a.Annotations["summary"]
+		result = append(result, alert)
 	}
 	return result
 }
+
+// latestSilence returns the silence among ids that ends last. Silences
+// missing from the lookup are reported by ID only.
+func latestSilence(ids []string, silences map[string]amSilence) amSilence {
+	var best amSilence
+	var bestEnd time.Time
+	for _, id := range ids {
+		s, ok := silences[id]
+		if !ok {
+			s = amSilence{ID: id}
+		}
+		end, _ := time.Parse(time.RFC3339, s.EndsAt)
+		if best.ID == "" || end.After(bestEnd) {
+			best, bestEnd = s, end
+		}
+	}
+	return best
+}
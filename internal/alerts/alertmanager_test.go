@@ -2,6 +2,7 @@ package alerts
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -133,3 +134,119 @@ func TestFetchAlertsHTTPError(t *testing.T) {
 		t.Error("expected error for 500 response")
 	}
 }
+
+func TestFetchAlertsMultipleEndpoints(t *testing.T) {
+	alertJSON := func(fingerprint, job string) string {
+		return `{"fingerprint":"` + fingerprint + `","labels":{"alertname":"DependencyDown","job":"` + job +
+			`","dependency":"postgres","severity":"critical"},"annotations":{},"startsAt":"2026-02-08T10:00:00Z","status":{"state":"active"}}`
+	}
+	newAM := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+
+	// HA pair reports the same alert; the regional instance adds another.
+	am1 := newAM(`[` + alertJSON("aaa", "svc-a") + `]`)
+	defer am1.Close()
+	am2 := newAM(`[` + alertJSON("aaa", "svc-a") + `]`)
+	defer am2.Close()
+	am3 := newAM(`[` + alertJSON("bbb", "svc-b") + `]`)
+	defer am3.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	c := NewClient(Config{URLs: []string{am1.URL, am2.URL, am3.URL}})
+	got, err := c.FetchAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAlerts() error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d alerts, want 2 after dedup", len(got))
+	}
+	if got[0].Service != "svc-a" || got[1].Service != "svc-b" {
+		t.Errorf("services = %q, %q; want svc-a, svc-b", got[0].Service, got[1].Service)
+	}
+
+	c = NewClient(Config{URLs: []string{am1.URL, down.URL}})
+	got, err = c.FetchAlerts(context.Background())
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("FetchAlerts() error = %v, want *PartialError", err)
+	}
+	if len(partial.Endpoints) != 1 || partial.Endpoints[0].URL != down.URL {
+		t.Errorf("failed endpoints = %v, want %s", partial.Endpoints, down.URL)
+	}
+	if len(got) != 1 {
+		t.Errorf("got %d alerts, want 1 from the healthy endpoint", len(got))
+	}
+
+	c = NewClient(Config{URLs: []string{down.URL, down.URL}})
+	_, err = c.FetchAlerts(context.Background())
+	if err == nil || errors.As(err, &partial) {
+		t.Errorf("FetchAlerts() error = %v, want a non-partial error when all endpoints fail", err)
+	}
+}
+
+func TestFetchAlertsSilencedAndInhibited(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v2/alerts":
+			if r.URL.Query().Get("silenced") != "true" || r.URL.Query().Get("inhibited") != "true" {
+				t.Errorf("query = %q, want silenced and inhibited alerts included", r.URL.RawQuery)
+			}
+			_, _ = w.Write([]byte(`[
+				{"labels":{"alertname":"DependencyDown","job":"svc-a","dependency":"postgres","severity":"critical"},
+				 "startsAt":"2026-02-08T10:00:00Z","status":{"state":"suppressed","silencedBy":["s-1","s-2"]}},
+				{"labels":{"alertname":"DependencyDegraded","job":"svc-a","dependency":"redis","severity":"warning"},
+				 "startsAt":"2026-02-08T10:00:00Z","status":{"state":"suppressed","inhibitedBy":["fp-1"]}},
+				{"labels":{"alertname":"DependencyDown","job":"svc-b","dependency":"kafka","severity":"critical"},
+				 "startsAt":"2026-02-08T10:00:00Z","status":{"state":"active"}}
+			]`))
+		case "/api/v2/silences":
+			_, _ = w.Write([]byte(`[
+				{"id":"s-1","createdBy":"alice","comment":"db maintenance","endsAt":"2026-02-08T12:00:00Z"},
+				{"id":"s-2","createdBy":"bob","comment":"extended","endsAt":"2026-02-08T14:00:00Z"}
+			]`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	got, err := NewClient(Config{URL: srv.URL}).FetchAlerts(context.Background())
+	if err != nil {
+		t.Fatalf("FetchAlerts() error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d alerts, want 3", len(got))
+	}
+
+	if got[0].State != StateSilenced || !got[0].Acknowledged() {
+		t.Errorf("alerts[0].State = %q, want %q", got[0].State, StateSilenced)
+	}
+	// The silence that ends last is reported.
+	if got[0].SilenceID != "s-2" || got[0].SilenceCreatedBy != "bob" || got[0].SilenceEndsAt != "2026-02-08T14:00:00Z" {
+		t.Errorf("alerts[0] silence = %q/%q/%q, want s-2/bob/2026-02-08T14:00:00Z",
+			got[0].SilenceID, got[0].SilenceCreatedBy, got[0].SilenceEndsAt)
+	}
+	if got[1].State != StateInhibited || got[1].SilenceID != "" {
+		t.Errorf("alerts[1] = %q (silence %q), want %q without silence", got[1].State, got[1].SilenceID, StateInhibited)
+	}
+	if got[2].State != StateFiring || got[2].Acknowledged() {
+		t.Errorf("alerts[2].State = %q, want %q", got[2].State, StateFiring)
+	}
+}
+
+func TestDedupAlertsByLabels(t *testing.T) {
+	a := amAlert{Labels: map[string]string{"alertname": "X", "job": "svc", "dependency": "db"}}
+	b := amAlert{Labels: map[string]string{"dependency": "db", "job": "svc", "alertname": "X"}}
+	c := amAlert{Labels: map[string]string{"alertname": "Y", "job": "svc", "dependency": "db"}}
+
+	got := dedupAlerts([]amAlert{a}, []amAlert{b, c})
+	if len(got) != 2 {
+		t.Errorf("got %d alerts, want 2 (identical label sets merged)", len(got))
+	}
+}
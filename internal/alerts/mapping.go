@@ -0,0 +1,108 @@
+package alerts
+
+import "net"
+
+// Edge states an alert can force on the edge it is mapped to.
+const (
+	EdgeStateDown     = "down"
+	EdgeStateDegraded = "degraded"
+)
+
+// Mapping describes how alert labels map to topology entities and which
+// alerts override edge state. Label lists are tried in order; the first
+// non-empty label wins.
+type Mapping struct {
+	ServiceLabels    []string
+	DependencyLabels []string
+	NamespaceLabels  []string
+	HostLabels       []string
+	PortLabels       []string
+	EndpointLabels   []string // labels holding "host:port"
+	SeverityLabel    string
+	StateRules       []StateRule
+}
+
+// StateRule maps matching alerts to an edge state. A rule matches when the
+// alert name equals AlertName (if set) and every matcher label has exactly
+// the given value.
+type StateRule struct {
+	AlertName string
+	Matchers  map[string]string
+	State     string // EdgeStateDown or EdgeStateDegraded
+}
+
+// DefaultMapping returns the built-in mapping: service from "job",
+// dependency from "dependency", and DependencyDown/DependencyDegraded
+// alerts overriding edge state.
+func DefaultMapping() Mapping {
+	return Mapping{
+		ServiceLabels:    []string{"job"},
+		DependencyLabels: []string{"dependency"},
+		SeverityLabel:    "severity",
+		StateRules: []StateRule{
+			{AlertName: "DependencyDown", State: EdgeStateDown},
+			{AlertName: "DependencyDegraded", State: EdgeStateDegraded},
+		},
+	}
+}
+
+// Map converts an alert's labels to a topology-mapped Alert. It reports
+// false when the alert cannot be attached to an edge: the service is
+// missing, or neither a dependency nor a host:port is present.
+// AlertName, Severity and Labels are filled; timing and summary are left
+// to the caller.
+func (m Mapping) Map(labels map[string]string) (Alert, bool) {
+	a := Alert{
+		AlertName:  labels["alertname"],
+		Service:    firstLabel(labels, m.ServiceLabels),
+		Dependency: firstLabel(labels, m.DependencyLabels),
+		Namespace:  firstLabel(labels, m.NamespaceLabels),
+		Host:       firstLabel(labels, m.HostLabels),
+		Port:       firstLabel(labels, m.PortLabels),
+		Severity:   labels[m.SeverityLabel],
+		Labels:     labels,
+	}
+	if a.Host == "" || a.Port == "" {
+		if ep := firstLabel(labels, m.EndpointLabels); ep != "" {
+			if host, port, err := net.SplitHostPort(ep); err == nil {
+				a.Host, a.Port = host, port
+			}
+		}
+	}
+	if a.Service == "" || (a.Dependency == "" && (a.Host == "" || a.Port == "")) {
+		return Alert{}, false
+	}
+	return a, true
+}
+
+// EdgeState returns the state of the first matching state rule, or "" when
+// the alert does not override edge state.
+func (m Mapping) EdgeState(labels map[string]string) string {
+	for _, r := range m.StateRules {
+		if r.matches(labels) {
+			return r.State
+		}
+	}
+	return ""
+}
+
+func (r StateRule) matches(labels map[string]string) bool {
+	if r.AlertName != "" && labels["alertname"] != r.AlertName {
+		return false
+	}
+	for k, v := range r.Matchers {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func firstLabel(labels map[string]string, names []string) string {
+	for _, name := range names {
+		if v := labels[name]; v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package alerts
+
+import "testing"
+
+func TestMappingCustomLabels(t *testing.T) {
+	m := Mapping{
+		ServiceLabels:    []string{"app", "job"},
+		DependencyLabels: []string{"target"},
+		NamespaceLabels:  []string{"kubernetes_namespace"},
+		EndpointLabels:   []string{"endpoint"},
+		SeverityLabel:    "priority",
+		StateRules: []StateRule{
+			{AlertName: "UpstreamUnavailable", State: EdgeStateDown},
+			{Matchers: map[string]string{"impact": "partial"}, State: EdgeStateDegraded},
+		},
+	}
+
+	a, ok := m.Map(map[string]string{
+		"alertname":            "UpstreamUnavailable",
+		"job":                  "svc-go",
+		"target":               "postgres",
+		"kubernetes_namespace": "prod",
+		"priority":             "p1",
+	})
+	if !ok {
+		t.Fatal("Map() ok = false, want true")
+	}
+	if a.Service != "svc-go" || a.Dependency != "postgres" || a.Namespace != "prod" || a.Severity != "p1" {
+		t.Errorf("Map() = %+v, want service svc-go, dependency postgres, namespace prod, severity p1", a)
+	}
+	if got := m.EdgeState(a.Labels); got != EdgeStateDown {
+		t.Errorf("EdgeState() = %q, want %q", got, EdgeStateDown)
+	}
+
+	// No dependency label: attached by host:port from the endpoint label.
+	a, ok = m.Map(map[string]string{
+		"alertname": "SlowQueries",
+		"app":       "svc-python",
+		"endpoint":  "redis.svc:6379",
+		"impact":    "partial",
+	})
+	if !ok {
+		t.Fatal("Map() by endpoint ok = false, want true")
+	}
+	if a.Service != "svc-python" || a.Host != "redis.svc" || a.Port != "6379" {
+		t.Errorf("Map() = %+v, want service svc-python, host redis.svc, port 6379", a)
+	}
+	if got := m.EdgeState(a.Labels); got != EdgeStateDegraded {
+		t.Errorf("EdgeState() = %q, want %q", got, EdgeStateDegraded)
+	}
+
+	// Neither dependency nor endpoint: not attachable.
+	if _, ok := m.Map(map[string]string{"alertname": "X", "app": "svc"}); ok {
+		t.Error("Map() without dependency or endpoint ok = true, want false")
+	}
+}
+
+func TestDefaultMappingStateRules(t *testing.T) {
+	m := DefaultMapping()
+	tests := map[string]string{
+		"DependencyDown":        EdgeStateDown,
+		"DependencyDegraded":    EdgeStateDegraded,
+		"DependencyHighLatency": "",
+	}
+	for name, want := range tests {
+		if got := m.EdgeState(map[string]string{"alertname": name}); got != want {
+			t.Errorf("EdgeState(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SilenceCommentPrefix marks silences created by dephealth-ui. Only silences
+// whose comment starts with it are listed and may be expired.
+const SilenceCommentPrefix = "[dephealth-ui] "
+
+// ErrSilenceNotFound is returned when a silence does not exist on any
+// endpoint or was not created by dephealth-ui.
+var ErrSilenceNotFound = errors.New("silence not found")
+
+// SilenceClient manages AlertManager silences created by dephealth-ui.
+// With several endpoints, silences are created on every endpoint, since
+// separate instances (e.g. a regional one next to an HA pair) do not
+// replicate them. The copies, which get different IDs, are listed once and
+// expired together.
+type SilenceClient interface {
+	CreateSilence(ctx context.Context, req SilenceRequest) (Silence, error)
+	ListSilences(ctx context.Context) ([]Silence, error)
+	ExpireSilence(ctx context.Context, id string) error
+}
+
+// SilenceRequest selects the alerts to silence by topology entity.
+// Matchers are built from the first label of each mapping list; at least
+// Service is required.
+type SilenceRequest struct {
+	Service    string
+	Dependency string
+	Namespace  string
+	Duration   time.Duration
+	Comment    string
+	CreatedBy  string
+}
+
+// Matcher is an AlertManager v2 label matcher.
+type Matcher struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	IsRegex bool   `json:"isRegex"`
+	IsEqual bool   `json:"isEqual"`
+}
+
+// Silence is an AlertManager silence created by dephealth-ui.
+// Comment is returned without SilenceCommentPrefix.
+type Silence struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  string    `json:"startsAt"`
+	EndsAt    string    `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	State     string    `json:"state"` // "active", "pending", "expired"
+}
+
+// amSilenceFull is the AlertManager API v2 gettable silence format.
+type amSilenceFull struct {
+	ID        string    `json:"id"`
+	Matchers  []Matcher `json:"matchers"`
+	StartsAt  string    `json:"startsAt"`
+	EndsAt    string    `json:"endsAt"`
+	CreatedBy string    `json:"createdBy"`
+	Comment   string    `json:"comment"`
+	Status    struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+// silenceKey identifies the copies of a silence that CreateSilence posted to
+// each endpoint: their IDs differ and AlertManager may move the start to
+// when it received them, but matchers, end, creator and comment are equal.
+func silenceKey(s amSilenceFull) string {
+	key, _ := json.Marshal([]any{s.Matchers, s.EndsAt, s.CreatedBy, s.Comment})
+	return string(key)
+}
+
+// SilenceMatchers returns equality matchers selecting alerts of the given
+// service, dependency and namespace. Empty values and entities without a
+// configured label are skipped.
+func (m Mapping) SilenceMatchers(service, dependency, namespace string) []Matcher {
+	var matchers []Matcher
+	add := func(labels []string, value string) {
+		if value == "" || len(labels) == 0 {
+			return
+		}
+		matchers = append(matchers, Matcher{Name: labels[0], Value: value, IsEqual: true})
+	}
+	add(m.ServiceLabels, service)
+	add(m.DependencyLabels, dependency)
+	add(m.NamespaceLabels, namespace)
+	return matchers
+}
+
+func (c *client) CreateSilence(ctx context.Context, req SilenceRequest) (Silence, error) {
+	if req.Service == "" {
+		return Silence{}, fmt.Errorf("service is required")
+	}
+	if req.Duration <= 0 {
+		return Silence{}, fmt.Errorf("duration must be positive")
+	}
+	urls := c.endpoints()
+	if len(urls) == 0 {
+		return Silence{}, fmt.Errorf("alertmanager is not configured")
+	}
+
+	now := time.Now().UTC()
+	silence := Silence{
+		Matchers:  c.cfg.Mapping.SilenceMatchers(req.Service, req.Dependency, req.Namespace),
+		StartsAt:  now.Format(time.RFC3339),
+		EndsAt:    now.Add(req.Duration).Format(time.RFC3339),
+		CreatedBy: req.CreatedBy,
+		Comment:   req.Comment,
+	}
+	body, err := json.Marshal(map[string]any{
+		"matchers":  silence.Matchers,
+		"startsAt":  silence.StartsAt,
+		"endsAt":    silence.EndsAt,
+		"createdBy": silence.CreatedBy,
+		"comment":   SilenceCommentPrefix + silence.Comment,
+	})
+	if err != nil {
+		return Silence{}, fmt.Errorf("encoding silence: %w", err)
+	}
+
+	// When some endpoints fail, the silence is returned with a *PartialError
+	// naming them, like FetchAlerts.
+	var failed []*EndpointError
+	for _, u := range urls {
+		var created struct {
+			SilenceID string `json:"silenceID"`
+		}
+		if err := c.do(ctx, http.MethodPost, u+"/api/v2/silences", body, &created); err != nil {
+			failed = append(failed, &EndpointError{URL: u, Err: err})
+			continue
+		}
+		if silence.ID == "" {
+			silence.ID = created.SilenceID
+		}
+	}
+	if len(failed) == len(urls) {
+		return Silence{}, fmt.Errorf("creating silence: %s", &PartialError{Endpoints: failed})
+	}
+	silence.State = "active"
+	if len(failed) > 0 {
+		return silence, &PartialError{Endpoints: failed}
+	}
+	return silence, nil
+}
+
+func (c *client) ListSilences(ctx context.Context) ([]Silence, error) {
+	urls := c.endpoints()
+	seen := make(map[string]bool)
+	result := []Silence{}
+	var errs []error
+	for _, u := range urls {
+		var amSilences []amSilenceFull
+		if err := c.get(ctx, u+"/api/v2/silences", &amSilences); err != nil {
+			errs = append(errs, &EndpointError{URL: u, Err: err})
+			continue
+		}
+		for _, s := range amSilences {
+			key := silenceKey(s)
+			if seen[key] || !strings.HasPrefix(s.Comment, SilenceCommentPrefix) {
+				continue
+			}
+			seen[key] = true
+			result = append(result, Silence{
+				ID:        s.ID,
+				Matchers:  s.Matchers,
+				StartsAt:  s.StartsAt,
+				EndsAt:    s.EndsAt,
+				CreatedBy: s.CreatedBy,
+				Comment:   strings.TrimPrefix(s.Comment, SilenceCommentPrefix),
+				State:     s.Status.State,
+			})
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(urls) {
+		return nil, fmt.Errorf("listing silences: %w", errors.Join(errs...))
+	}
+	return result, nil
+}
+
+// ExpireSilence expires the silence with the given ID and its copies on the
+// other endpoints. It fails if any endpoint could not be read or a copy
+// could not be expired; expired copies are skipped, so retrying is safe.
+func (c *client) ExpireSilence(ctx context.Context, id string) error {
+	urls := c.endpoints()
+	lists := make([][]amSilenceFull, len(urls))
+	var errs []error
+	key := ""
+	for i, u := range urls {
+		if err := c.get(ctx, u+"/api/v2/silences", &lists[i]); err != nil {
+			errs = append(errs, &EndpointError{URL: u, Err: err})
+			continue
+		}
+		for _, s := range lists[i] {
+			if s.ID != id {
+				continue
+			}
+			if !strings.HasPrefix(s.Comment, SilenceCommentPrefix) {
+				return ErrSilenceNotFound
+			}
+			key = silenceKey(s)
+		}
+	}
+	if key == "" {
+		if len(errs) > 0 {
+			return fmt.Errorf("expiring silence: %w", errors.Join(errs...))
+		}
+		return ErrSilenceNotFound
+	}
+
+	// HA peers list the same replicated silence; expire each ID once.
+	expired := make(map[string]bool)
+	for i, u := range urls {
+		for _, s := range lists[i] {
+			if expired[s.ID] || s.Status.State == "expired" || silenceKey(s) != key {
+				continue
+			}
+			// A copy that is already gone needs no expiring.
+			err := c.do(ctx, http.MethodDelete, u+"/api/v2/silence/"+url.PathEscape(s.ID), nil, nil)
+			if err != nil && !errors.Is(err, errNotFound) {
+				errs = append(errs, &EndpointError{URL: u, Err: err})
+				continue
+			}
+			expired[s.ID] = true
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("expiring silence: %w", errors.Join(errs...))
+	}
+	return nil
+}
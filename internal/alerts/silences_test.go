@@ -0,0 +1,221 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCreateSilence(t *testing.T) {
+	var posted struct {
+		Matchers  []Matcher `json:"matchers"`
+		CreatedBy string    `json:"createdBy"`
+		Comment   string    `json:"comment"`
+		StartsAt  string    `json:"startsAt"`
+		EndsAt    string    `json:"endsAt"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v2/silences" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"silenceID":"s-1"}`))
+	}))
+	defer srv.Close()
+
+	mapping := DefaultMapping()
+	mapping.NamespaceLabels = []string{"namespace"}
+	c := NewClient(Config{URL: srv.URL, Mapping: mapping}).(SilenceClient)
+	got, err := c.CreateSilence(context.Background(), SilenceRequest{
+		Service:    "svc-go",
+		Dependency: "postgres",
+		Namespace:  "prod",
+		Duration:   2 * time.Hour,
+		Comment:    "db maintenance",
+		CreatedBy:  "alice",
+	})
+	if err != nil {
+		t.Fatalf("CreateSilence() error: %v", err)
+	}
+	if got.ID != "s-1" || got.Comment != "db maintenance" {
+		t.Errorf("CreateSilence() = %+v, want ID s-1 and unprefixed comment", got)
+	}
+
+	want := []Matcher{
+		{Name: "job", Value: "svc-go", IsEqual: true},
+		{Name: "dependency", Value: "postgres", IsEqual: true},
+		{Name: "namespace", Value: "prod", IsEqual: true},
+	}
+	if len(posted.Matchers) != len(want) {
+		t.Fatalf("matchers = %+v, want %+v", posted.Matchers, want)
+	}
+	for i := range want {
+		if posted.Matchers[i] != want[i] {
+			t.Errorf("matchers[%d] = %+v, want %+v", i, posted.Matchers[i], want[i])
+		}
+	}
+	if posted.CreatedBy != "alice" || posted.Comment != SilenceCommentPrefix+"db maintenance" {
+		t.Errorf("createdBy/comment = %q/%q", posted.CreatedBy, posted.Comment)
+	}
+	start, _ := time.Parse(time.RFC3339, posted.StartsAt)
+	end, _ := time.Parse(time.RFC3339, posted.EndsAt)
+	if end.Sub(start) != 2*time.Hour {
+		t.Errorf("silence length = %v, want 2h", end.Sub(start))
+	}
+}
+
+func TestListAndExpireSilences(t *testing.T) {
+	var deleted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silences":
+			_, _ = w.Write([]byte(`[
+				{"id":"s-1","createdBy":"alice","comment":"[dephealth-ui] db maintenance","status":{"state":"active"}},
+				{"id":"s-2","createdBy":"bob","comment":"manual silence","status":{"state":"active"}}
+			]`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silence/s-1":
+			_, _ = w.Write([]byte(`{"id":"s-1","comment":"[dephealth-ui] db maintenance"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silence/s-2":
+			_, _ = w.Write([]byte(`{"id":"s-2","comment":"manual silence"}`))
+		case r.Method == http.MethodDelete:
+			deleted = r.URL.Path
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(Config{URL: srv.URL}).(SilenceClient)
+	list, err := c.ListSilences(context.Background())
+	if err != nil {
+		t.Fatalf("ListSilences() error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "s-1" || list[0].Comment != "db maintenance" || list[0].State != "active" {
+		t.Errorf("ListSilences() = %+v, want only s-1", list)
+	}
+
+	if err := c.ExpireSilence(context.Background(), "s-1"); err != nil {
+		t.Fatalf("ExpireSilence(s-1) error: %v", err)
+	}
+	if deleted != "/api/v2/silence/s-1" {
+		t.Errorf("deleted %q, want /api/v2/silence/s-1", deleted)
+	}
+
+	// Silences not created by dephealth-ui and unknown IDs are not found.
+	for _, id := range []string{"s-2", "missing"} {
+		if err := c.ExpireSilence(context.Background(), id); !errors.Is(err, ErrSilenceNotFound) {
+			t.Errorf("ExpireSilence(%s) error = %v, want ErrSilenceNotFound", id, err)
+		}
+	}
+}
+
+// fakeSilenceServer is an AlertManager that keeps silences in memory and
+// gives them IDs starting with prefix.
+type fakeSilenceServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	silences []amSilenceFull
+}
+
+func newFakeSilenceServer(prefix string) *fakeSilenceServer {
+	f := &fakeSilenceServer{}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v2/silences":
+			var s amSilenceFull
+			_ = json.NewDecoder(r.Body).Decode(&s)
+			s.ID = fmt.Sprintf("%s-%d", prefix, len(f.silences)+1)
+			s.Status.State = "active"
+			f.silences = append(f.silences, s)
+			_ = json.NewEncoder(w).Encode(map[string]string{"silenceID": s.ID})
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v2/silences":
+			_ = json.NewEncoder(w).Encode(f.silences)
+		case r.Method == http.MethodDelete:
+			for i := range f.silences {
+				if r.URL.Path == "/api/v2/silence/"+f.silences[i].ID {
+					f.silences[i].Status.State = "expired"
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return f
+}
+
+func (f *fakeSilenceServer) states() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var states []string
+	for _, s := range f.silences {
+		states = append(states, s.Status.State)
+	}
+	return states
+}
+
+func TestSilencesOnSeveralEndpoints(t *testing.T) {
+	// An HA member and a regional instance that does not replicate silences.
+	ha := newFakeSilenceServer("ha")
+	defer ha.Close()
+	region := newFakeSilenceServer("region")
+	defer region.Close()
+
+	c := NewClient(Config{URLs: []string{ha.URL, region.URL}}).(SilenceClient)
+	ctx := context.Background()
+	created, err := c.CreateSilence(ctx, SilenceRequest{Service: "svc-go", Duration: time.Hour, Comment: "deploy", CreatedBy: "alice"})
+	if err != nil {
+		t.Fatalf("CreateSilence() error: %v", err)
+	}
+	if created.ID != "ha-1" {
+		t.Errorf("ID = %q, want ha-1 from the first endpoint", created.ID)
+	}
+	for name, f := range map[string]*fakeSilenceServer{"ha": ha, "region": region} {
+		if got := f.states(); len(got) != 1 || got[0] != "active" {
+			t.Errorf("%s silences = %v, want one active", name, got)
+		}
+	}
+
+	list, err := c.ListSilences(ctx)
+	if err != nil {
+		t.Fatalf("ListSilences() error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "ha-1" {
+		t.Errorf("ListSilences() = %+v, want the copies listed once", list)
+	}
+
+	if err := c.ExpireSilence(ctx, "ha-1"); err != nil {
+		t.Fatalf("ExpireSilence() error: %v", err)
+	}
+	for name, f := range map[string]*fakeSilenceServer{"ha": ha, "region": region} {
+		if got := f.states(); len(got) != 1 || got[0] != "expired" {
+			t.Errorf("%s silences = %v, want the copy expired", name, got)
+		}
+	}
+
+	// A failing endpoint is reported while the silence is still created.
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	c = NewClient(Config{URLs: []string{down.URL, region.URL}}).(SilenceClient)
+	created, err = c.CreateSilence(ctx, SilenceRequest{Service: "svc-go", Duration: time.Hour, Comment: "deploy", CreatedBy: "alice"})
+	var partial *PartialError
+	if !errors.As(err, &partial) || len(partial.Endpoints) != 1 || partial.Endpoints[0].URL != down.URL {
+		t.Errorf("CreateSilence() error = %v, want a PartialError naming the failed endpoint", err)
+	}
+	if created.ID != "region-2" {
+		t.Errorf("ID = %q, want region-2", created.ID)
+	}
+}
@@ -17,6 +17,20 @@ type Authenticator interface {
 	Routes() http.Handler
 }
 
+type usernameKey struct{}
+
+// Username returns the name of the user authenticated for the request
+// context, or "" when authentication is disabled.
+func Username(ctx context.Context) string {
+	name, _ := ctx.Value(usernameKey{}).(string)
+	return name
+}
+
+// withUsername returns r with the authenticated user name attached to its context.
+func withUsername(r *http.Request, name string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), usernameKey{}, name))
+}
+
 // NewFromConfig creates an Authenticator based on the configuration.
 // For OIDC, use NewFromConfigWithContext which performs provider discovery.
 func NewFromConfig(cfg config.AuthConfig) (Authenticator, error) {
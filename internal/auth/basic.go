@@ -37,7 +37,7 @@ func (a *basicAuth) Middleware() func(http.Handler) http.Handler {
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, withUsername(r, username))
 		})
 	}
 }
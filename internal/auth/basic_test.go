@@ -41,6 +41,23 @@ func TestBasicValidCredentials(t *testing.T) {
 	}
 }
 
+func TestBasicSetsUsername(t *testing.T) {
+	hash := hashPassword(t, "secret")
+	auth := NewBasic([]User{{Username: "admin", PasswordHash: hash}})
+
+	var got string
+	handler := auth.Middleware()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = Username(r.Context())
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("admin", "secret")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "admin" {
+		t.Errorf("Username() = %q, want %q", got, "admin")
+	}
+}
+
 func TestBasicWrongPassword(t *testing.T) {
 	hash := hashPassword(t, "secret")
 	auth := NewBasic([]User{{Username: "admin", PasswordHash: hash}})
@@ -14,6 +14,18 @@ type UserInfo struct {
 	Email   string `json:"email"`
 }
 
+// displayName returns the e-mail, name or subject of the user, whichever is set first.
+func (u UserInfo) displayName() string {
+	switch {
+	case u.Email != "":
+		return u.Email
+	case u.Name != "":
+		return u.Name
+	default:
+		return u.Subject
+	}
+}
+
 // Session represents an authenticated user session.
 type Session struct {
 	ID        string
@@ -19,7 +19,8 @@ type hashableResponse struct {
 }
 
 // Cache provides an in-memory TTL cache for TopologyResponse.
-// It uses lazy expiration (no background goroutine).
+// It uses lazy expiration; refreshing is driven by the server's background
+// refresher.
 type Cache struct {
 	ttl   time.Duration
 	mu    sync.RWMutex
@@ -61,6 +62,19 @@ func (c *Cache) GetWithETag() (*topology.TopologyResponse, string, bool) {
 	return c.data, c.etag, true
 }
 
+// GetLastKnown returns the most recently stored response and its ETag
+// regardless of TTL. It is used to serve last-known-good data when a
+// rebuild fails.
+func (c *Cache) GetLastKnown() (*topology.TopologyResponse, string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.data == nil {
+		return nil, "", false
+	}
+	return c.data, c.etag, true
+}
+
 // Set stores a response in the cache with the current timestamp and computes its ETag.
 func (c *Cache) Set(resp *topology.TopologyResponse) {
 	etag := computeETag(resp)
@@ -170,3 +170,33 @@ func TestConcurrentAccess(t *testing.T) {
 		t.Fatal("expected non-nil response")
 	}
 }
+
+func TestGetLastKnownIgnoresTTL(t *testing.T) {
+	c := New(1 * time.Millisecond)
+	c.Set(&topology.TopologyResponse{
+		Nodes: []topology.Node{{ID: "svc-go"}},
+	})
+
+	time.Sleep(5 * time.Millisecond)
+
+	if _, ok := c.Get(); ok {
+		t.Fatal("expected ok=false for expired entry")
+	}
+	resp, etag, ok := c.GetLastKnown()
+	if !ok {
+		t.Fatal("expected ok=true from GetLastKnown after expiry")
+	}
+	if etag == "" {
+		t.Error("expected non-empty ETag")
+	}
+	if len(resp.Nodes) != 1 || resp.Nodes[0].ID != "svc-go" {
+		t.Errorf("got %+v, want node with ID svc-go", resp)
+	}
+}
+
+func TestGetLastKnownEmpty(t *testing.T) {
+	c := New(10 * time.Second)
+	if _, _, ok := c.GetLastKnown(); ok {
+		t.Error("expected ok=false for empty cache")
+	}
+}
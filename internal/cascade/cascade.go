@@ -8,6 +8,11 @@ import "github.com/BigKAA/dephealth-ui/internal/topology"
 type Options struct {
 	MaxDepth  int    // 0 = unlimited (default)
 	Namespace string // filter results by namespace (optional)
+	Mode      string // ModeStrict (default) or ModeWeighted
+	// NonCriticalWeight is the propagation weight of non-critical edges in
+	// weighted mode; nil means DefaultNonCriticalWeight. 0 stops propagation
+	// over non-critical edges.
+	NonCriticalWeight *float64
 }
 
 // RootCause represents a terminal failure point in the dependency chain.
@@ -17,6 +22,8 @@ type RootCause struct {
 	Type      string `json:"type"`
 	Namespace string `json:"namespace"`
 	State     string `json:"state"`
+	// Probability that this node is the origin of the failure (weighted mode).
+	Probability float64 `json:"probability,omitempty"`
 }
 
 // AffectedService represents a service impacted by a cascade failure.
@@ -25,6 +32,8 @@ type AffectedService struct {
 	Namespace  string   `json:"namespace"`
 	DependsOn  string   `json:"dependsOn"`
 	RootCauses []string `json:"rootCauses"`
+	// Impact is the failure score reaching the service, 0..1 (weighted mode).
+	Impact float64 `json:"impact,omitempty"`
 }
 
 // Failure represents a single failed dependency relationship.
@@ -275,6 +284,14 @@ func buildCascadeChains(affectedNodeID string, rootCauseSet map[string]bool, cas
 
 // Analyze performs cascade failure analysis on the full topology.
 func Analyze(nodes []topology.Node, edges []topology.Edge, opts Options) *AnalysisResult {
+	if opts.Mode == ModeWeighted {
+		result := analyzeWeighted(nodes, edges, opts)
+		if opts.Namespace != "" {
+			return filterByNamespace(result, opts.Namespace)
+		}
+		return result
+	}
+
 	nodeMap := make(map[string]topology.Node, len(nodes))
 	for _, n := range nodes {
 		nodeMap[n.ID] = n
@@ -0,0 +1,165 @@
+package cascade
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// Simulation lists the failures forced onto a topology by Simulate.
+type Simulation struct {
+	Nodes []string           // node IDs forced down
+	Edges []topology.EdgeRef // edges forced down
+}
+
+// EntryPoint is an entry node (Node.IsEntry) that loses functionality in a simulation.
+type EntryPoint struct {
+	ID        string `json:"id"`
+	Label     string `json:"label"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster,omitempty"`
+	State     string `json:"state"` // current state, before the simulation
+	// Causes are the IDs of the simulated failures that reach the entry point;
+	// a failed edge is represented by its source node.
+	Causes []string `json:"causes"`
+}
+
+// SimulationResult is the cascade analysis of a topology with forced failures.
+type SimulationResult struct {
+	AnalysisResult
+	FailedNodes []string           `json:"failedNodes"`
+	FailedEdges []topology.EdgeRef `json:"failedEdges"`
+	EntryPoints []EntryPoint       `json:"entryPoints"`
+}
+
+// Simulate forces the failures in sim onto the topology and analyzes the result.
+//
+// Failed nodes are set "down". A failed edge is set "down" and its source
+// loses the dependency: the source becomes "down" when the edge is critical
+// and "degraded" otherwise (unless already worse). The nodes and edges passed
+// in are not modified. Unknown node IDs or edges are an error.
+func Simulate(nodes []topology.Node, edges []topology.Edge, sim Simulation, opts Options) (*SimulationResult, error) {
+	nodeIdx := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		nodeIdx[n.ID] = i
+	}
+	edgeIdx := make(map[topology.EdgeRef]int, len(edges))
+	for i, e := range edges {
+		edgeIdx[topology.EdgeRef{Source: e.Source, Target: e.Target}] = i
+	}
+
+	simNodes := append([]topology.Node(nil), nodes...)
+	simEdges := append([]topology.Edge(nil), edges...)
+
+	// origins are the nodes the simulated failures start from.
+	origins := make(map[string]bool)
+	for _, id := range sim.Nodes {
+		i, ok := nodeIdx[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown node %q", id)
+		}
+		simNodes[i].State = "down"
+		origins[id] = true
+	}
+	for _, ref := range sim.Edges {
+		i, ok := edgeIdx[ref]
+		if !ok {
+			return nil, fmt.Errorf("unknown edge %s → %s", ref.Source, ref.Target)
+		}
+		si, ok := nodeIdx[ref.Source]
+		if !ok {
+			return nil, fmt.Errorf("unknown node %q", ref.Source)
+		}
+		simEdges[i].State = "down"
+		simEdges[i].Health = 0
+		src := &simNodes[si]
+		switch {
+		case simEdges[i].Critical:
+			src.State = "down"
+		case src.State == "ok":
+			src.State = "degraded"
+		}
+		origins[ref.Source] = true
+	}
+
+	analysis := Analyze(simNodes, simEdges, opts)
+	result := &SimulationResult{
+		AnalysisResult: *analysis,
+		FailedNodes:    append([]string{}, sim.Nodes...),
+		FailedEdges:    append([]topology.EdgeRef{}, sim.Edges...),
+		EntryPoints:    simulatedEntryPoints(nodes, simNodes, simEdges, origins, opts),
+	}
+	return result, nil
+}
+
+// simulatedEntryPoints returns the entry nodes that are simulated failure
+// origins themselves or are reached from one over critical edges.
+func simulatedEntryPoints(nodes, simNodes []topology.Node, simEdges []topology.Edge, origins map[string]bool, opts Options) []EntryPoint {
+	nodeMap := make(map[string]topology.Node, len(simNodes))
+	for _, n := range simNodes {
+		nodeMap[n.ID] = n
+	}
+	adj := buildAdjacency(simEdges)
+
+	causes := make(map[string]map[string]bool) // entry ID → origin IDs
+	addCause := func(id, origin string) {
+		if causes[id] == nil {
+			causes[id] = make(map[string]bool)
+		}
+		causes[id][origin] = true
+	}
+
+	for origin := range origins {
+		if nodeMap[origin].State != "down" {
+			// Non-critical edge failure: the source is only degraded.
+			continue
+		}
+		addCause(origin, origin)
+		visited := map[string]bool{origin: true}
+		type queueItem struct {
+			id    string
+			depth int
+		}
+		queue := []queueItem{{id: origin}}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			if opts.MaxDepth > 0 && cur.depth >= opts.MaxDepth {
+				continue
+			}
+			for _, e := range adj.incoming[cur.id] {
+				if !e.Critical || visited[e.Source] {
+					continue
+				}
+				visited[e.Source] = true
+				addCause(e.Source, origin)
+				queue = append(queue, queueItem{id: e.Source, depth: cur.depth + 1})
+			}
+		}
+	}
+
+	entries := []EntryPoint{}
+	for _, n := range nodes {
+		if !n.IsEntry || causes[n.ID] == nil {
+			continue
+		}
+		if opts.Namespace != "" && n.Namespace != opts.Namespace {
+			continue
+		}
+		ids := make([]string, 0, len(causes[n.ID]))
+		for id := range causes[n.ID] {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		entries = append(entries, EntryPoint{
+			ID:        n.ID,
+			Label:     n.Label,
+			Namespace: n.Namespace,
+			Cluster:   n.Cluster,
+			State:     n.State,
+			Causes:    ids,
+		})
+	}
+	return entries
+}
@@ -0,0 +1,92 @@
+package cascade
+
+import (
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// simTopology: gateway(entry) → orders → redis, gateway → search ⇢ redis (non-critical),
+// admin(entry) → search.
+func simTopology() ([]topology.Node, []topology.Edge) {
+	gateway := node("gateway", "ok", "service", "ns1")
+	gateway.IsEntry = true
+	admin := node("admin", "ok", "service", "ns1")
+	admin.IsEntry = true
+	nodes := []topology.Node{
+		gateway,
+		admin,
+		node("orders", "ok", "service", "ns1"),
+		node("search", "ok", "service", "ns1"),
+		node("redis", "ok", "redis", "ns1"),
+	}
+	edges := []topology.Edge{
+		critEdge("gateway", "orders", "http"),
+		critEdge("gateway", "search", "http"),
+		critEdge("admin", "search", "http"),
+		critEdge("orders", "redis", "redis"),
+		nonCritEdge("search", "redis", "redis"),
+	}
+	return nodes, edges
+}
+
+func TestSimulate_NodeDown(t *testing.T) {
+	nodes, edges := simTopology()
+
+	result, err := Simulate(nodes, edges, Simulation{Nodes: []string{"redis"}}, Options{})
+	if err != nil {
+		t.Fatalf("Simulate() error: %v", err)
+	}
+	if len(result.RootCauses) != 1 || result.RootCauses[0].ID != "redis" {
+		t.Errorf("expected root cause redis, got %v", result.RootCauses)
+	}
+	affected := make(map[string]bool)
+	for _, as := range result.AffectedServices {
+		affected[as.Service] = true
+	}
+	if !affected["orders"] || !affected["gateway"] || affected["search"] || affected["admin"] {
+		t.Errorf("expected orders and gateway affected, got %v", result.AffectedServices)
+	}
+	if len(result.EntryPoints) != 1 || result.EntryPoints[0].ID != "gateway" {
+		t.Fatalf("expected entry point gateway, got %v", result.EntryPoints)
+	}
+	if c := result.EntryPoints[0].Causes; len(c) != 1 || c[0] != "redis" {
+		t.Errorf("expected causes [redis], got %v", c)
+	}
+	if nodes[4].State != "ok" {
+		t.Error("Simulate modified the input nodes")
+	}
+}
+
+func TestSimulate_Edges(t *testing.T) {
+	nodes, edges := simTopology()
+
+	// A critical edge takes its source down.
+	result, err := Simulate(nodes, edges, Simulation{Edges: []topology.EdgeRef{{Source: "admin", Target: "search"}}}, Options{})
+	if err != nil {
+		t.Fatalf("Simulate() error: %v", err)
+	}
+	if len(result.EntryPoints) != 1 || result.EntryPoints[0].ID != "admin" {
+		t.Errorf("expected entry point admin, got %v", result.EntryPoints)
+	}
+
+	// A non-critical edge only degrades its source.
+	result, err = Simulate(nodes, edges, Simulation{Edges: []topology.EdgeRef{{Source: "search", Target: "redis"}}}, Options{})
+	if err != nil {
+		t.Fatalf("Simulate() error: %v", err)
+	}
+	if len(result.EntryPoints) != 0 || len(result.AffectedServices) != 0 {
+		t.Errorf("expected no impact, got entry points %v, affected %v", result.EntryPoints, result.AffectedServices)
+	}
+}
+
+func TestSimulate_Unknown(t *testing.T) {
+	nodes, edges := simTopology()
+
+	if _, err := Simulate(nodes, edges, Simulation{Nodes: []string{"nope"}}, Options{}); err == nil {
+		t.Error("expected error for unknown node")
+	}
+	if _, err := Simulate(nodes, edges, Simulation{Edges: []topology.EdgeRef{{Source: "redis", Target: "gateway"}}}, Options{}); err == nil {
+		t.Error("expected error for unknown edge")
+	}
+}
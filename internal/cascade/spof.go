@@ -0,0 +1,245 @@
+package cascade
+
+import (
+	"sort"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// NodeRisk describes how much of the topology depends on a node through
+// critical edges.
+type NodeRisk struct {
+	ID        string `json:"id"`
+	Label     string `json:"label"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	State     string `json:"state"`
+	// EntryDependents is the number of entry points (Node.IsEntry) that
+	// transitively depend on the node.
+	EntryDependents int `json:"entryDependents"`
+	// Dependents is the number of services that transitively depend on the node.
+	Dependents int `json:"dependents"`
+	// Articulation is set when removing the node disconnects the critical graph.
+	Articulation bool `json:"articulation"`
+}
+
+// SPOFSummary provides aggregate counts for the SPOF analysis.
+type SPOFSummary struct {
+	TotalNodes         int `json:"totalNodes"`
+	CriticalEdges      int `json:"criticalEdges"`
+	EntryPoints        int `json:"entryPoints"`
+	ArticulationPoints int `json:"articulationPoints"`
+	Bridges            int `json:"bridges"`
+}
+
+// SPOFResult is the output of single point of failure analysis.
+type SPOFResult struct {
+	// ArticulationPoints are the node IDs whose removal disconnects the
+	// critical graph, sorted.
+	ArticulationPoints []string `json:"articulationPoints"`
+	// Bridges are the critical edges whose removal disconnects the critical graph.
+	Bridges []topology.EdgeRef `json:"bridges"`
+	// Ranking lists the nodes with at least one dependent, by blast radius:
+	// entry dependents, then dependents, descending.
+	Ranking []NodeRisk  `json:"ranking"`
+	Summary SPOFSummary `json:"summary"`
+}
+
+// AnalyzeSPOF finds single points of failure in the graph formed by the
+// critical edges, regardless of the current node states. Articulation points
+// and bridges are computed on the undirected critical graph; blast radius
+// follows the dependency direction. With opts.Namespace set, the ranking and
+// articulation points are limited to nodes in that namespace and bridges to
+// edges whose source is in it; the analysis itself always covers the whole
+// topology.
+func AnalyzeSPOF(nodes []topology.Node, edges []topology.Edge, opts Options) *SPOFResult {
+	nodeMap := make(map[string]topology.Node, len(nodes))
+	for _, n := range nodes {
+		nodeMap[n.ID] = n
+	}
+
+	var critical []topology.Edge
+	for _, e := range edges {
+		_, srcOK := nodeMap[e.Source]
+		_, tgtOK := nodeMap[e.Target]
+		if e.Critical && srcOK && tgtOK && e.Source != e.Target {
+			critical = append(critical, e)
+		}
+	}
+	adj := buildAdjacency(critical)
+
+	articulation, bridges := articulationPoints(nodes, critical)
+
+	// Blast radius: walk downstream from every service (and every entry
+	// point) and credit each reached node.
+	dependents := make(map[string]int)
+	entryDependents := make(map[string]int)
+	entryCount := 0
+	for _, n := range nodes {
+		if n.IsEntry {
+			entryCount++
+		}
+		if n.Type != "service" && !n.IsEntry {
+			continue
+		}
+		for id := range downstream(n.ID, adj, opts.MaxDepth) {
+			if n.Type == "service" {
+				dependents[id]++
+			}
+			if n.IsEntry {
+				entryDependents[id]++
+			}
+		}
+	}
+
+	inNamespace := func(id string) bool {
+		return opts.Namespace == "" || nodeMap[id].Namespace == opts.Namespace
+	}
+
+	result := &SPOFResult{
+		ArticulationPoints: []string{},
+		Bridges:            []topology.EdgeRef{},
+		Ranking:            []NodeRisk{},
+	}
+	for id := range articulation {
+		if inNamespace(id) {
+			result.ArticulationPoints = append(result.ArticulationPoints, id)
+		}
+	}
+	sort.Strings(result.ArticulationPoints)
+	for _, b := range bridges {
+		if inNamespace(b.Source) {
+			result.Bridges = append(result.Bridges, b)
+		}
+	}
+	sort.Slice(result.Bridges, func(i, j int) bool {
+		if result.Bridges[i].Source != result.Bridges[j].Source {
+			return result.Bridges[i].Source < result.Bridges[j].Source
+		}
+		return result.Bridges[i].Target < result.Bridges[j].Target
+	})
+
+	for _, n := range nodes {
+		if dependents[n.ID] == 0 && entryDependents[n.ID] == 0 {
+			continue
+		}
+		if !inNamespace(n.ID) {
+			continue
+		}
+		result.Ranking = append(result.Ranking, NodeRisk{
+			ID:              n.ID,
+			Label:           n.Label,
+			Type:            n.Type,
+			Namespace:       n.Namespace,
+			State:           n.State,
+			EntryDependents: entryDependents[n.ID],
+			Dependents:      dependents[n.ID],
+			Articulation:    articulation[n.ID],
+		})
+	}
+	sort.Slice(result.Ranking, func(i, j int) bool {
+		a, b := result.Ranking[i], result.Ranking[j]
+		if a.EntryDependents != b.EntryDependents {
+			return a.EntryDependents > b.EntryDependents
+		}
+		if a.Dependents != b.Dependents {
+			return a.Dependents > b.Dependents
+		}
+		if a.Articulation != b.Articulation {
+			return a.Articulation
+		}
+		return a.ID < b.ID
+	})
+
+	result.Summary = SPOFSummary{
+		TotalNodes:         len(nodes),
+		CriticalEdges:      len(critical),
+		EntryPoints:        entryCount,
+		ArticulationPoints: len(result.ArticulationPoints),
+		Bridges:            len(result.Bridges),
+	}
+	return result
+}
+
+// downstream returns the nodes reachable from id over the edges in adj,
+// excluding id itself, within maxDepth hops (0 = unlimited).
+func downstream(id string, adj adjacency, maxDepth int) map[string]bool {
+	reached := make(map[string]bool)
+	type queueItem struct {
+		id    string
+		depth int
+	}
+	queue := []queueItem{{id: id}}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if maxDepth > 0 && cur.depth >= maxDepth {
+			continue
+		}
+		for _, e := range adj.outgoing[cur.id] {
+			if e.Target == id || reached[e.Target] {
+				continue
+			}
+			reached[e.Target] = true
+			queue = append(queue, queueItem{id: e.Target, depth: cur.depth + 1})
+		}
+	}
+	return reached
+}
+
+// articulationPoints runs Tarjan's algorithm on the undirected view of edges
+// and returns the articulation points and bridges. Parallel edges (including
+// A→B together with B→A) are never bridges.
+func articulationPoints(nodes []topology.Node, edges []topology.Edge) (map[string]bool, []topology.EdgeRef) {
+	type halfEdge struct {
+		to string
+		id int // index into edges
+	}
+	graph := make(map[string][]halfEdge)
+	for i, e := range edges {
+		graph[e.Source] = append(graph[e.Source], halfEdge{to: e.Target, id: i})
+		graph[e.Target] = append(graph[e.Target], halfEdge{to: e.Source, id: i})
+	}
+
+	disc := make(map[string]int)
+	low := make(map[string]int)
+	points := make(map[string]bool)
+	var bridges []topology.EdgeRef
+	timer := 0
+
+	var visit func(u string, parentEdge int)
+	visit = func(u string, parentEdge int) {
+		timer++
+		disc[u], low[u] = timer, timer
+		children := 0
+		for _, he := range graph[u] {
+			if he.id == parentEdge {
+				continue
+			}
+			if _, seen := disc[he.to]; seen {
+				low[u] = min(low[u], disc[he.to])
+				continue
+			}
+			children++
+			visit(he.to, he.id)
+			low[u] = min(low[u], low[he.to])
+			if parentEdge >= 0 && low[he.to] >= disc[u] {
+				points[u] = true
+			}
+			if low[he.to] > disc[u] {
+				e := edges[he.id]
+				bridges = append(bridges, topology.EdgeRef{Source: e.Source, Target: e.Target})
+			}
+		}
+		if parentEdge < 0 && children > 1 {
+			points[u] = true
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := disc[n.ID]; !seen && len(graph[n.ID]) > 0 {
+			visit(n.ID, -1)
+		}
+	}
+	return points, bridges
+}
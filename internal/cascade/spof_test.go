@@ -0,0 +1,100 @@
+package cascade
+
+import (
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// spofTopology: gateway(entry) → api → db, gateway → auth → db,
+// admin(entry) → api, api ⇢ cache (non-critical).
+func spofTopology() ([]topology.Node, []topology.Edge) {
+	gateway := node("gateway", "ok", "service", "ns1")
+	gateway.IsEntry = true
+	admin := node("admin", "ok", "service", "ns2")
+	admin.IsEntry = true
+	nodes := []topology.Node{
+		gateway,
+		admin,
+		node("api", "ok", "service", "ns1"),
+		node("auth", "ok", "service", "ns1"),
+		node("db", "ok", "postgres", "ns1"),
+		node("cache", "ok", "redis", "ns1"),
+	}
+	edges := []topology.Edge{
+		critEdge("gateway", "api", "http"),
+		critEdge("gateway", "auth", "http"),
+		critEdge("admin", "api", "http"),
+		critEdge("api", "db", "postgres"),
+		critEdge("auth", "db", "postgres"),
+		nonCritEdge("api", "cache", "redis"),
+	}
+	return nodes, edges
+}
+
+func TestAnalyzeSPOF(t *testing.T) {
+	nodes, edges := spofTopology()
+	result := AnalyzeSPOF(nodes, edges, Options{})
+
+	if len(result.ArticulationPoints) != 1 || result.ArticulationPoints[0] != "api" {
+		t.Errorf("expected articulation point api, got %v", result.ArticulationPoints)
+	}
+	if len(result.Bridges) != 1 || result.Bridges[0] != (topology.EdgeRef{Source: "admin", Target: "api"}) {
+		t.Errorf("expected bridge admin → api, got %v", result.Bridges)
+	}
+
+	want := []struct {
+		id                  string
+		entries, dependents int
+		articulation        bool
+	}{
+		{"db", 2, 4, false},
+		{"api", 2, 2, true},
+		{"auth", 1, 1, false},
+	}
+	if len(result.Ranking) != len(want) {
+		t.Fatalf("expected %d ranked nodes, got %+v", len(want), result.Ranking)
+	}
+	for i, w := range want {
+		r := result.Ranking[i]
+		if r.ID != w.id || r.EntryDependents != w.entries || r.Dependents != w.dependents || r.Articulation != w.articulation {
+			t.Errorf("ranking[%d] = %+v, want %+v", i, r, w)
+		}
+	}
+
+	s := result.Summary
+	if s.TotalNodes != 6 || s.CriticalEdges != 5 || s.EntryPoints != 2 || s.ArticulationPoints != 1 || s.Bridges != 1 {
+		t.Errorf("unexpected summary %+v", s)
+	}
+}
+
+func TestAnalyzeSPOF_ParallelEdgesAreNotBridges(t *testing.T) {
+	nodes := []topology.Node{
+		node("A", "ok", "service", "ns1"),
+		node("B", "ok", "service", "ns1"),
+	}
+	edges := []topology.Edge{
+		critEdge("A", "B", "grpc"),
+		critEdge("B", "A", "grpc"),
+	}
+	result := AnalyzeSPOF(nodes, edges, Options{})
+	if len(result.Bridges) != 0 || len(result.ArticulationPoints) != 0 {
+		t.Errorf("expected no bridges or articulation points, got %v, %v", result.Bridges, result.ArticulationPoints)
+	}
+	if len(result.Ranking) != 2 {
+		t.Errorf("expected both nodes ranked, got %v", result.Ranking)
+	}
+}
+
+func TestAnalyzeSPOF_Namespace(t *testing.T) {
+	nodes, edges := spofTopology()
+	result := AnalyzeSPOF(nodes, edges, Options{Namespace: "ns2"})
+
+	// admin depends on others but nothing depends on admin.
+	if len(result.Ranking) != 0 || len(result.ArticulationPoints) != 0 {
+		t.Errorf("expected empty ranking in ns2, got %v", result.Ranking)
+	}
+	if len(result.Bridges) != 1 {
+		t.Errorf("expected bridge admin → api in ns2, got %v", result.Bridges)
+	}
+}
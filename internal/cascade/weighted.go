@@ -0,0 +1,248 @@
+package cascade
+
+import (
+	"math"
+	"sort"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// Analysis modes.
+const (
+	// ModeStrict seeds only from "down" nodes and follows critical edges (default).
+	ModeStrict = "strict"
+	// ModeWeighted also seeds from degraded and unknown nodes, follows
+	// non-critical edges with a reduced weight, and ranks the results.
+	ModeWeighted = "weighted"
+)
+
+// DefaultNonCriticalWeight is the propagation weight of non-critical edges
+// in weighted mode (critical edges weigh 1).
+const DefaultNonCriticalWeight = 0.3
+
+// minScore is the smallest candidate or impact score kept in weighted mode.
+const minScore = 0.05
+
+// stateSeverity maps a node or edge state to a failure severity in [0, 1].
+func stateSeverity(state string) float64 {
+	switch state {
+	case "down":
+		return 1
+	case "unknown":
+		return 0.8
+	case "degraded":
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// transmission returns how much of a dependency's failure reaches its
+// consumer over edge e: the criticality weight, halved for edges the
+// consumer still reports healthy.
+func (o Options) transmission(e topology.Edge) float64 {
+	w := 1.0
+	if !e.Critical {
+		w = DefaultNonCriticalWeight
+		if o.NonCriticalWeight != nil {
+			w = *o.NonCriticalWeight
+		}
+	}
+	return w * (0.5 + 0.5*stateSeverity(e.State))
+}
+
+// analyzeWeighted implements ModeWeighted.
+//
+// Every unhealthy node is a root cause candidate. Its own share of the
+// failure is its severity minus the part explained by its unhealthy
+// dependencies (the strongest dependency severity times the edge
+// transmission); candidate probabilities are these shares normalized to 1.
+// Each candidate's share then propagates upstream, multiplied by the
+// transmission of every edge on the way; a service's impact is the
+// strongest share reaching it.
+func analyzeWeighted(nodes []topology.Node, edges []topology.Edge, opts Options) *AnalysisResult {
+	nodeMap := make(map[string]topology.Node, len(nodes))
+	for _, n := range nodes {
+		nodeMap[n.ID] = n
+	}
+	adj := buildAdjacency(edges)
+
+	// Root cause candidates and their intrinsic failure share.
+	intrinsic := make(map[string]float64)
+	var total float64
+	for _, n := range nodes {
+		sev := stateSeverity(n.State)
+		if sev == 0 {
+			continue
+		}
+		explained := 0.0
+		for _, e := range adj.outgoing[n.ID] {
+			t, ok := nodeMap[e.Target]
+			if !ok {
+				continue
+			}
+			explained = math.Max(explained, opts.transmission(e)*stateSeverity(t.State))
+		}
+		share := sev * (1 - math.Min(explained, 1))
+		if share < minScore {
+			continue
+		}
+		intrinsic[n.ID] = share
+		total += share
+	}
+
+	result := &AnalysisResult{
+		RootCauses:       []RootCause{},
+		AffectedServices: []AffectedService{},
+		AllFailures:      []Failure{},
+		CascadeChains:    []CascadeChain{},
+	}
+	for id, share := range intrinsic {
+		n := nodeMap[id]
+		result.RootCauses = append(result.RootCauses, RootCause{
+			ID:          n.ID,
+			Label:       n.Label,
+			Type:        n.Type,
+			Namespace:   n.Namespace,
+			State:       n.State,
+			Probability: round3(share / total),
+		})
+	}
+	sort.Slice(result.RootCauses, func(i, j int) bool {
+		a, b := result.RootCauses[i], result.RootCauses[j]
+		if a.Probability != b.Probability {
+			return a.Probability > b.Probability
+		}
+		return a.ID < b.ID
+	})
+
+	// Upstream propagation of each candidate's share.
+	type reach struct {
+		score float64
+		next  string // next hop towards the candidate
+		depth int
+	}
+	impacts := make(map[string]map[string]reach) // service ID → candidate ID → reach
+	for _, rc := range result.RootCauses {
+		best := map[string]reach{rc.ID: {score: intrinsic[rc.ID]}}
+		queue := []string{rc.ID}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			r := best[cur]
+			if opts.MaxDepth > 0 && r.depth >= opts.MaxDepth {
+				continue
+			}
+			for _, e := range adj.incoming[cur] {
+				score := r.score * opts.transmission(e)
+				if score < minScore || e.Source == rc.ID {
+					continue
+				}
+				if old, ok := best[e.Source]; ok && old.score >= score {
+					continue
+				}
+				best[e.Source] = reach{score: score, next: cur, depth: r.depth + 1}
+				queue = append(queue, e.Source)
+			}
+		}
+		for id, r := range best {
+			if id == rc.ID || nodeMap[id].Type != "service" {
+				continue
+			}
+			if impacts[id] == nil {
+				impacts[id] = make(map[string]reach)
+			}
+			impacts[id][rc.ID] = r
+
+			// Chain: follow next hops from the service to the candidate.
+			path := []string{nodeMap[id].Label}
+			for hop := r.next; hop != ""; hop = best[hop].next {
+				path = append(path, nodeMap[hop].Label)
+			}
+			result.CascadeChains = append(result.CascadeChains, CascadeChain{
+				AffectedService: nodeMap[id].Label,
+				Namespace:       nodeMap[id].Namespace,
+				DependsOn:       rc.Label,
+				Path:            path,
+				Depth:           len(path) - 1,
+			})
+		}
+	}
+
+	for id, byCause := range impacts {
+		n := nodeMap[id]
+		causes := make([]string, 0, len(byCause))
+		for rc := range byCause {
+			causes = append(causes, rc)
+		}
+		sort.Slice(causes, func(i, j int) bool {
+			a, b := byCause[causes[i]].score, byCause[causes[j]].score
+			if a != b {
+				return a > b
+			}
+			return causes[i] < causes[j]
+		})
+		top := byCause[causes[0]]
+		result.AffectedServices = append(result.AffectedServices, AffectedService{
+			Service:    n.Label,
+			Namespace:  n.Namespace,
+			DependsOn:  nodeMap[top.next].Label,
+			RootCauses: causes,
+			Impact:     round3(top.score),
+		})
+	}
+	sort.Slice(result.AffectedServices, func(i, j int) bool {
+		a, b := result.AffectedServices[i], result.AffectedServices[j]
+		if a.Impact != b.Impact {
+			return a.Impact > b.Impact
+		}
+		return a.Service < b.Service
+	})
+	sort.Slice(result.CascadeChains, func(i, j int) bool {
+		a, b := result.CascadeChains[i], result.CascadeChains[j]
+		if a.AffectedService != b.AffectedService {
+			return a.AffectedService < b.AffectedService
+		}
+		return a.DependsOn < b.DependsOn
+	})
+
+	// All failures: unhealthy edges and edges into unhealthy nodes.
+	for _, e := range edges {
+		target, ok := nodeMap[e.Target]
+		if !ok || (stateSeverity(e.State) == 0 && stateSeverity(target.State) == 0) {
+			continue
+		}
+		source := nodeMap[e.Source]
+		result.AllFailures = append(result.AllFailures, Failure{
+			Service:    source.Label,
+			Namespace:  source.Namespace,
+			Dependency: target.Label,
+			Type:       e.Type,
+			Host:       target.Host,
+			Port:       target.Port,
+		})
+	}
+
+	serviceCount := 0
+	for _, n := range nodes {
+		if n.Type == "service" {
+			serviceCount++
+		}
+	}
+	maxDepth := 0
+	for _, c := range result.CascadeChains {
+		maxDepth = max(maxDepth, c.Depth)
+	}
+	result.Summary = Summary{
+		TotalServices:        serviceCount,
+		RootCauseCount:       len(result.RootCauses),
+		AffectedServiceCount: len(result.AffectedServices),
+		TotalFailureCount:    len(result.AllFailures),
+		MaxDepth:             maxDepth,
+	}
+	return result
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
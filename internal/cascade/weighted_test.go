@@ -0,0 +1,124 @@
+package cascade
+
+import (
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// helper to set the state of an edge.
+func withState(e topology.Edge, state string) topology.Edge {
+	e.State = state
+	return e
+}
+
+func TestAnalyzeWeighted_DegradedChain(t *testing.T) {
+	// A(degraded) → DB(degraded): strict mode finds nothing, weighted mode
+	// ranks DB above A, since A's degradation is partly explained by DB.
+	nodes := []topology.Node{
+		node("A", "degraded", "service", "ns1"),
+		node("DB", "degraded", "dependency", "ns1"),
+	}
+	edges := []topology.Edge{withState(critEdge("A", "DB", "postgres"), "degraded")}
+
+	if strict := Analyze(nodes, edges, Options{}); len(strict.RootCauses) != 0 {
+		t.Fatalf("strict: expected no root causes, got %v", strict.RootCauses)
+	}
+
+	result := Analyze(nodes, edges, Options{Mode: ModeWeighted})
+	if len(result.RootCauses) != 2 {
+		t.Fatalf("expected 2 root cause candidates, got %v", result.RootCauses)
+	}
+	if result.RootCauses[0].ID != "DB" || result.RootCauses[1].ID != "A" {
+		t.Errorf("expected ranking [DB A], got %v", result.RootCauses)
+	}
+	if p := result.RootCauses[0].Probability; p != 0.615 {
+		t.Errorf("expected DB probability 0.615, got %v", p)
+	}
+	if len(result.AffectedServices) != 1 || result.AffectedServices[0].Service != "A" {
+		t.Fatalf("expected A affected, got %v", result.AffectedServices)
+	}
+	// 0.5 (DB) × 0.75 (critical, degraded edge)
+	if got := result.AffectedServices[0].Impact; got != 0.375 {
+		t.Errorf("expected impact 0.375, got %v", got)
+	}
+	if result.Summary.TotalFailureCount != 1 {
+		t.Errorf("expected 1 failure, got %d", result.Summary.TotalFailureCount)
+	}
+}
+
+func TestAnalyzeWeighted_NonCriticalDependency(t *testing.T) {
+	nodes := []topology.Node{
+		node("A", "ok", "service", "ns1"),
+		node("Cache", "down", "dependency", "ns1"),
+	}
+	edges := []topology.Edge{withState(nonCritEdge("A", "Cache", "redis"), "down")}
+
+	if strict := Analyze(nodes, edges, Options{}); len(strict.AffectedServices) != 0 {
+		t.Fatalf("strict: expected no affected services, got %v", strict.AffectedServices)
+	}
+
+	result := Analyze(nodes, edges, Options{Mode: ModeWeighted})
+	if len(result.AffectedServices) != 1 || result.AffectedServices[0].Impact != DefaultNonCriticalWeight {
+		t.Fatalf("expected A with impact %v, got %v", DefaultNonCriticalWeight, result.AffectedServices)
+	}
+
+	low := 0.01
+	result = Analyze(nodes, edges, Options{Mode: ModeWeighted, NonCriticalWeight: &low})
+	if len(result.AffectedServices) != 0 {
+		t.Errorf("expected impact below cutoff to be dropped, got %v", result.AffectedServices)
+	}
+
+	// An explicit 0 stops propagation over non-critical edges.
+	none := 0.0
+	result = Analyze(nodes, edges, Options{Mode: ModeWeighted, NonCriticalWeight: &none})
+	if len(result.AffectedServices) != 0 {
+		t.Errorf("expected no propagation with weight 0, got %v", result.AffectedServices)
+	}
+}
+
+func TestAnalyzeWeighted_DownChain(t *testing.T) {
+	// Front(ok) → API(down) → DB(down): API is fully explained by DB.
+	nodes := []topology.Node{
+		node("Front", "ok", "service", "ns1"),
+		node("API", "down", "service", "ns1"),
+		node("DB", "down", "dependency", "ns2"),
+	}
+	edges := []topology.Edge{
+		withState(critEdge("Front", "API", "http"), "down"),
+		withState(critEdge("API", "DB", "postgres"), "down"),
+	}
+
+	result := Analyze(nodes, edges, Options{Mode: ModeWeighted})
+	if len(result.RootCauses) != 1 || result.RootCauses[0].ID != "DB" || result.RootCauses[0].Probability != 1 {
+		t.Fatalf("expected DB with probability 1, got %v", result.RootCauses)
+	}
+	if len(result.AffectedServices) != 2 {
+		t.Fatalf("expected 2 affected services, got %v", result.AffectedServices)
+	}
+	for _, as := range result.AffectedServices {
+		if as.Impact != 1 || len(as.RootCauses) != 1 || as.RootCauses[0] != "DB" {
+			t.Errorf("unexpected affected service %+v", as)
+		}
+	}
+
+	var front *CascadeChain
+	for i := range result.CascadeChains {
+		if result.CascadeChains[i].AffectedService == "Front" {
+			front = &result.CascadeChains[i]
+		}
+	}
+	if front == nil || front.Depth != 2 || len(front.Path) != 3 || front.Path[1] != "API" {
+		t.Errorf("expected chain Front → API → DB, got %+v", front)
+	}
+
+	limited := Analyze(nodes, edges, Options{Mode: ModeWeighted, MaxDepth: 1})
+	if len(limited.AffectedServices) != 1 || limited.AffectedServices[0].Service != "API" {
+		t.Errorf("MaxDepth=1: expected only API affected, got %v", limited.AffectedServices)
+	}
+
+	filtered := Analyze(nodes, edges, Options{Mode: ModeWeighted, Namespace: "ns1"})
+	if len(filtered.RootCauses) != 0 || len(filtered.AffectedServices) != 2 {
+		t.Errorf("namespace filter: got %d root causes, %d affected", len(filtered.RootCauses), len(filtered.AffectedServices))
+	}
+}
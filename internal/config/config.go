@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -15,22 +17,187 @@ import (
 
 var hexColorRe = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
 
+// NotificationEventKinds lists the event kinds a webhook can subscribe to.
+var NotificationEventKinds = []string{"node_state", "edge_state", "service_appeared", "service_disappeared", "root_cause"}
+
+// TopologyQueryNames lists the datasource queries of a topology build, as
+// used in topology.queries.timeouts and in the build timings of the topology meta.
+var TopologyQueryNames = []string{
+	"edges", "health", "avgLatency", "latencyP50", "latencyP95", "latencyP99",
+	"errorRatio", "status", "statusDetail", "flapping", "historicalAlerts", "alerts",
+}
+
 // Config holds the complete application configuration.
 type Config struct {
-	Server      ServerConfig      `yaml:"server"`
-	Datasources DatasourcesConfig `yaml:"datasources"`
-	Cache       CacheConfig       `yaml:"cache"`
-	Topology    TopologyConfig    `yaml:"topology"`
-	Auth        AuthConfig        `yaml:"auth"`
-	Grafana     GrafanaConfig     `yaml:"grafana"`
-	Alerts      AlertsConfig      `yaml:"alerts"`
-	Log         logging.LogConfig `yaml:"log"`
+	Server        ServerConfig        `yaml:"server"`
+	Datasources   DatasourcesConfig   `yaml:"datasources"`
+	Cache         CacheConfig         `yaml:"cache"`
+	Snapshots     SnapshotsConfig     `yaml:"snapshots"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Topology      TopologyConfig      `yaml:"topology"`
+	Auth          AuthConfig          `yaml:"auth"`
+	Grafana       GrafanaConfig       `yaml:"grafana"`
+	Alerts        AlertsConfig        `yaml:"alerts"`
+	Metrics       MetricsConfig       `yaml:"metrics"`
+	Log           logging.LogConfig   `yaml:"log"`
+}
+
+// MetricsConfig holds the metric names, label names and label values the
+// PromQL queries use, for relabelled metrics or forks of the dephealth SDK.
+// Empty fields take the SDK defaults (see WithDefaults).
+type MetricsConfig struct {
+	Names  MetricNamesConfig  `yaml:"names"`
+	Labels MetricLabelsConfig `yaml:"labels"`
+	Values MetricValuesConfig `yaml:"values"`
+	// Extra label matchers appended to every query selector, including the
+	// ALERTS query in history mode, e.g. `cluster="eu-1"` or `env=~"prod|stage"`.
+	Matchers []string `yaml:"matchers"`
+}
+
+// MetricNamesConfig holds the dephealth metric names.
+type MetricNamesConfig struct {
+	Health string `yaml:"health"` // default: app_dependency_health
+	// Latency histogram base name; _sum, _count and _bucket are appended
+	// (default: app_dependency_latency_seconds).
+	Latency      string `yaml:"latency"`
+	Status       string `yaml:"status"`       // default: app_dependency_status
+	StatusDetail string `yaml:"statusDetail"` // default: app_dependency_status_detail
 }
 
-// AlertsConfig holds alert severity display settings.
+// MetricLabelsConfig holds the label names of the dephealth metrics. The
+// defaults are the names of the fields in lower case.
+type MetricLabelsConfig struct {
+	Name       string `yaml:"name"`
+	Namespace  string `yaml:"namespace"`
+	Group      string `yaml:"group"`
+	Dependency string `yaml:"dependency"`
+	Type       string `yaml:"type"`
+	Host       string `yaml:"host"`
+	Port       string `yaml:"port"`
+	Critical   string `yaml:"critical"`
+	IsEntry    string `yaml:"isEntry"` // default: isentry
+	Status     string `yaml:"status"`
+	Detail     string `yaml:"detail"`
+}
+
+// MetricValuesConfig holds the label values with a special meaning.
+type MetricValuesConfig struct {
+	Critical []string `yaml:"critical"` // critical label values of critical edges (default: ["yes"])
+	IsEntry  []string `yaml:"isEntry"`  // isentry label values of entry points (default: ["yes"])
+	StatusOK string   `yaml:"statusOk"` // status label value of a healthy edge (default: "ok")
+}
+
+// WithDefaults returns m with every empty field set to the dephealth SDK default.
+func (m MetricsConfig) WithDefaults() MetricsConfig {
+	def := func(v *string, d string) {
+		if *v == "" {
+			*v = d
+		}
+	}
+	def(&m.Names.Health, "app_dependency_health")
+	def(&m.Names.Latency, "app_dependency_latency_seconds")
+	def(&m.Names.Status, "app_dependency_status")
+	def(&m.Names.StatusDetail, "app_dependency_status_detail")
+	l := &m.Labels
+	def(&l.Name, "name")
+	def(&l.Namespace, "namespace")
+	def(&l.Group, "group")
+	def(&l.Dependency, "dependency")
+	def(&l.Type, "type")
+	def(&l.Host, "host")
+	def(&l.Port, "port")
+	def(&l.Critical, "critical")
+	def(&l.IsEntry, "isentry")
+	def(&l.Status, "status")
+	def(&l.Detail, "detail")
+	if len(m.Values.Critical) == 0 {
+		m.Values.Critical = []string{"yes"}
+	}
+	if len(m.Values.IsEntry) == 0 {
+		m.Values.IsEntry = []string{"yes"}
+	}
+	def(&m.Values.StatusOK, "ok")
+	return m
+}
+
+var (
+	promMetricNameRe = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	promLabelNameRe  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	promMatcherRe    = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*\s*(=|!=|=~|!~)\s*"(?:[^"\\]|\\.)*"$`)
+)
+
+// validate checks that names are valid PromQL identifiers and matchers are
+// well-formed, so that they can be pasted into queries.
+func (m MetricsConfig) validate() error {
+	m = m.WithDefaults()
+	for field, name := range map[string]string{
+		"health": m.Names.Health, "latency": m.Names.Latency,
+		"status": m.Names.Status, "statusDetail": m.Names.StatusDetail,
+	} {
+		if !promMetricNameRe.MatchString(name) {
+			return fmt.Errorf("metrics.names.%s %q is not a valid metric name", field, name)
+		}
+	}
+	l := m.Labels
+	for field, name := range map[string]string{
+		"name": l.Name, "namespace": l.Namespace, "group": l.Group, "dependency": l.Dependency,
+		"type": l.Type, "host": l.Host, "port": l.Port, "critical": l.Critical,
+		"isEntry": l.IsEntry, "status": l.Status, "detail": l.Detail,
+	} {
+		if !promLabelNameRe.MatchString(name) {
+			return fmt.Errorf("metrics.labels.%s %q is not a valid label name", field, name)
+		}
+	}
+	for i, mt := range m.Matchers {
+		if !promMatcherRe.MatchString(strings.TrimSpace(mt)) {
+			return fmt.Errorf("metrics.matchers[%d] %q must be a label matcher like label=\"value\"", i, mt)
+		}
+	}
+	return nil
+}
+
+// AlertsConfig holds alert severity display and topology mapping settings.
 type AlertsConfig struct {
 	SeverityLabel  string          `yaml:"severityLabel"`
 	SeverityLevels []SeverityLevel `yaml:"severityLevels"`
+	// Which alert labels identify the edge an alert belongs to and which
+	// alerts override edge state.
+	Mapping AlertMappingConfig `yaml:"mapping"`
+	// Silences created through the API.
+	Silences SilencesConfig `yaml:"silences"`
+}
+
+// SilencesConfig controls silences created and expired through the API.
+type SilencesConfig struct {
+	// AllowAnonymous permits unauthenticated users to manage silences, which
+	// is only possible with auth.type "none". Off by default.
+	AllowAnonymous bool `yaml:"allowAnonymous"`
+	// MaxDuration is the longest silence that can be created; 0 disables the limit.
+	MaxDuration time.Duration `yaml:"maxDuration"`
+}
+
+// AlertMappingConfig maps alert labels to topology entities. Each label
+// list is tried in order; the first label present on the alert wins.
+// An alert is attached to an edge by service plus dependency, or by
+// service plus host:port when it has no dependency label.
+type AlertMappingConfig struct {
+	ServiceLabels    []string `yaml:"serviceLabels"`
+	DependencyLabels []string `yaml:"dependencyLabels"`
+	NamespaceLabels  []string `yaml:"namespaceLabels"`
+	HostLabels       []string `yaml:"hostLabels"`
+	PortLabels       []string `yaml:"portLabels"`
+	// Labels holding a combined "host:port" value.
+	EndpointLabels []string `yaml:"endpointLabels"`
+	// Rules mapping alerts to edge states; the first matching rule wins.
+	StateRules []AlertStateRule `yaml:"stateRules"`
+}
+
+// AlertStateRule forces an edge state for matching alerts. The alert name
+// (when set) and every matcher label must match exactly.
+type AlertStateRule struct {
+	AlertName string            `yaml:"alertName"`
+	Matchers  map[string]string `yaml:"matchers"`
+	State     string            `yaml:"state"` // "down" or "degraded"
 }
 
 // SeverityLevel defines a single alert severity level with its display color.
@@ -46,27 +213,236 @@ type ServerConfig struct {
 
 // DatasourcesConfig holds external datasource connection settings.
 type DatasourcesConfig struct {
-	Prometheus  PrometheusConfig  `yaml:"prometheus"`
-	Alertmanager AlertmanagerConfig `yaml:"alertmanager"`
+	Prometheus PrometheusConfig `yaml:"prometheus"`
+	// Named Prometheus/VictoriaMetrics datasources (e.g. one per cluster),
+	// queried in parallel and merged into one topology. When set, replaces
+	// prometheus; the first entry also serves timeline and instance queries.
+	PrometheusSources []PrometheusSourceConfig `yaml:"prometheusSources"`
+	Alertmanager      AlertmanagerConfig       `yaml:"alertmanager"`
+	// Retries and circuit breaking of the queries to every Prometheus datasource.
+	Resilience ResilienceConfig `yaml:"resilience"`
+}
+
+// ResilienceConfig holds retry and circuit breaker settings for Prometheus queries.
+type ResilienceConfig struct {
+	Retry          RetryConfig          `yaml:"retry"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuitBreaker"`
+}
+
+// RetryConfig controls retries of failed queries (network errors, 429 and
+// 5xx responses). Waits between attempts grow exponentially from Backoff up
+// to MaxBackoff with random jitter; a longer Retry-After is honoured.
+type RetryConfig struct {
+	// Attempts per query including the first (default: 3, 0 or 1 disables retries).
+	MaxAttempts int           `yaml:"maxAttempts"`
+	Backoff     time.Duration `yaml:"backoff"`    // default: 200ms
+	MaxBackoff  time.Duration `yaml:"maxBackoff"` // default: 2s
+}
+
+// CircuitBreakerConfig controls the per-datasource circuit breaker. After
+// FailureThreshold consecutive failed queries the circuit opens and queries
+// fail immediately for OpenDuration (or the Retry-After of the last response,
+// if longer); then a single probe query decides whether it closes again.
+type CircuitBreakerConfig struct {
+	// Consecutive failed queries that open the circuit (default: 5, 0 disables).
+	FailureThreshold int `yaml:"failureThreshold"`
+	// How long the circuit stays open before a probe (default: 30s).
+	OpenDuration time.Duration `yaml:"openDuration"`
+}
+
+// PrometheusSourceConfig is a named Prometheus datasource.
+type PrometheusSourceConfig struct {
+	Name             string `yaml:"name"`
+	PrometheusConfig `yaml:",inline"`
+}
+
+// PrometheusList returns the configured Prometheus datasources: the named
+// prometheusSources when set, otherwise the single unnamed prometheus entry.
+func (d DatasourcesConfig) PrometheusList() []PrometheusSourceConfig {
+	if len(d.PrometheusSources) > 0 {
+		return d.PrometheusSources
+	}
+	return []PrometheusSourceConfig{{PrometheusConfig: d.Prometheus}}
 }
 
 // PrometheusConfig holds Prometheus/VictoriaMetrics connection settings.
 type PrometheusConfig struct {
-	URL      string `yaml:"url"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	URL              string `yaml:"url"`
+	Username         string `yaml:"username"`
+	Password         string `yaml:"password"`
+	HTTPClientConfig `yaml:",inline"`
+	// Tenant of a multi-tenant backend (VictoriaMetrics cluster, Mimir, Thanos).
+	Tenant TenantConfig `yaml:"tenant"`
+}
+
+// Tenant modes: how the tenant is passed to a multi-tenant backend.
+const (
+	TenantModeHeader = "header" // Mimir, Cortex, Thanos: tenant ID in a request header
+	TenantModePath   = "path"   // VictoriaMetrics cluster: <url>/select/<tenant>/prometheus
+)
+
+// TenantConfig selects the tenant of a multi-tenant metrics backend.
+type TenantConfig struct {
+	// "header" or "path" (see TenantModeHeader, TenantModePath); empty disables tenancy.
+	Mode string `yaml:"mode"`
+	// Default tenant, e.g. "0" or "0:1" for VictoriaMetrics, "team-a" for Mimir.
+	ID string `yaml:"id"`
+	// Header carrying the tenant in mode "header" (default: X-Scope-OrgID).
+	Header string `yaml:"header"`
+	// Further tenants that API requests may select with the tenant parameter.
+	Allowed []string `yaml:"allowed"`
+}
+
+// validate checks the tenant settings of the datasource at path.
+func (t TenantConfig) validate(path string) error {
+	switch t.Mode {
+	case "":
+		return nil
+	case TenantModeHeader, TenantModePath:
+	default:
+		return fmt.Errorf("%s.tenant.mode %q must be %q or %q", path, t.Mode, TenantModeHeader, TenantModePath)
+	}
+	for _, id := range append([]string{t.ID}, t.Allowed...) {
+		if id == "" || strings.ContainsAny(id, "/?#") {
+			return fmt.Errorf("%s.tenant: tenant IDs must be non-empty and must not contain '/', '?' or '#'", path)
+		}
+	}
+	return nil
+}
+
+// TenantAllowed reports whether API requests may select tenant, i.e. some
+// multi-tenant Prometheus datasource has it as its default or allowed tenant.
+func (d DatasourcesConfig) TenantAllowed(tenant string) bool {
+	for _, src := range d.PrometheusList() {
+		if src.Tenant.Mode != "" && (src.Tenant.ID == tenant || slices.Contains(src.Tenant.Allowed, tenant)) {
+			return true
+		}
+	}
+	return false
 }
 
 // AlertmanagerConfig holds AlertManager connection settings.
 type AlertmanagerConfig struct {
-	URL      string `yaml:"url"`
-	Username string `yaml:"username"`
-	Password string `yaml:"password"`
+	URL string `yaml:"url"`
+	// Several AlertManager endpoints (e.g. an HA pair plus regional
+	// instances), fetched concurrently with alerts deduplicated. When set,
+	// replaces url; username and password apply to every endpoint.
+	URLs     []string `yaml:"urls"`
+	Username string   `yaml:"username"`
+	Password string   `yaml:"password"`
+	// Authentication, TLS and proxy settings, applied to every endpoint.
+	HTTPClientConfig `yaml:",inline"`
+}
+
+// HTTPClientConfig holds authentication, TLS and proxy settings of an
+// outgoing HTTP client (datasources and Grafana).
+type HTTPClientConfig struct {
+	// Bearer token sent as "Authorization: Bearer <token>"; takes precedence
+	// over username/password.
+	BearerToken string `yaml:"bearerToken"`
+	// File holding the bearer token, re-read when it changes (e.g. a rotated
+	// service account token). Mutually exclusive with bearerToken.
+	BearerTokenFile string `yaml:"bearerTokenFile"`
+	// Extra headers sent with every request (e.g. a tenant ID).
+	Headers map[string]string `yaml:"headers"`
+	TLS     TLSConfig         `yaml:"tls"`
+	// HTTP proxy URL. When empty, HTTP_PROXY/HTTPS_PROXY/NO_PROXY apply.
+	ProxyURL string `yaml:"proxyUrl"`
+}
+
+// TLSConfig holds TLS settings of an outgoing HTTP client.
+type TLSConfig struct {
+	CAFile   string `yaml:"caFile"`   // PEM CA bundle used instead of the system roots
+	CertFile string `yaml:"certFile"` // PEM client certificate (requires keyFile)
+	KeyFile  string `yaml:"keyFile"`  // PEM client key (requires certFile)
+	// Skip verification of the server certificate. For testing only.
+	InsecureSkipVerify bool `yaml:"insecureSkipVerify"`
+}
+
+// validate checks the settings of the client configured at path.
+func (h HTTPClientConfig) validate(path string) error {
+	if h.BearerToken != "" && h.BearerTokenFile != "" {
+		return fmt.Errorf("%s: bearerToken and bearerTokenFile are mutually exclusive", path)
+	}
+	if (h.TLS.CertFile == "") != (h.TLS.KeyFile == "") {
+		return fmt.Errorf("%s.tls: certFile and keyFile must be set together", path)
+	}
+	if h.ProxyURL != "" {
+		u, err := url.Parse(h.ProxyURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s.proxyUrl %q must be an absolute URL", path, h.ProxyURL)
+		}
+	}
+	return nil
+}
+
+// Endpoints returns the configured AlertManager URLs, or nil when alert
+// enrichment is disabled.
+func (a AlertmanagerConfig) Endpoints() []string {
+	if len(a.URLs) > 0 {
+		return a.URLs
+	}
+	if a.URL != "" {
+		return []string{a.URL}
+	}
+	return nil
 }
 
 // CacheConfig holds cache settings.
 type CacheConfig struct {
 	TTL time.Duration `yaml:"ttl"`
+	// Interval between background rebuilds of the unfiltered topology.
+	// Zero means "use TTL".
+	RefreshInterval time.Duration `yaml:"refreshInterval"`
+}
+
+// SnapshotsConfig holds settings of the persistent topology snapshot store.
+// When enabled, the background-built topology is written to Dir every
+// Interval and historical requests are served from the nearest snapshot.
+type SnapshotsConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"`
+	// Snapshots older than Retention are deleted.
+	Retention time.Duration `yaml:"retention"`
+	// Downsampling thins out old snapshots: past After, at most one snapshot
+	// per Resolution is kept. The rule with the largest After that applies wins.
+	Downsampling []DownsampleRule `yaml:"downsampling"`
+}
+
+// DownsampleRule keeps one snapshot per Resolution for snapshots older than After.
+type DownsampleRule struct {
+	After      time.Duration `yaml:"after"`
+	Resolution time.Duration `yaml:"resolution"`
+}
+
+// NotificationsConfig holds webhook notification settings. Node and edge
+// state transitions detected between successive background builds are
+// batched and delivered to every configured webhook.
+type NotificationsConfig struct {
+	Webhooks []WebhookConfig `yaml:"webhooks"`
+	// Transitions detected within BatchWindow are sent in one request (default: 10s).
+	BatchWindow time.Duration `yaml:"batchWindow"`
+	// Maximum number of events per request (default: 100).
+	MaxBatchSize int `yaml:"maxBatchSize"`
+	// Delivery attempts per request, including the first one (default: 5).
+	MaxAttempts int `yaml:"maxAttempts"`
+	// Delay before the first retry; doubled after every failed attempt (default: 1s).
+	RetryBackoff time.Duration `yaml:"retryBackoff"`
+	// HTTP timeout of a single delivery attempt (default: 10s).
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// WebhookConfig defines a generic webhook receiving transition events as JSON.
+type WebhookConfig struct {
+	Name string `yaml:"name"`
+	URL  string `yaml:"url"`
+	// Secret enables HMAC-SHA256 signing of requests (X-Dephealth-Signature).
+	Secret string `yaml:"secret"`
+	// Additional HTTP headers, e.g. Authorization.
+	Headers map[string]string `yaml:"headers"`
+	// Event kinds delivered to this webhook; empty means all kinds.
+	Events []string `yaml:"events"`
 }
 
 // TopologyConfig holds topology graph settings.
@@ -75,6 +451,89 @@ type TopologyConfig struct {
 	// Uses last_over_time() to keep nodes visible after metrics disappear.
 	// Set to 0 to disable (default: show only current metrics).
 	Lookback time.Duration `yaml:"lookback"`
+	// Latency query and latency-based degradation settings.
+	Latency LatencyConfig `yaml:"latency"`
+	// Rules that mark healthy edges "degraded" when latency or error ratio
+	// breach a limit. Take precedence over latency.degradedThresholds.
+	Thresholds []ThresholdRule `yaml:"thresholds"`
+	// Flap detection for dependency edges.
+	Flapping FlappingConfig `yaml:"flapping"`
+	// Time budgets of the datasource queries of a build.
+	Queries QueriesConfig `yaml:"queries"`
+}
+
+// QueriesConfig holds the time budgets of the datasource queries of a
+// topology build. The queries of a build run concurrently, each with its own
+// timeout, and the whole build must finish within BuildTimeout.
+type QueriesConfig struct {
+	// Per-query timeout (default: 10s, 0 = none).
+	Timeout time.Duration `yaml:"timeout"`
+	// Per-query overrides of Timeout, keyed by query name (see TopologyQueryNames).
+	Timeouts map[string]time.Duration `yaml:"timeouts"`
+	// Deadline for the whole build across all sources (default: 20s, 0 = none).
+	BuildTimeout time.Duration `yaml:"buildTimeout"`
+}
+
+// QueryTimeout returns the timeout for the named query.
+func (q QueriesConfig) QueryTimeout(name string) time.Duration {
+	if d, ok := q.Timeouts[name]; ok {
+		return d
+	}
+	return q.Timeout
+}
+
+// FlappingConfig holds flap detection settings. An edge starts flapping when
+// it changes status at least Threshold times within Window, and stops once
+// the count within Window drops to ClearThreshold or below.
+type FlappingConfig struct {
+	// Sliding window for counting status transitions (default: 30m, 0 disables).
+	Window time.Duration `yaml:"window"`
+	// Transitions within Window that mark an edge as flapping (default: 6).
+	Threshold int `yaml:"threshold"`
+	// Transitions within Window at or below which flapping clears (default: 2).
+	ClearThreshold int `yaml:"clearThreshold"`
+}
+
+// ValidateThresholds checks the thresholds, which apply whenever a window is
+// set: in the config or by a request overriding it.
+func (f FlappingConfig) ValidateThresholds() error {
+	if f.Threshold < 2 {
+		return fmt.Errorf("topology.flapping.threshold must be at least 2 (got %d)", f.Threshold)
+	}
+	if f.ClearThreshold < 0 || f.ClearThreshold >= f.Threshold {
+		return fmt.Errorf("topology.flapping.clearThreshold must be between 0 and threshold-1 (got %d)", f.ClearThreshold)
+	}
+	return nil
+}
+
+// ThresholdRule defines degradation limits for a set of edges.
+// Every selector that is set must match; when several rules match, the most
+// specific one wins (dependency > service > namespace > type), ties go to the
+// first rule in the list. A rule without selectors matches all edges.
+type ThresholdRule struct {
+	Type       string `yaml:"type"`       // dependency type (postgres, redis, http, ...)
+	Namespace  string `yaml:"namespace"`  // namespace of the source service
+	Service    string `yaml:"service"`    // source service name
+	Dependency string `yaml:"dependency"` // dependency name; with service selects an exact edge
+	// Latency above which the edge is degraded (0 = no latency limit).
+	Latency time.Duration `yaml:"latency"`
+	// Percentile compared against Latency; defaults to latency.percentile.
+	Percentile string `yaml:"percentile"`
+	// Fraction of time (0-1) in a non-ok app_dependency_status above which
+	// the edge is degraded (0 = no error ratio limit).
+	ErrorRatio float64 `yaml:"errorRatio"`
+}
+
+// LatencyConfig holds edge latency settings.
+type LatencyConfig struct {
+	// Window for rate() in latency queries (default: 5m).
+	Window time.Duration `yaml:"window"`
+	// Percentile compared against DegradedThresholds: p50, p95 or p99 (default: p99).
+	Percentile string `yaml:"percentile"`
+	// Latency above which a healthy edge is marked "degraded", keyed by
+	// dependency type (postgres, redis, http, ...). The "default" key applies
+	// to types without an explicit entry. Empty disables latency degradation.
+	DegradedThresholds map[string]time.Duration `yaml:"degradedThresholds"`
 }
 
 // AuthConfig holds authentication settings.
@@ -110,6 +569,8 @@ type GrafanaConfig struct {
 	Username   string           `yaml:"username"` // Basic auth
 	Password   string           `yaml:"password"` // Basic auth
 	Dashboards DashboardsConfig `yaml:"dashboards"`
+	// TLS, proxy and header settings; bearerToken(File) may replace token.
+	HTTPClientConfig `yaml:",inline"`
 }
 
 // DashboardsConfig holds Grafana dashboard UIDs.
@@ -147,18 +608,166 @@ func Load(path string) (*Config, error) {
 
 // Validate checks that all required configuration fields are set.
 func (c *Config) Validate() error {
-	if c.Datasources.Prometheus.URL == "" {
+	if len(c.Datasources.PrometheusSources) == 0 && c.Datasources.Prometheus.URL == "" {
 		return fmt.Errorf("datasources.prometheus.url is required")
 	}
+	for i, u := range c.Datasources.Alertmanager.URLs {
+		if u == "" {
+			return fmt.Errorf("datasources.alertmanager.urls[%d] must not be empty", i)
+		}
+	}
+	if err := c.Datasources.Prometheus.validate("datasources.prometheus"); err != nil {
+		return err
+	}
+	if err := c.Datasources.Prometheus.Tenant.validate("datasources.prometheus"); err != nil {
+		return err
+	}
+	if err := c.Datasources.Alertmanager.validate("datasources.alertmanager"); err != nil {
+		return err
+	}
+	if err := c.Grafana.validate("grafana"); err != nil {
+		return err
+	}
+	if c.Grafana.Token != "" && (c.Grafana.BearerToken != "" || c.Grafana.BearerTokenFile != "") {
+		return fmt.Errorf("grafana: token is mutually exclusive with bearerToken and bearerTokenFile")
+	}
+	if r := c.Datasources.Resilience.Retry; r.MaxAttempts < 0 || r.Backoff < 0 || r.MaxBackoff < 0 || (r.MaxBackoff > 0 && r.MaxBackoff < r.Backoff) {
+		return fmt.Errorf("datasources.resilience.retry: maxAttempts and backoffs must not be negative and maxBackoff must not be below backoff")
+	}
+	if cb := c.Datasources.Resilience.CircuitBreaker; cb.FailureThreshold < 0 || (cb.FailureThreshold > 0 && cb.OpenDuration <= 0) {
+		return fmt.Errorf("datasources.resilience.circuitBreaker: failureThreshold must not be negative and openDuration must be positive")
+	}
+	sourceNames := make(map[string]bool, len(c.Datasources.PrometheusSources))
+	for i, src := range c.Datasources.PrometheusSources {
+		if src.Name == "" {
+			return fmt.Errorf("datasources.prometheusSources[%d].name is required", i)
+		}
+		if strings.Contains(src.Name, "/") {
+			return fmt.Errorf("datasources.prometheusSources[%d].name %q must not contain '/'", i, src.Name)
+		}
+		if sourceNames[src.Name] {
+			return fmt.Errorf("datasources.prometheusSources[%d].name %q is duplicated", i, src.Name)
+		}
+		sourceNames[src.Name] = true
+		if src.URL == "" {
+			return fmt.Errorf("datasources.prometheusSources[%d].url is required", i)
+		}
+		if err := src.validate(fmt.Sprintf("datasources.prometheusSources[%d]", i)); err != nil {
+			return err
+		}
+		if err := src.Tenant.validate(fmt.Sprintf("datasources.prometheusSources[%d]", i)); err != nil {
+			return err
+		}
+	}
 	if c.Server.Listen == "" {
 		return fmt.Errorf("server.listen is required")
 	}
+	if c.Cache.RefreshInterval < 0 {
+		return fmt.Errorf("cache.refreshInterval must not be negative")
+	}
+	if c.Snapshots.Enabled {
+		if c.Snapshots.Dir == "" {
+			return fmt.Errorf("snapshots.dir is required when snapshots are enabled")
+		}
+		if c.Snapshots.Interval <= 0 {
+			return fmt.Errorf("snapshots.interval must be positive")
+		}
+		if c.Snapshots.Retention <= 0 {
+			return fmt.Errorf("snapshots.retention must be positive")
+		}
+		for i, r := range c.Snapshots.Downsampling {
+			if r.After <= 0 || r.Resolution <= 0 {
+				return fmt.Errorf("snapshots.downsampling[%d]: after and resolution must be positive", i)
+			}
+		}
+	}
 	if c.Topology.Lookback < 0 {
 		return fmt.Errorf("topology.lookback must not be negative")
 	}
 	if c.Topology.Lookback > 0 && c.Topology.Lookback < time.Minute {
 		return fmt.Errorf("topology.lookback must be at least 1m (got %s)", c.Topology.Lookback)
 	}
+	if c.Topology.Latency.Window < 0 {
+		return fmt.Errorf("topology.latency.window must not be negative")
+	}
+	if c.Topology.Latency.Window > 0 && c.Topology.Latency.Window < time.Second {
+		return fmt.Errorf("topology.latency.window must be at least 1s (got %s)", c.Topology.Latency.Window)
+	}
+	switch c.Topology.Latency.Percentile {
+	case "p50", "p95", "p99", "":
+	default:
+		return fmt.Errorf("topology.latency.percentile %q is invalid (expected p50/p95/p99)", c.Topology.Latency.Percentile)
+	}
+	for typ, d := range c.Topology.Latency.DegradedThresholds {
+		if d < 0 {
+			return fmt.Errorf("topology.latency.degradedThresholds[%s] must not be negative", typ)
+		}
+	}
+	for i, r := range c.Topology.Thresholds {
+		if r.Latency < 0 {
+			return fmt.Errorf("topology.thresholds[%d].latency must not be negative", i)
+		}
+		if r.ErrorRatio < 0 || r.ErrorRatio > 1 {
+			return fmt.Errorf("topology.thresholds[%d].errorRatio must be between 0 and 1 (got %g)", i, r.ErrorRatio)
+		}
+		if r.Latency == 0 && r.ErrorRatio == 0 {
+			return fmt.Errorf("topology.thresholds[%d] must set latency or errorRatio", i)
+		}
+		switch r.Percentile {
+		case "p50", "p95", "p99", "":
+		default:
+			return fmt.Errorf("topology.thresholds[%d].percentile %q is invalid (expected p50/p95/p99)", i, r.Percentile)
+		}
+		if r.Dependency != "" && r.Service == "" {
+			return fmt.Errorf("topology.thresholds[%d].dependency requires service", i)
+		}
+	}
+	if len(c.Notifications.Webhooks) > 0 {
+		n := c.Notifications
+		if n.BatchWindow < 0 || n.RetryBackoff < 0 || n.Timeout <= 0 {
+			return fmt.Errorf("notifications.batchWindow and retryBackoff must not be negative, timeout must be positive")
+		}
+		if n.MaxBatchSize < 1 || n.MaxAttempts < 1 {
+			return fmt.Errorf("notifications.maxBatchSize and maxAttempts must be at least 1")
+		}
+		names := make(map[string]bool)
+		for i, wh := range n.Webhooks {
+			if wh.Name == "" {
+				return fmt.Errorf("notifications.webhooks[%d].name is required", i)
+			}
+			if names[wh.Name] {
+				return fmt.Errorf("notifications.webhooks[%d].name %q is duplicated", i, wh.Name)
+			}
+			names[wh.Name] = true
+			if u, err := url.Parse(wh.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("notifications.webhooks[%d].url %q must be an http(s) URL", i, wh.URL)
+			}
+			for _, ev := range wh.Events {
+				if !slices.Contains(NotificationEventKinds, ev) {
+					return fmt.Errorf("notifications.webhooks[%d].events: unknown event kind %q", i, ev)
+				}
+			}
+		}
+	}
+	if q := c.Topology.Queries; q.Timeout < 0 || q.BuildTimeout < 0 {
+		return fmt.Errorf("topology.queries.timeout and buildTimeout must not be negative")
+	}
+	for name, d := range c.Topology.Queries.Timeouts {
+		if !slices.Contains(TopologyQueryNames, name) {
+			return fmt.Errorf("topology.queries.timeouts: unknown query %q", name)
+		}
+		if d < 0 {
+			return fmt.Errorf("topology.queries.timeouts.%s must not be negative", name)
+		}
+	}
+	if f := c.Topology.Flapping; f.Window != 0 {
+		if f.Window < time.Minute || f.Window > 12*time.Hour {
+			return fmt.Errorf("topology.flapping.window must be between 1m and 12h (got %s)", f.Window)
+		}
+		if err := f.ValidateThresholds(); err != nil {
+			return err
+		}
+	}
 
 	switch c.Auth.Type {
 	case "none", "":
@@ -211,6 +820,19 @@ func (c *Config) Validate() error {
 	if len(c.Alerts.SeverityLevels) == 0 {
 		return fmt.Errorf("alerts.severityLevels must not be empty")
 	}
+	for i, r := range c.Alerts.Mapping.StateRules {
+		if r.AlertName == "" && len(r.Matchers) == 0 {
+			return fmt.Errorf("alerts.mapping.stateRules[%d] must set alertName or matchers", i)
+		}
+		switch r.State {
+		case "down", "degraded":
+		default:
+			return fmt.Errorf("alerts.mapping.stateRules[%d].state %q is invalid (expected down/degraded)", i, r.State)
+		}
+	}
+	if c.Alerts.Silences.MaxDuration < 0 {
+		return fmt.Errorf("alerts.silences.maxDuration must not be negative")
+	}
 	for i, level := range c.Alerts.SeverityLevels {
 		if level.Value == "" {
 			return fmt.Errorf("alerts.severityLevels[%d].value is required", i)
@@ -222,6 +844,9 @@ func (c *Config) Validate() error {
 			return fmt.Errorf("alerts.severityLevels[%d].color %q is not a valid hex color (#RRGGBB)", i, level.Color)
 		}
 	}
+	if err := c.Metrics.validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -234,6 +859,45 @@ func defaultConfig() *Config {
 		Cache: CacheConfig{
 			TTL: 15 * time.Second,
 		},
+		Notifications: NotificationsConfig{
+			BatchWindow:  10 * time.Second,
+			MaxBatchSize: 100,
+			MaxAttempts:  5,
+			RetryBackoff: time.Second,
+			Timeout:      10 * time.Second,
+		},
+		Datasources: DatasourcesConfig{
+			Resilience: ResilienceConfig{
+				Retry: RetryConfig{
+					MaxAttempts: 3,
+					Backoff:     200 * time.Millisecond,
+					MaxBackoff:  2 * time.Second,
+				},
+				CircuitBreaker: CircuitBreakerConfig{
+					FailureThreshold: 5,
+					OpenDuration:     30 * time.Second,
+				},
+			},
+		},
+		Snapshots: SnapshotsConfig{
+			Interval:  time.Minute,
+			Retention: 30 * 24 * time.Hour,
+		},
+		Topology: TopologyConfig{
+			Latency: LatencyConfig{
+				Window:     5 * time.Minute,
+				Percentile: "p99",
+			},
+			Flapping: FlappingConfig{
+				Window:         30 * time.Minute,
+				Threshold:      6,
+				ClearThreshold: 2,
+			},
+			Queries: QueriesConfig{
+				Timeout:      10 * time.Second,
+				BuildTimeout: 20 * time.Second,
+			},
+		},
 		Auth: AuthConfig{
 			Type: "none",
 		},
@@ -244,7 +908,19 @@ func defaultConfig() *Config {
 				{Value: "warning", Color: "#ff9800"},
 				{Value: "info", Color: "#2196f3"},
 			},
+			Mapping: AlertMappingConfig{
+				ServiceLabels:    []string{"job"},
+				DependencyLabels: []string{"dependency"},
+				StateRules: []AlertStateRule{
+					{AlertName: "DependencyDown", State: "down"},
+					{AlertName: "DependencyDegraded", State: "degraded"},
+				},
+			},
+			Silences: SilencesConfig{
+				MaxDuration: 7 * 24 * time.Hour,
+			},
 		},
+		Metrics: MetricsConfig{}.WithDefaults(),
 		Log: logging.LogConfig{
 			Format:     "json",
 			Level:      "info",
@@ -260,9 +936,24 @@ func applyEnvOverrides(cfg *Config) {
 	if v := os.Getenv("DEPHEALTH_DATASOURCES_PROMETHEUS_URL"); v != "" {
 		cfg.Datasources.Prometheus.URL = v
 	}
+	if v := os.Getenv("DEPHEALTH_DATASOURCES_PROMETHEUS_BEARERTOKEN"); v != "" {
+		cfg.Datasources.Prometheus.BearerToken = v
+	}
 	if v := os.Getenv("DEPHEALTH_DATASOURCES_ALERTMANAGER_URL"); v != "" {
 		cfg.Datasources.Alertmanager.URL = v
 	}
+	if v := os.Getenv("DEPHEALTH_DATASOURCES_ALERTMANAGER_URLS"); v != "" {
+		var urls []string
+		for _, u := range strings.Split(v, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				urls = append(urls, u)
+			}
+		}
+		cfg.Datasources.Alertmanager.URLs = urls
+	}
+	if v := os.Getenv("DEPHEALTH_DATASOURCES_ALERTMANAGER_BEARERTOKEN"); v != "" {
+		cfg.Datasources.Alertmanager.BearerToken = v
+	}
 	if v := os.Getenv("DEPHEALTH_CACHE_TTL"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			cfg.Cache.TTL = d
@@ -270,6 +961,19 @@ func applyEnvOverrides(cfg *Config) {
 			slog.Warn("ignoring invalid DEPHEALTH_CACHE_TTL", "value", v, "error", err)
 		}
 	}
+	if v := os.Getenv("DEPHEALTH_CACHE_REFRESHINTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Cache.RefreshInterval = d
+		} else {
+			slog.Warn("ignoring invalid DEPHEALTH_CACHE_REFRESHINTERVAL", "value", v, "error", err)
+		}
+	}
+	if v := os.Getenv("DEPHEALTH_SNAPSHOTS_ENABLED"); v != "" {
+		cfg.Snapshots.Enabled = strings.EqualFold(v, "true") || v == "1"
+	}
+	if v := os.Getenv("DEPHEALTH_SNAPSHOTS_DIR"); v != "" {
+		cfg.Snapshots.Dir = v
+	}
 	if v := os.Getenv("DEPHEALTH_TOPOLOGY_LOOKBACK"); v != "" {
 		if d, err := time.ParseDuration(v); err == nil {
 			cfg.Topology.Lookback = d
@@ -277,6 +981,37 @@ func applyEnvOverrides(cfg *Config) {
 			slog.Warn("ignoring invalid DEPHEALTH_TOPOLOGY_LOOKBACK", "value", v, "error", err)
 		}
 	}
+	if v := os.Getenv("DEPHEALTH_TOPOLOGY_LATENCY_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Topology.Latency.Window = d
+		} else {
+			slog.Warn("ignoring invalid DEPHEALTH_TOPOLOGY_LATENCY_WINDOW", "value", v, "error", err)
+		}
+	}
+	if v := os.Getenv("DEPHEALTH_TOPOLOGY_LATENCY_PERCENTILE"); v != "" {
+		cfg.Topology.Latency.Percentile = strings.ToLower(v)
+	}
+	if v := os.Getenv("DEPHEALTH_TOPOLOGY_FLAPPING_WINDOW"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Topology.Flapping.Window = d
+		} else {
+			slog.Warn("ignoring invalid DEPHEALTH_TOPOLOGY_FLAPPING_WINDOW", "value", v, "error", err)
+		}
+	}
+	if v := os.Getenv("DEPHEALTH_TOPOLOGY_QUERIES_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Topology.Queries.Timeout = d
+		} else {
+			slog.Warn("ignoring invalid DEPHEALTH_TOPOLOGY_QUERIES_TIMEOUT", "value", v, "error", err)
+		}
+	}
+	if v := os.Getenv("DEPHEALTH_TOPOLOGY_QUERIES_BUILDTIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Topology.Queries.BuildTimeout = d
+		} else {
+			slog.Warn("ignoring invalid DEPHEALTH_TOPOLOGY_QUERIES_BUILDTIMEOUT", "value", v, "error", err)
+		}
+	}
 	if v := os.Getenv("DEPHEALTH_AUTH_TYPE"); v != "" {
 		cfg.Auth.Type = v
 	}
@@ -107,14 +107,36 @@ func TestLoadDefaults(t *testing.T) {
 	if cfg.Topology.Lookback != 0 {
 		t.Errorf("default Topology.Lookback = %v, want 0", cfg.Topology.Lookback)
 	}
+	if cfg.Topology.Latency.Window != 5*time.Minute {
+		t.Errorf("default Topology.Latency.Window = %v, want %v", cfg.Topology.Latency.Window, 5*time.Minute)
+	}
+	if f := cfg.Topology.Flapping; f.Window != 30*time.Minute || f.Threshold != 6 || f.ClearThreshold != 2 {
+		t.Errorf("default Topology.Flapping = %+v, want 30m/6/2", f)
+	}
+	if q := cfg.Topology.Queries; q.Timeout != 10*time.Second || q.BuildTimeout != 20*time.Second {
+		t.Errorf("default Topology.Queries = %+v, want 10s/20s", q)
+	}
+	if r := cfg.Datasources.Resilience; r.Retry.MaxAttempts != 3 || r.Retry.Backoff != 200*time.Millisecond || r.Retry.MaxBackoff != 2*time.Second ||
+		r.CircuitBreaker.FailureThreshold != 5 || r.CircuitBreaker.OpenDuration != 30*time.Second {
+		t.Errorf("default Datasources.Resilience = %+v, want 3/200ms/2s and 5/30s", r)
+	}
+	if m := cfg.Metrics; m.Names.Health != "app_dependency_health" || m.Labels.IsEntry != "isentry" || len(m.Values.Critical) != 1 || m.Values.Critical[0] != "yes" {
+		t.Errorf("default Metrics = %+v, want the dephealth SDK names", m)
+	}
+	if s := cfg.Alerts.Silences; s.AllowAnonymous || s.MaxDuration != 7*24*time.Hour {
+		t.Errorf("default Alerts.Silences = %+v, want authenticated only and 168h", s)
+	}
 }
 
 func TestLoadEnvOverrides(t *testing.T) {
 	t.Setenv("DEPHEALTH_SERVER_LISTEN", ":3000")
 	t.Setenv("DEPHEALTH_DATASOURCES_PROMETHEUS_URL", "http://env-vm:8428")
 	t.Setenv("DEPHEALTH_DATASOURCES_ALERTMANAGER_URL", "http://env-am:9093")
+	t.Setenv("DEPHEALTH_DATASOURCES_ALERTMANAGER_URLS", "http://am-1:9093, http://am-2:9093")
 	t.Setenv("DEPHEALTH_CACHE_TTL", "45s")
+	t.Setenv("DEPHEALTH_CACHE_REFRESHINTERVAL", "30s")
 	t.Setenv("DEPHEALTH_TOPOLOGY_LOOKBACK", "2h")
+	t.Setenv("DEPHEALTH_TOPOLOGY_LATENCY_WINDOW", "10m")
 	t.Setenv("DEPHEALTH_AUTH_TYPE", "oidc")
 	t.Setenv("DEPHEALTH_GRAFANA_BASEURL", "https://env-grafana.example.com")
 
@@ -132,12 +154,21 @@ func TestLoadEnvOverrides(t *testing.T) {
 	if cfg.Datasources.Alertmanager.URL != "http://env-am:9093" {
 		t.Errorf("Alertmanager.URL = %q, want %q", cfg.Datasources.Alertmanager.URL, "http://env-am:9093")
 	}
+	if got := cfg.Datasources.Alertmanager.Endpoints(); len(got) != 2 || got[1] != "http://am-2:9093" {
+		t.Errorf("Alertmanager.Endpoints() = %v, want [http://am-1:9093 http://am-2:9093]", got)
+	}
 	if cfg.Cache.TTL != 45*time.Second {
 		t.Errorf("Cache.TTL = %v, want %v", cfg.Cache.TTL, 45*time.Second)
 	}
+	if cfg.Cache.RefreshInterval != 30*time.Second {
+		t.Errorf("Cache.RefreshInterval = %v, want %v", cfg.Cache.RefreshInterval, 30*time.Second)
+	}
 	if cfg.Topology.Lookback != 2*time.Hour {
 		t.Errorf("Topology.Lookback = %v, want %v", cfg.Topology.Lookback, 2*time.Hour)
 	}
+	if cfg.Topology.Latency.Window != 10*time.Minute {
+		t.Errorf("Topology.Latency.Window = %v, want %v", cfg.Topology.Latency.Window, 10*time.Minute)
+	}
 	if cfg.Auth.Type != "oidc" {
 		t.Errorf("Auth.Type = %q, want %q", cfg.Auth.Type, "oidc")
 	}
@@ -176,6 +207,19 @@ datasources:
     url: "http://vm:8428"
 topology:
   lookback: 1h
+  latency:
+    window: 2m
+    percentile: p95
+    degradedThresholds:
+      default: 500ms
+      redis: 20ms
+  thresholds:
+    - namespace: prod
+      latency: 300ms
+      percentile: p95
+    - service: order-service
+      dependency: postgres-main
+      errorRatio: 0.05
 `
 	dir := t.TempDir()
 	path := filepath.Join(dir, "config.yaml")
@@ -191,6 +235,80 @@ topology:
 	if cfg.Topology.Lookback != time.Hour {
 		t.Errorf("Topology.Lookback = %v, want %v", cfg.Topology.Lookback, time.Hour)
 	}
+	if cfg.Topology.Latency.Window != 2*time.Minute {
+		t.Errorf("Topology.Latency.Window = %v, want %v", cfg.Topology.Latency.Window, 2*time.Minute)
+	}
+	if cfg.Topology.Latency.Percentile != "p95" {
+		t.Errorf("Topology.Latency.Percentile = %q, want %q", cfg.Topology.Latency.Percentile, "p95")
+	}
+	if got := cfg.Topology.Latency.DegradedThresholds["redis"]; got != 20*time.Millisecond {
+		t.Errorf("DegradedThresholds[redis] = %v, want %v", got, 20*time.Millisecond)
+	}
+	if len(cfg.Topology.Thresholds) != 2 {
+		t.Fatalf("len(Topology.Thresholds) = %d, want 2", len(cfg.Topology.Thresholds))
+	}
+	if r := cfg.Topology.Thresholds[1]; r.Service != "order-service" || r.Dependency != "postgres-main" || r.ErrorRatio != 0.05 {
+		t.Errorf("Topology.Thresholds[1] = %+v", r)
+	}
+}
+
+func TestLoadPrometheusSourcesFromYAML(t *testing.T) {
+	content := `
+server:
+  listen: ":8080"
+datasources:
+  prometheusSources:
+    - name: eu
+      url: "http://vm-eu:8428"
+    - name: us
+      url: "http://vm-us:8428"
+      username: "reader"
+      password: "secret"
+      bearerTokenFile: /var/run/secrets/vmauth/token
+      headers:
+        X-Scope-OrgID: team-a
+      tls:
+        caFile: /etc/ssl/vm-ca.pem
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+
+	sources := cfg.Datasources.PrometheusList()
+	if len(sources) != 2 {
+		t.Fatalf("len(PrometheusList()) = %d, want 2", len(sources))
+	}
+	if s := sources[1]; s.Name != "us" || s.URL != "http://vm-us:8428" || s.Username != "reader" {
+		t.Errorf("PrometheusList()[1] = %+v", s)
+	}
+	if h := sources[1].HTTPClientConfig; h.BearerTokenFile != "/var/run/secrets/vmauth/token" || h.Headers["X-Scope-OrgID"] != "team-a" || h.TLS.CAFile != "/etc/ssl/vm-ca.pem" {
+		t.Errorf("PrometheusList()[1].HTTPClientConfig = %+v", h)
+	}
+
+	single := DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}}
+	if got := single.PrometheusList(); len(got) != 1 || got[0].Name != "" || got[0].URL != "http://vm:8428" {
+		t.Errorf("PrometheusList() without sources = %+v, want the single unnamed prometheus", got)
+	}
+}
+
+func TestTenantAllowed(t *testing.T) {
+	d := DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{
+		{Name: "vm", PrometheusConfig: PrometheusConfig{Tenant: TenantConfig{Mode: TenantModePath, ID: "0", Allowed: []string{"1"}}}},
+		{Name: "mimir", PrometheusConfig: PrometheusConfig{Tenant: TenantConfig{Mode: TenantModeHeader, ID: "team-a"}}},
+		{Name: "plain", PrometheusConfig: PrometheusConfig{Tenant: TenantConfig{ID: "ignored"}}},
+	}}
+	for tenant, want := range map[string]bool{"0": true, "1": true, "team-a": true, "ignored": false, "2": false} {
+		if got := d.TenantAllowed(tenant); got != want {
+			t.Errorf("TenantAllowed(%q) = %v, want %v", tenant, got, want)
+		}
+	}
 }
 
 func TestValidate(t *testing.T) {
@@ -208,6 +326,225 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "negative refresh interval",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Cache:       CacheConfig{RefreshInterval: -time.Second},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "snapshots enabled without dir",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Snapshots:   SnapshotsConfig{Enabled: true, Interval: time.Minute, Retention: time.Hour},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "snapshot downsampling without resolution",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Snapshots: SnapshotsConfig{Enabled: true, Dir: "/tmp/snapshots", Interval: time.Minute, Retention: time.Hour,
+					Downsampling: []DownsampleRule{{After: time.Hour}}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "flapping clear threshold not below threshold",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Flapping: FlappingConfig{Window: 30 * time.Minute, Threshold: 4, ClearThreshold: 4}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown query timeout",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Queries: QueriesConfig{Timeouts: map[string]time.Duration{"latency": time.Second}}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "bearer token and token file together",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{
+					URL:              "http://vm:8428",
+					HTTPClientConfig: HTTPClientConfig{BearerToken: "x", BearerTokenFile: "/token"},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alertmanager client cert without key",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{
+					Prometheus:   PrometheusConfig{URL: "http://vm:8428"},
+					Alertmanager: AlertmanagerConfig{URL: "http://am:9093", HTTPClientConfig: HTTPClientConfig{TLS: TLSConfig{CertFile: "/cert.pem"}}},
+				},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "prometheus source proxy without scheme",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{{
+					Name:             "eu",
+					PrometheusConfig: PrometheusConfig{URL: "http://vm:8428", HTTPClientConfig: HTTPClientConfig{ProxyURL: "proxy:3128"}},
+				}}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "grafana token with bearer token file",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Grafana:     GrafanaConfig{Token: "x", HTTPClientConfig: HTTPClientConfig{BearerTokenFile: "/token"}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown tenant mode",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{
+					URL:    "http://vm:8428",
+					Tenant: TenantConfig{Mode: "query", ID: "0"},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "tenant mode without id",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{{
+					Name:             "eu",
+					PrometheusConfig: PrometheusConfig{URL: "http://vmselect:8481", Tenant: TenantConfig{Mode: TenantModePath}},
+				}}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative silence max duration",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Alerts: func() AlertsConfig {
+					a := validAlerts()
+					a.Silences.MaxDuration = -time.Hour
+					return a
+				}(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid metric label name",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Metrics:     MetricsConfig{Labels: MetricLabelsConfig{Name: "service-name"}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "malformed metric matcher",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Metrics:     MetricsConfig{Matchers: []string{`env="prod"}) or vector(1`}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid metric matchers",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Metrics:     MetricsConfig{Matchers: []string{`env="prod"`, `cluster=~"eu-.*"`}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "retry max backoff below backoff",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{
+					Prometheus: PrometheusConfig{URL: "http://vm:8428"},
+					Resilience: ResilienceConfig{Retry: RetryConfig{MaxAttempts: 3, Backoff: time.Second, MaxBackoff: 100 * time.Millisecond}},
+				},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "circuit breaker without open duration",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{
+					Prometheus: PrometheusConfig{URL: "http://vm:8428"},
+					Resilience: ResilienceConfig{CircuitBreaker: CircuitBreakerConfig{FailureThreshold: 5}},
+				},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "flapping window too short",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Flapping: FlappingConfig{Window: 30 * time.Second, Threshold: 4, ClearThreshold: 1}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "webhook without url scheme",
+			cfg: Config{
+				Server:        ServerConfig{Listen: ":8080"},
+				Datasources:   DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Notifications: NotificationsConfig{Webhooks: []WebhookConfig{{Name: "ops", URL: "hooks.example.com/x"}}, MaxBatchSize: 1, MaxAttempts: 1, Timeout: time.Second},
+				Alerts:        validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "webhook with unknown event kind",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Notifications: NotificationsConfig{Webhooks: []WebhookConfig{{Name: "ops", URL: "https://hooks.example.com/x", Events: []string{"edge_flap"}}},
+					MaxBatchSize: 1, MaxAttempts: 1, Timeout: time.Second},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing prometheus url",
 			cfg: Config{
@@ -215,6 +552,64 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "prometheus sources without primary url",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{
+					{Name: "eu", PrometheusConfig: PrometheusConfig{URL: "http://vm-eu:8428"}},
+					{Name: "us", PrometheusConfig: PrometheusConfig{URL: "http://vm-us:8428"}},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "prometheus source missing name",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{
+					{PrometheusConfig: PrometheusConfig{URL: "http://vm-eu:8428"}},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "prometheus source duplicate name",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{
+					{Name: "eu", PrometheusConfig: PrometheusConfig{URL: "http://vm-eu:8428"}},
+					{Name: "eu", PrometheusConfig: PrometheusConfig{URL: "http://vm-us:8428"}},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "prometheus source missing url",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{PrometheusSources: []PrometheusSourceConfig{
+					{Name: "eu"},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alertmanager urls with empty entry",
+			cfg: Config{
+				Server: ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{
+					Prometheus:   PrometheusConfig{URL: "http://vm:8428"},
+					Alertmanager: AlertmanagerConfig{URLs: []string{"http://am-1:9093", ""}},
+				},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing listen address",
 			cfg: Config{
@@ -393,6 +788,70 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "topology latency percentile invalid",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Latency: LatencyConfig{Percentile: "p90"}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "topology threshold rule valid",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology: TopologyConfig{Thresholds: []ThresholdRule{
+					{Type: "postgres", Latency: 200 * time.Millisecond, ErrorRatio: 0.1},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: false,
+		},
+		{
+			name: "topology threshold rule without limits",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Thresholds: []ThresholdRule{{Type: "postgres"}}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "topology threshold error ratio out of range",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Thresholds: []ThresholdRule{{Type: "postgres", ErrorRatio: 5}}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "topology threshold dependency without service",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology:    TopologyConfig{Thresholds: []ThresholdRule{{Dependency: "pg", Latency: time.Second}}},
+				Alerts:      validAlerts(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "topology latency threshold negative",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Topology: TopologyConfig{Latency: LatencyConfig{
+					DegradedThresholds: map[string]time.Duration{"redis": -time.Millisecond},
+				}},
+				Alerts: validAlerts(),
+			},
+			wantErr: true,
+		},
 		// Alerts validation test cases.
 		{
 			name: "alerts severity levels empty",
@@ -439,6 +898,32 @@ func TestValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "alert state rule with invalid state",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Alerts: func() AlertsConfig {
+					a := validAlerts()
+					a.Mapping.StateRules = []AlertStateRule{{AlertName: "DependencyDown", State: "critical"}}
+					return a
+				}(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "alert state rule without alertName or matchers",
+			cfg: Config{
+				Server:      ServerConfig{Listen: ":8080"},
+				Datasources: DatasourcesConfig{Prometheus: PrometheusConfig{URL: "http://vm:8428"}},
+				Alerts: func() AlertsConfig {
+					a := validAlerts()
+					a.Mapping.StateRules = []AlertStateRule{{State: "down"}}
+					return a
+				}(),
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -626,6 +1111,52 @@ alerts:
 	}
 }
 
+func TestLoadAlertMappingFromYAML(t *testing.T) {
+	content := `
+datasources:
+  prometheus:
+    url: "http://vm:8428"
+alerts:
+  mapping:
+    dependencyLabels: ["target", "dependency"]
+    endpointLabels: ["endpoint"]
+    stateRules:
+      - alertName: "UpstreamUnavailable"
+        state: "down"
+      - matchers:
+          impact: "partial"
+        state: "degraded"
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	m := cfg.Alerts.Mapping
+	// Unset label lists keep their defaults.
+	if len(m.ServiceLabels) != 1 || m.ServiceLabels[0] != "job" {
+		t.Errorf("Mapping.ServiceLabels = %v, want [job]", m.ServiceLabels)
+	}
+	if len(m.DependencyLabels) != 2 || m.DependencyLabels[0] != "target" {
+		t.Errorf("Mapping.DependencyLabels = %v, want [target dependency]", m.DependencyLabels)
+	}
+	if len(m.StateRules) != 2 {
+		t.Fatalf("got %d state rules, want 2", len(m.StateRules))
+	}
+	if m.StateRules[1].Matchers["impact"] != "partial" || m.StateRules[1].State != "degraded" {
+		t.Errorf("StateRules[1] = %+v, want impact=partial → degraded", m.StateRules[1])
+	}
+}
+
 func TestAlertsEnvOverrides(t *testing.T) {
 	t.Setenv("DEPHEALTH_ALERTS_SEVERITYLABEL", "level")
 	t.Setenv("DEPHEALTH_ALERTS_SEVERITYLEVELS", `[{"value":"high","color":"#ff0000"},{"value":"low","color":"#00ff00"}]`)
@@ -648,3 +1179,78 @@ func TestAlertsEnvOverrides(t *testing.T) {
 		t.Errorf("SeverityLevels[1].Color = %q, want %q", cfg.Alerts.SeverityLevels[1].Color, "#00ff00")
 	}
 }
+
+func TestLoadSnapshotsFromYAML(t *testing.T) {
+	content := `
+datasources:
+  prometheus:
+    url: "http://vm:8428"
+snapshots:
+  enabled: true
+  dir: "/var/lib/dephealth-ui/snapshots"
+  retention: 2160h
+  downsampling:
+    - after: 24h
+      resolution: 15m
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error: %v", err)
+	}
+
+	sn := cfg.Snapshots
+	if !sn.Enabled || sn.Dir != "/var/lib/dephealth-ui/snapshots" {
+		t.Errorf("Snapshots = %+v, want enabled with dir", sn)
+	}
+	// Interval keeps its default.
+	if sn.Interval != time.Minute {
+		t.Errorf("Snapshots.Interval = %v, want 1m", sn.Interval)
+	}
+	if sn.Retention != 90*24*time.Hour {
+		t.Errorf("Snapshots.Retention = %v, want 2160h", sn.Retention)
+	}
+	if len(sn.Downsampling) != 1 || sn.Downsampling[0].Resolution != 15*time.Minute {
+		t.Errorf("Snapshots.Downsampling = %+v, want one 15m rule", sn.Downsampling)
+	}
+}
+
+func TestLoadNotificationsFromYAML(t *testing.T) {
+	content := `
+datasources:
+  prometheus:
+    url: "http://vm:8428"
+notifications:
+  batchWindow: 30s
+  webhooks:
+    - name: ops
+      url: "https://hooks.example.com/dephealth"
+      secret: "s3cret"
+      events: [edge_state, root_cause]
+`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	n := cfg.Notifications
+	if n.BatchWindow != 30*time.Second || n.MaxAttempts != 5 || n.MaxBatchSize != 100 {
+		t.Errorf("notifications = %+v, want batchWindow 30s and default retry settings", n)
+	}
+	if len(n.Webhooks) != 1 || n.Webhooks[0].Secret != "s3cret" || len(n.Webhooks[0].Events) != 2 {
+		t.Errorf("webhooks = %+v", n.Webhooks)
+	}
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+)
+
+// AvailabilityExportData is the export structure for an availability report.
+type AvailabilityExportData struct {
+	Version   string                      `json:"version"`
+	Timestamp string                      `json:"timestamp"`
+	From      string                      `json:"from"`
+	To        string                      `json:"to"`
+	Step      string                      `json:"step"`
+	Filters   map[string]string           `json:"filters"`
+	Services  []AvailabilityExportService `json:"services"`
+	Edges     []AvailabilityExportEdge    `json:"edges"`
+}
+
+// AvailabilityExportService is the availability of one service node.
+type AvailabilityExportService struct {
+	Service         string   `json:"service"`
+	Namespace       string   `json:"namespace"`
+	Dependencies    int      `json:"dependencies"`
+	AvailabilityPct float64  `json:"availability_pct"`
+	DowntimeSeconds float64  `json:"downtime_seconds"`
+	Outages         int      `json:"outages"`
+	MTTRSeconds     float64  `json:"mttr_seconds"`
+	MTBFSeconds     float64  `json:"mtbf_seconds"`
+	Score           *float64 `json:"score,omitempty"`
+}
+
+// AvailabilityExportEdge is the availability of one dependency edge.
+type AvailabilityExportEdge struct {
+	Service         string  `json:"service"`
+	Namespace       string  `json:"namespace"`
+	Dependency      string  `json:"dependency"`
+	Type            string  `json:"type"`
+	Host            string  `json:"host"`
+	Port            string  `json:"port"`
+	Critical        bool    `json:"critical"`
+	AvailabilityPct float64 `json:"availability_pct"`
+	DowntimeSeconds float64 `json:"downtime_seconds"`
+	Outages         int     `json:"outages"`
+	MTTRSeconds     float64 `json:"mttr_seconds"`
+	MTBFSeconds     float64 `json:"mtbf_seconds"`
+}
+
+// ConvertAvailability converts an AvailabilityReport into a flat AvailabilityExportData structure.
+func ConvertAvailability(r *timeline.AvailabilityReport, filters map[string]string) *AvailabilityExportData {
+	if filters == nil {
+		filters = map[string]string{}
+	}
+
+	services := make([]AvailabilityExportService, 0, len(r.Services))
+	for _, s := range r.Services {
+		services = append(services, AvailabilityExportService{
+			Service:         s.Service,
+			Namespace:       s.Namespace,
+			Dependencies:    s.Dependencies,
+			AvailabilityPct: s.Availability,
+			DowntimeSeconds: s.DowntimeSeconds,
+			Outages:         s.Outages,
+			MTTRSeconds:     s.MTTRSeconds,
+			MTBFSeconds:     s.MTBFSeconds,
+			Score:           s.Score,
+		})
+	}
+
+	edges := make([]AvailabilityExportEdge, 0, len(r.Edges))
+	for _, e := range r.Edges {
+		edges = append(edges, AvailabilityExportEdge{
+			Service:         e.Service,
+			Namespace:       e.Namespace,
+			Dependency:      e.Dependency,
+			Type:            e.Type,
+			Host:            e.Host,
+			Port:            e.Port,
+			Critical:        e.Critical,
+			AvailabilityPct: e.Availability,
+			DowntimeSeconds: e.DowntimeSeconds,
+			Outages:         e.Outages,
+			MTTRSeconds:     e.MTTRSeconds,
+			MTBFSeconds:     e.MTBFSeconds,
+		})
+	}
+
+	return &AvailabilityExportData{
+		Version:   "1.0",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		From:      r.Start.UTC().Format(time.RFC3339),
+		To:        r.End.UTC().Format(time.RFC3339),
+		Step:      r.Step,
+		Filters:   filters,
+		Services:  services,
+		Edges:     edges,
+	}
+}
+
+// ExportAvailabilityJSON serializes AvailabilityExportData to indented JSON bytes.
+func ExportAvailabilityJSON(data *AvailabilityExportData) ([]byte, error) {
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// ExportAvailabilityCSV produces a ZIP archive containing services.csv and edges.csv.
+func ExportAvailabilityCSV(data *AvailabilityExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if err := writeAvailabilityServicesCSV(zw, data.Services); err != nil {
+		return nil, fmt.Errorf("writing services.csv: %w", err)
+	}
+	if err := writeAvailabilityEdgesCSV(zw, data.Edges); err != nil {
+		return nil, fmt.Errorf("writing edges.csv: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeAvailabilityServicesCSV(zw *zip.Writer, services []AvailabilityExportService) error {
+	w, err := zw.Create("services.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"service", "namespace", "dependencies", "availability_pct", "downtime_seconds",
+		"outages", "mttr_seconds", "mtbf_seconds", "score",
+	}); err != nil {
+		return err
+	}
+	for _, s := range services {
+		score := ""
+		if s.Score != nil {
+			score = fmt.Sprintf("%.4f", *s.Score)
+		}
+		if err := cw.Write([]string{
+			s.Service,
+			s.Namespace,
+			fmt.Sprintf("%d", s.Dependencies),
+			fmt.Sprintf("%.4f", s.AvailabilityPct),
+			fmt.Sprintf("%g", s.DowntimeSeconds),
+			fmt.Sprintf("%d", s.Outages),
+			fmt.Sprintf("%g", s.MTTRSeconds),
+			fmt.Sprintf("%g", s.MTBFSeconds),
+			score,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeAvailabilityEdgesCSV(zw *zip.Writer, edges []AvailabilityExportEdge) error {
+	w, err := zw.Create("edges.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"service", "namespace", "dependency", "type", "host", "port", "critical",
+		"availability_pct", "downtime_seconds", "outages", "mttr_seconds", "mtbf_seconds",
+	}); err != nil {
+		return err
+	}
+	for _, e := range edges {
+		if err := cw.Write([]string{
+			e.Service,
+			e.Namespace,
+			e.Dependency,
+			e.Type,
+			e.Host,
+			e.Port,
+			fmt.Sprintf("%t", e.Critical),
+			fmt.Sprintf("%.4f", e.AvailabilityPct),
+			fmt.Sprintf("%g", e.DowntimeSeconds),
+			fmt.Sprintf("%d", e.Outages),
+			fmt.Sprintf("%g", e.MTTRSeconds),
+			fmt.Sprintf("%g", e.MTBFSeconds),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// AvailabilityFilename generates a filename for an exported availability report.
+func AvailabilityFilename(format string) string {
+	ts := time.Now().UTC().Format("20060102-150405")
+	return fmt.Sprintf("dephealth-availability-%s.%s", ts, format)
+}
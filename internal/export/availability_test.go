@@ -0,0 +1,80 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+)
+
+func sampleAvailabilityReport() *timeline.AvailabilityReport {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	score := 99.5
+	return &timeline.AvailabilityReport{
+		Start: start,
+		End:   start.Add(30 * 24 * time.Hour),
+		Step:  "1h0m0s",
+		Edges: []timeline.EdgeAvailability{{
+			Service: "orders", Namespace: "prod", Dependency: "postgres-main", Type: "postgres",
+			Host: "pg-main", Port: "5432", Critical: true,
+			AvailabilityStats: timeline.AvailabilityStats{Availability: 99.5, Samples: 720, DowntimeSeconds: 12600, Outages: 2, MTTRSeconds: 6300, MTBFSeconds: 1288800},
+		}},
+		Services: []timeline.ServiceAvailability{{
+			Service: "orders", Namespace: "prod", Dependencies: 1, Score: &score,
+			AvailabilityStats: timeline.AvailabilityStats{Availability: 99.5, Samples: 720, Outages: 2},
+		}},
+	}
+}
+
+func TestConvertAvailability(t *testing.T) {
+	data := ConvertAvailability(sampleAvailabilityReport(), map[string]string{"namespace": "prod"})
+
+	if data.From != "2026-03-01T00:00:00Z" || data.To != "2026-03-31T00:00:00Z" {
+		t.Errorf("From/To = %s/%s", data.From, data.To)
+	}
+	if len(data.Edges) != 1 || data.Edges[0].AvailabilityPct != 99.5 || data.Edges[0].MTTRSeconds != 6300 {
+		t.Errorf("Edges = %+v", data.Edges)
+	}
+	if len(data.Services) != 1 || data.Services[0].Score == nil || *data.Services[0].Score != 99.5 {
+		t.Errorf("Services = %+v", data.Services)
+	}
+
+	b, err := ExportAvailabilityJSON(data)
+	if err != nil {
+		t.Fatalf("ExportAvailabilityJSON error: %v", err)
+	}
+	var decoded AvailabilityExportData
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+}
+
+func TestExportAvailabilityCSV(t *testing.T) {
+	b, err := ExportAvailabilityCSV(ConvertAvailability(sampleAvailabilityReport(), nil))
+	if err != nil {
+		t.Fatalf("ExportAvailabilityCSV error: %v", err)
+	}
+
+	for _, name := range []string{"services.csv", "edges.csv"} {
+		content := readZipFile(t, b, name)
+		if !bytes.HasPrefix(content, utf8BOM) {
+			t.Errorf("%s missing UTF-8 BOM", name)
+		}
+		records, err := csv.NewReader(bytes.NewReader(content[len(utf8BOM):])).ReadAll()
+		if err != nil {
+			t.Fatalf("%s: csv parse error: %v", name, err)
+		}
+		if len(records) != 2 || records[0][0] != "service" || records[1][0] != "orders" {
+			t.Errorf("%s records = %v, want header + orders", name, records)
+		}
+	}
+
+	services := readZipFile(t, b, "services.csv")
+	records, _ := csv.NewReader(bytes.NewReader(services[len(utf8BOM):])).ReadAll()
+	if got := records[1][8]; got != "99.5000" {
+		t.Errorf("score = %q, want 99.5000", got)
+	}
+}
@@ -0,0 +1,306 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// Change kinds used in diff exports.
+const (
+	ChangeAdded       = "added"
+	ChangeRemoved     = "removed"
+	ChangeState       = "state_changed"
+	ChangeCriticality = "criticality_changed"
+	ChangeLatency     = "latency_changed"
+)
+
+// DiffExportData is the export structure for a topology diff between two points in time.
+type DiffExportData struct {
+	Version   string               `json:"version"`
+	Timestamp string               `json:"timestamp"`
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Filters   map[string]string    `json:"filters"`
+	Nodes     []DiffExportNode     `json:"nodes"`
+	Edges     []DiffExportEdge     `json:"edges"`
+	Summary   topology.DiffSummary `json:"summary"`
+}
+
+// DiffExportNode is a single node change.
+type DiffExportNode struct {
+	Change    string `json:"change"`
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Type      string `json:"type"`
+	FromState string `json:"from_state"`
+	ToState   string `json:"to_state"`
+}
+
+// DiffExportEdge is a single edge change. An edge with several kinds of
+// change (e.g. state and latency) appears once per kind.
+type DiffExportEdge struct {
+	Change         string  `json:"change"`
+	Source         string  `json:"source"`
+	Target         string  `json:"target"`
+	Type           string  `json:"type"`
+	FromState      string  `json:"from_state"`
+	ToState        string  `json:"to_state"`
+	FromCritical   bool    `json:"from_critical"`
+	ToCritical     bool    `json:"to_critical"`
+	FromLatencyMs  float64 `json:"from_latency_ms"`
+	ToLatencyMs    float64 `json:"to_latency_ms"`
+	LatencyDeltaMs float64 `json:"latency_delta_ms"`
+}
+
+// ConvertDiff converts a TopologyDiff into a flat DiffExportData structure.
+// Rows are ordered by change kind: added, removed, state, criticality, latency.
+func ConvertDiff(d *topology.TopologyDiff, filters map[string]string) *DiffExportData {
+	if filters == nil {
+		filters = map[string]string{}
+	}
+
+	nodes := make([]DiffExportNode, 0, len(d.AddedNodes)+len(d.RemovedNodes)+len(d.NodeStateChanges))
+	for _, n := range d.AddedNodes {
+		nodes = append(nodes, DiffExportNode{
+			Change: ChangeAdded, ID: n.ID, Name: n.Label, Namespace: n.Namespace, Type: n.Type,
+			ToState: n.State,
+		})
+	}
+	for _, n := range d.RemovedNodes {
+		nodes = append(nodes, DiffExportNode{
+			Change: ChangeRemoved, ID: n.ID, Name: n.Label, Namespace: n.Namespace, Type: n.Type,
+			FromState: n.State,
+		})
+	}
+	for _, c := range d.NodeStateChanges {
+		nodes = append(nodes, DiffExportNode{
+			Change: ChangeState, ID: c.ID, Name: c.Label, Namespace: c.Namespace, Type: c.Type,
+			FromState: c.FromState, ToState: c.ToState,
+		})
+	}
+
+	edges := make([]DiffExportEdge, 0, len(d.AddedEdges)+len(d.RemovedEdges)+
+		len(d.EdgeStateChanges)+len(d.CriticalityChanges)+len(d.LatencyChanges))
+	for _, e := range d.AddedEdges {
+		edges = append(edges, DiffExportEdge{
+			Change: ChangeAdded, Source: e.Source, Target: e.Target, Type: e.Type,
+			ToState: e.State, ToCritical: e.Critical, ToLatencyMs: e.LatencyRaw * 1000,
+		})
+	}
+	for _, e := range d.RemovedEdges {
+		edges = append(edges, DiffExportEdge{
+			Change: ChangeRemoved, Source: e.Source, Target: e.Target, Type: e.Type,
+			FromState: e.State, FromCritical: e.Critical, FromLatencyMs: e.LatencyRaw * 1000,
+		})
+	}
+	for _, c := range d.EdgeStateChanges {
+		edges = append(edges, DiffExportEdge{
+			Change: ChangeState, Source: c.Source, Target: c.Target, Type: c.Type,
+			FromState: c.FromState, ToState: c.ToState,
+		})
+	}
+	for _, c := range d.CriticalityChanges {
+		edges = append(edges, DiffExportEdge{
+			Change: ChangeCriticality, Source: c.Source, Target: c.Target, Type: c.Type,
+			FromCritical: c.FromCritical, ToCritical: c.ToCritical,
+		})
+	}
+	for _, c := range d.LatencyChanges {
+		edges = append(edges, DiffExportEdge{
+			Change: ChangeLatency, Source: c.Source, Target: c.Target, Type: c.Type,
+			FromLatencyMs: c.FromLatency * 1000, ToLatencyMs: c.ToLatency * 1000, LatencyDeltaMs: c.Delta * 1000,
+		})
+	}
+
+	return &DiffExportData{
+		Version:   "1.0",
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		From:      d.From.UTC().Format(time.RFC3339),
+		To:        d.To.UTC().Format(time.RFC3339),
+		Filters:   filters,
+		Nodes:     nodes,
+		Edges:     edges,
+		Summary:   d.Summary,
+	}
+}
+
+// ExportDiffJSON serializes DiffExportData to indented JSON bytes.
+func ExportDiffJSON(data *DiffExportData) ([]byte, error) {
+	return json.MarshalIndent(data, "", "  ")
+}
+
+// ExportDiffCSV produces a ZIP archive containing node_changes.csv and edge_changes.csv.
+func ExportDiffCSV(data *DiffExportData) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	if err := writeDiffNodesCSV(zw, data.Nodes); err != nil {
+		return nil, fmt.Errorf("writing node_changes.csv: %w", err)
+	}
+	if err := writeDiffEdgesCSV(zw, data.Edges); err != nil {
+		return nil, fmt.Errorf("writing edge_changes.csv: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("closing zip: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeDiffNodesCSV(zw *zip.Writer, nodes []DiffExportNode) error {
+	w, err := zw.Create("node_changes.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"change", "id", "name", "namespace", "type", "from_state", "to_state"}); err != nil {
+		return err
+	}
+	for _, n := range nodes {
+		if err := cw.Write([]string{
+			n.Change,
+			n.ID,
+			n.Name,
+			n.Namespace,
+			n.Type,
+			n.FromState,
+			n.ToState,
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeDiffEdgesCSV(zw *zip.Writer, edges []DiffExportEdge) error {
+	w, err := zw.Create("edge_changes.csv")
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"change", "source", "target", "type", "from_state", "to_state",
+		"from_critical", "to_critical", "from_latency_ms", "to_latency_ms", "latency_delta_ms",
+	}); err != nil {
+		return err
+	}
+	for _, e := range edges {
+		if err := cw.Write([]string{
+			e.Change,
+			e.Source,
+			e.Target,
+			e.Type,
+			e.FromState,
+			e.ToState,
+			fmt.Sprintf("%t", e.FromCritical),
+			fmt.Sprintf("%t", e.ToCritical),
+			fmt.Sprintf("%g", e.FromLatencyMs),
+			fmt.Sprintf("%g", e.ToLatencyMs),
+			fmt.Sprintf("%g", e.LatencyDeltaMs),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Diff colors: added elements green, removed red, changed by kind.
+const (
+	diffAddedColor       = "#28a745"
+	diffRemovedColor     = "#dc3545"
+	diffStateColor       = "#fd7e14"
+	diffCriticalityColor = "#6f42c1"
+	diffLatencyColor     = "#6c757d"
+)
+
+// ExportDiffDOT produces a Graphviz DOT graph of the changed nodes and edges.
+// Added elements are green, removed elements are red and dashed, state changes
+// are labelled with "from → to". An edge with several change kinds is drawn once,
+// using the first kind in ConvertDiff order.
+func ExportDiffDOT(data *DiffExportData, opts DOTOptions) ([]byte, error) {
+	rankDir := opts.RankDir
+	if rankDir == "" {
+		rankDir = "TB"
+	}
+
+	var b strings.Builder
+
+	b.WriteString("digraph dephealth_diff {\n")
+	fmt.Fprintf(&b, "  rankdir=%s;\n", rankDir)
+	b.WriteString("  node [shape=box, style=\"rounded,filled\"];\n\n")
+
+	for _, n := range data.Nodes {
+		switch n.Change {
+		case ChangeAdded:
+			fmt.Fprintf(&b, "  %s [fillcolor=%q, color=%q, penwidth=2];\n",
+				quoteDot(n.ID), stateColors["ok"], diffAddedColor)
+		case ChangeRemoved:
+			fmt.Fprintf(&b, "  %s [fillcolor=%q, color=%q, style=\"rounded,filled,dashed\"];\n",
+				quoteDot(n.ID), stateColors["unknown"], diffRemovedColor)
+		case ChangeState:
+			color := stateColors[n.ToState]
+			if color == "" {
+				color = stateColors["unknown"]
+			}
+			fmt.Fprintf(&b, "  %s [fillcolor=%q, label=%s];\n",
+				quoteDot(n.ID), color, quoteDot(n.ID+" ("+n.FromState+" → "+n.ToState+")"))
+		}
+	}
+	b.WriteString("\n")
+
+	seen := make(map[[2]string]bool, len(data.Edges))
+	for _, e := range data.Edges {
+		key := [2]string{e.Source, e.Target}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var attrs []string
+		switch e.Change {
+		case ChangeAdded:
+			attrs = []string{fmt.Sprintf("color=%q", diffAddedColor), "penwidth=2"}
+		case ChangeRemoved:
+			attrs = []string{fmt.Sprintf("color=%q", diffRemovedColor), "style=dashed"}
+		case ChangeState:
+			attrs = []string{fmt.Sprintf("color=%q", diffStateColor),
+				fmt.Sprintf("label=%s", quoteDot(e.FromState+" → "+e.ToState))}
+		case ChangeCriticality:
+			attrs = []string{fmt.Sprintf("color=%q", diffCriticalityColor),
+				fmt.Sprintf("label=%s", quoteDot(fmt.Sprintf("critical: %t → %t", e.FromCritical, e.ToCritical)))}
+		case ChangeLatency:
+			attrs = []string{fmt.Sprintf("color=%q", diffLatencyColor),
+				fmt.Sprintf("label=%s", quoteDot(fmt.Sprintf("%+.1fms", e.LatencyDeltaMs)))}
+		}
+
+		fmt.Fprintf(&b, "  %s -> %s [%s];\n",
+			quoteDot(e.Source), quoteDot(e.Target), strings.Join(attrs, ", "))
+	}
+
+	b.WriteString("}\n")
+	return []byte(b.String()), nil
+}
+
+// DiffFilename generates a filename for an exported topology diff.
+func DiffFilename(format string) string {
+	ts := time.Now().UTC().Format("20060102-150405")
+	return fmt.Sprintf("dephealth-diff-%s.%s", ts, format)
+}
@@ -0,0 +1,137 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func sampleTopologyDiff() *topology.TopologyDiff {
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &topology.TopologyDiff{
+		From:         from,
+		To:           from.Add(24 * time.Hour),
+		AddedNodes:   []topology.Node{{ID: "pg:5432", Label: "postgres", Type: "postgres", State: "ok"}},
+		RemovedNodes: []topology.Node{{ID: "redis:6379", Label: "redis", Type: "redis", State: "ok"}},
+		AddedEdges:   []topology.Edge{{Source: "svc-b", Target: "pg:5432", Type: "postgres", State: "ok", LatencyRaw: 0.003}},
+		RemovedEdges: []topology.Edge{{Source: "svc-b", Target: "redis:6379", Type: "redis", State: "ok"}},
+		NodeStateChanges: []topology.NodeStateChange{
+			{ID: "svc-a", Label: "svc-a", Type: "service", FromState: "ok", ToState: "degraded"},
+		},
+		EdgeStateChanges: []topology.EdgeStateChange{
+			{Source: "svc-a", Target: "svc-b", Type: "http", FromState: "ok", ToState: "down"},
+		},
+		CriticalityChanges: []topology.CriticalityChange{
+			{Source: "svc-a", Target: "redis:6379", FromCritical: false, ToCritical: true},
+		},
+		LatencyChanges: []topology.LatencyChange{
+			{Source: "svc-a", Target: "svc-b", FromLatency: 0.010, ToLatency: 0.050, Delta: 0.040},
+		},
+		Summary: topology.DiffSummary{AddedNodes: 1, RemovedNodes: 1, AddedEdges: 1, RemovedEdges: 1,
+			NodeStateChanges: 1, EdgeStateChanges: 1, CriticalityChanges: 1, LatencyChanges: 1},
+	}
+}
+
+func TestConvertDiff(t *testing.T) {
+	data := ConvertDiff(sampleTopologyDiff(), map[string]string{"namespace": "prod"})
+
+	if data.From != "2026-03-01T12:00:00Z" || data.To != "2026-03-02T12:00:00Z" {
+		t.Errorf("From/To = %s/%s", data.From, data.To)
+	}
+	if data.Filters["namespace"] != "prod" {
+		t.Errorf("Filters = %v, want namespace=prod", data.Filters)
+	}
+	if len(data.Nodes) != 3 {
+		t.Fatalf("Nodes = %d, want 3", len(data.Nodes))
+	}
+	wantNodeKinds := []string{ChangeAdded, ChangeRemoved, ChangeState}
+	for i, k := range wantNodeKinds {
+		if data.Nodes[i].Change != k {
+			t.Errorf("Nodes[%d].Change = %q, want %q", i, data.Nodes[i].Change, k)
+		}
+	}
+	if len(data.Edges) != 5 {
+		t.Fatalf("Edges = %d, want 5", len(data.Edges))
+	}
+	lat := data.Edges[4]
+	if lat.Change != ChangeLatency || lat.LatencyDeltaMs < 39.9 || lat.LatencyDeltaMs > 40.1 {
+		t.Errorf("latency row = %+v, want latency_changed with 40ms delta", lat)
+	}
+	if data.Edges[0].ToLatencyMs < 2.9 || data.Edges[0].ToLatencyMs > 3.1 {
+		t.Errorf("added edge ToLatencyMs = %v, want 3", data.Edges[0].ToLatencyMs)
+	}
+}
+
+func TestExportDiffJSON(t *testing.T) {
+	b, err := ExportDiffJSON(ConvertDiff(sampleTopologyDiff(), nil))
+	if err != nil {
+		t.Fatalf("ExportDiffJSON error: %v", err)
+	}
+	var got DiffExportData
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if got.Summary.LatencyChanges != 1 {
+		t.Errorf("Summary.LatencyChanges = %d, want 1", got.Summary.LatencyChanges)
+	}
+}
+
+func TestExportDiffCSV(t *testing.T) {
+	b, err := ExportDiffCSV(ConvertDiff(sampleTopologyDiff(), nil))
+	if err != nil {
+		t.Fatalf("ExportDiffCSV error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("zip.NewReader error: %v", err)
+	}
+	if len(zr.File) != 2 || zr.File[0].Name != "node_changes.csv" || zr.File[1].Name != "edge_changes.csv" {
+		t.Fatalf("unexpected ZIP contents: %d files", len(zr.File))
+	}
+
+	content := readZipFile(t, b, "edge_changes.csv")
+	if !bytes.HasPrefix(content, utf8BOM) {
+		t.Error("edge_changes.csv missing UTF-8 BOM")
+	}
+	records, err := csv.NewReader(bytes.NewReader(content[len(utf8BOM):])).ReadAll()
+	if err != nil {
+		t.Fatalf("csv parse error: %v", err)
+	}
+	if len(records) != 6 {
+		t.Errorf("edge_changes.csv rows = %d, want 6 (header + 5)", len(records))
+	}
+	if records[0][0] != "change" {
+		t.Errorf("header[0] = %q, want change", records[0][0])
+	}
+}
+
+func TestExportDiffDOT(t *testing.T) {
+	b, err := ExportDiffDOT(ConvertDiff(sampleTopologyDiff(), nil), DOTOptions{})
+	if err != nil {
+		t.Fatalf("ExportDiffDOT error: %v", err)
+	}
+	dot := string(b)
+
+	if !strings.HasPrefix(dot, "digraph dephealth_diff {") {
+		t.Error("DOT output should start with 'digraph dephealth_diff {'")
+	}
+	if !strings.Contains(dot, `"svc-b" -> "pg:5432" [color="#28a745"`) {
+		t.Error("added edge should be green")
+	}
+	if !strings.Contains(dot, `"svc-b" -> "redis:6379" [color="#dc3545", style=dashed]`) {
+		t.Error("removed edge should be red and dashed")
+	}
+	if strings.Count(dot, `"svc-a" -> "svc-b"`) != 1 {
+		t.Error("edge with state and latency change should be drawn once")
+	}
+	if !strings.Contains(dot, `label="ok → down"`) {
+		t.Error("state change edge should be labelled with transition")
+	}
+}
@@ -14,6 +14,8 @@ type Config struct {
 	Username string // Basic auth username
 	Password string // Basic auth password
 	Timeout  time.Duration
+	// Transport carries TLS, proxy and header settings (nil = http.DefaultTransport).
+	Transport http.RoundTripper
 }
 
 // Checker validates Grafana availability and dashboard existence.
@@ -30,7 +32,7 @@ func NewChecker(cfg Config) *Checker {
 	}
 	return &Checker{
 		cfg:  cfg,
-		http: &http.Client{Timeout: timeout},
+		http: &http.Client{Timeout: timeout, Transport: cfg.Transport},
 	}
 }
 
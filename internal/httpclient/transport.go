@@ -0,0 +1,145 @@
+// Package httpclient builds HTTP transports for outgoing connections to
+// datasources and Grafana from config.HTTPClientConfig.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// NewTransport returns an http.RoundTripper that applies cfg: TLS and proxy
+// settings on the connection, bearer token and extra headers on every
+// request. A token file is re-read whenever its modification time changes.
+func NewTransport(cfg config.HTTPClientConfig) (http.RoundTripper, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsCfg, err := newTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		base.TLSClientConfig = tlsCfg
+	}
+	if cfg.ProxyURL != "" {
+		proxy, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("parsing proxy URL: %w", err)
+		}
+		base.Proxy = http.ProxyURL(proxy)
+	}
+
+	if cfg.BearerToken == "" && cfg.BearerTokenFile == "" && len(cfg.Headers) == 0 {
+		return base, nil
+	}
+	t := &transport{base: base, headers: cfg.Headers, token: cfg.BearerToken}
+	if cfg.BearerTokenFile != "" {
+		t.tokenFile = &tokenFile{path: cfg.BearerTokenFile}
+		// Fail at startup on an unreadable file rather than on the first request.
+		if _, err := t.tokenFile.get(); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// newTLSConfig returns the TLS client configuration for cfg, or nil when
+// the defaults apply.
+func newTLSConfig(cfg config.TLSConfig) (*tls.Config, error) {
+	if cfg == (config.TLSConfig{}) {
+		return nil, nil
+	}
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if cfg.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+// transport adds the bearer token and extra headers to every request.
+type transport struct {
+	base      http.RoundTripper
+	headers   map[string]string
+	token     string
+	tokenFile *tokenFile
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token := t.token
+	if t.tokenFile != nil {
+		var err error
+		if token, err = t.tokenFile.get(); err != nil {
+			return nil, err
+		}
+	}
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// tokenFile caches a bearer token read from a file and re-reads it when the
+// file's modification time changes.
+type tokenFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+// get returns the current token. When the file cannot be read after it was
+// read once, the last token is kept so that a rotation in progress does not
+// fail requests.
+func (f *tokenFile) get() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, err := os.Stat(f.path)
+	if err == nil && info.ModTime().Equal(f.modTime) && f.token != "" {
+		return f.token, nil
+	}
+	var data []byte
+	if err == nil {
+		data, err = os.ReadFile(f.path)
+	}
+	if err != nil {
+		if f.token != "" {
+			return f.token, nil
+		}
+		return "", fmt.Errorf("reading bearer token file: %w", err)
+	}
+	f.token = strings.TrimSpace(string(data))
+	f.modTime = info.ModTime()
+	return f.token, nil
+}
@@ -0,0 +1,169 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// get sends a GET request to url through rt and returns the status code.
+func get(t *testing.T, rt http.RoundTripper, url string) int {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBearerTokenAndHeaders(t *testing.T) {
+	var auth, tenant string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		auth, tenant = r.Header.Get("Authorization"), r.Header.Get("X-Scope-OrgID")
+	}))
+	defer srv.Close()
+
+	rt, err := NewTransport(config.HTTPClientConfig{
+		BearerToken: "secret",
+		Headers:     map[string]string{"X-Scope-OrgID": "team-a"},
+	})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	get(t, rt, srv.URL)
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer secret")
+	}
+	if tenant != "team-a" {
+		t.Errorf("X-Scope-OrgID = %q, want %q", tenant, "team-a")
+	}
+}
+
+func TestBearerTokenFileRotation(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	path := writeFile(t, "token", []byte("first\n"))
+	rt, err := NewTransport(config.HTTPClientConfig{BearerTokenFile: path})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	get(t, rt, srv.URL)
+	if auth != "Bearer first" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer first")
+	}
+
+	if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	get(t, rt, srv.URL)
+	if auth != "Bearer second" {
+		t.Errorf("Authorization = %q after rotation, want %q", auth, "Bearer second")
+	}
+
+	// A missing file keeps the last token.
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	get(t, rt, srv.URL)
+	if auth != "Bearer second" {
+		t.Errorf("Authorization = %q after removal, want %q", auth, "Bearer second")
+	}
+
+	if _, err := NewTransport(config.HTTPClientConfig{BearerTokenFile: path}); err == nil {
+		t.Error("expected error for a missing token file")
+	}
+}
+
+func TestCustomCAAndClientCertificate(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The server certificate doubles as the client certificate.
+	cert := srv.TLS.Certificates[0]
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]})
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := writeFile(t, "ca.pem", certPEM)
+	certFile := writeFile(t, "cert.pem", certPEM)
+	keyFile := writeFile(t, "key.pem", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	rt, err := NewTransport(config.HTTPClientConfig{TLS: config.TLSConfig{CAFile: caFile, CertFile: certFile, KeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	if code := get(t, rt, srv.URL); code != http.StatusOK {
+		t.Errorf("status = %d, want 200", code)
+	}
+
+	// Without the CA the server certificate is not trusted.
+	rt, err = NewTransport(config.HTTPClientConfig{TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile}})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	if _, err := (&http.Client{Transport: rt}).Get(srv.URL); err == nil {
+		t.Error("expected certificate verification error without CA file")
+	}
+
+	rt, err = NewTransport(config.HTTPClientConfig{TLS: config.TLSConfig{CertFile: certFile, KeyFile: keyFile, InsecureSkipVerify: true}})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	if code := get(t, rt, srv.URL); code != http.StatusOK {
+		t.Errorf("status = %d with insecureSkipVerify, want 200", code)
+	}
+}
+
+func TestProxyURL(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	rt, err := NewTransport(config.HTTPClientConfig{ProxyURL: proxy.URL})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	get(t, rt, "http://vm.example.invalid:8428/api/v1/query")
+	if proxied != "http://vm.example.invalid:8428/api/v1/query" {
+		t.Errorf("proxy received %q, want the absolute target URL", proxied)
+	}
+}
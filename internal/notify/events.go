@@ -0,0 +1,225 @@
+// Package notify detects state transitions between successive topology
+// builds and delivers them to webhooks.
+package notify
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// Event kinds. They match config.NotificationEventKinds.
+const (
+	KindNodeState          = "node_state"
+	KindEdgeState          = "edge_state"
+	KindServiceAppeared    = "service_appeared"
+	KindServiceDisappeared = "service_disappeared"
+	KindRootCause          = "root_cause"
+)
+
+// Event is a single transition between two successive topology builds.
+type Event struct {
+	Kind    string    `json:"kind"`
+	Time    time.Time `json:"time"`    // when the later build was made
+	Summary string    `json:"summary"` // human-readable one-liner
+	// Node identity; for root_cause events the failing node.
+	NodeID    string `json:"nodeId,omitempty"`
+	Label     string `json:"label,omitempty"`
+	NodeType  string `json:"nodeType,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// Edge identity, set on edge_state events.
+	Source     string `json:"source,omitempty"`
+	Target     string `json:"target,omitempty"`
+	Dependency string `json:"dependency,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Critical   bool   `json:"critical,omitempty"`
+	Detail     string `json:"detail,omitempty"` // edge status detail after the transition
+	Cluster    string `json:"cluster,omitempty"`
+	FromState  string `json:"fromState,omitempty"`
+	ToState    string `json:"toState,omitempty"`
+	// Services affected by a new root cause.
+	AffectedServices []string `json:"affectedServices,omitempty"`
+}
+
+// Detector compares each topology with the previous one and reports the
+// transitions between them. The first topology only sets the baseline.
+// It is not safe for concurrent use.
+type Detector struct {
+	prev      *topology.TopologyResponse
+	prevRoots map[string]bool
+}
+
+// Next records resp as the latest topology and returns the transitions from
+// the previous one. Stale and historical responses are ignored: they do not
+// describe the current state.
+func (d *Detector) Next(resp *topology.TopologyResponse) []Event {
+	if resp == nil || resp.Meta.Stale || resp.Meta.IsHistory {
+		return nil
+	}
+	analysis := cascade.Analyze(resp.Nodes, resp.Edges, cascade.Options{})
+	roots := make(map[string]bool, len(analysis.RootCauses))
+	for _, rc := range analysis.RootCauses {
+		roots[rc.ID] = true
+	}
+
+	prev, prevRoots := d.prev, d.prevRoots
+	d.prev, d.prevRoots = resp, roots
+	if prev == nil {
+		return nil
+	}
+
+	at := resp.Meta.CachedAt
+	if at.IsZero() {
+		at = time.Now().UTC()
+	}
+	events := nodeEvents(prev, resp, at)
+	events = append(events, edgeEvents(prev, resp, at)...)
+	events = append(events, rootCauseEvents(analysis, prevRoots, resp, at)...)
+	return events
+}
+
+func nodeEvents(prev, next *topology.TopologyResponse, at time.Time) []Event {
+	prevNodes := make(map[string]topology.Node, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		prevNodes[n.ID] = n
+	}
+	nextNodes := make(map[string]bool, len(next.Nodes))
+
+	var events []Event
+	for _, n := range next.Nodes {
+		nextNodes[n.ID] = true
+		old, ok := prevNodes[n.ID]
+		switch {
+		case !ok && n.Type == "service":
+			ev := nodeEvent(KindServiceAppeared, n, at)
+			ev.ToState = n.State
+			ev.Summary = fmt.Sprintf("service %s appeared (%s)", n.Label, n.State)
+			events = append(events, ev)
+		case ok && old.State != n.State:
+			ev := nodeEvent(KindNodeState, n, at)
+			ev.FromState, ev.ToState = old.State, n.State
+			ev.Summary = fmt.Sprintf("%s: %s → %s", n.Label, old.State, n.State)
+			events = append(events, ev)
+		}
+	}
+	for _, n := range prev.Nodes {
+		if !nextNodes[n.ID] && n.Type == "service" {
+			ev := nodeEvent(KindServiceDisappeared, n, at)
+			ev.FromState = n.State
+			ev.Summary = fmt.Sprintf("service %s disappeared", n.Label)
+			events = append(events, ev)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].NodeID < events[j].NodeID })
+	return events
+}
+
+func nodeEvent(kind string, n topology.Node, at time.Time) Event {
+	return Event{
+		Kind:      kind,
+		Time:      at,
+		NodeID:    n.ID,
+		Label:     n.Label,
+		NodeType:  n.Type,
+		Namespace: n.Namespace,
+		Cluster:   n.Cluster,
+	}
+}
+
+func edgeEvents(prev, next *topology.TopologyResponse, at time.Time) []Event {
+	prevEdges := make(map[topology.EdgeRef]topology.Edge, len(prev.Edges))
+	for _, e := range prev.Edges {
+		prevEdges[topology.EdgeRef{Source: e.Source, Target: e.Target}] = e
+	}
+	labels := make(map[string]string, len(next.Nodes))
+	namespaces := make(map[string]string, len(next.Nodes))
+	for _, n := range next.Nodes {
+		labels[n.ID] = n.Label
+		namespaces[n.ID] = n.Namespace
+	}
+
+	var events []Event
+	for _, e := range next.Edges {
+		old, ok := prevEdges[topology.EdgeRef{Source: e.Source, Target: e.Target}]
+		if !ok || old.State == e.State {
+			continue
+		}
+		dep := e.Dependency
+		if dep == "" {
+			dep = labelOr(labels, e.Target)
+		}
+		events = append(events, Event{
+			Kind:       KindEdgeState,
+			Time:       at,
+			Summary:    fmt.Sprintf("%s → %s: %s → %s", labelOr(labels, e.Source), dep, old.State, e.State),
+			Namespace:  namespaces[e.Source],
+			Source:     e.Source,
+			Target:     e.Target,
+			Dependency: e.Dependency,
+			Type:       e.Type,
+			Critical:   e.Critical,
+			Detail:     e.Detail,
+			Cluster:    e.Cluster,
+			FromState:  old.State,
+			ToState:    e.State,
+		})
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].Source != events[j].Source {
+			return events[i].Source < events[j].Source
+		}
+		return events[i].Target < events[j].Target
+	})
+	return events
+}
+
+func rootCauseEvents(analysis *cascade.AnalysisResult, prevRoots map[string]bool, next *topology.TopologyResponse, at time.Time) []Event {
+	var events []Event
+	for _, rc := range analysis.RootCauses {
+		if prevRoots[rc.ID] {
+			continue
+		}
+		var affected []string
+		for _, a := range analysis.AffectedServices {
+			if a.Service != rc.ID && slices.Contains(a.RootCauses, rc.ID) {
+				affected = append(affected, a.Service)
+			}
+		}
+		sort.Strings(affected)
+		summary := fmt.Sprintf("new root cause %s (%s)", rc.Label, rc.State)
+		if len(affected) > 0 {
+			summary += ", affecting " + strings.Join(affected, ", ")
+		}
+		ev := Event{
+			Kind:             KindRootCause,
+			Time:             at,
+			Summary:          summary,
+			NodeID:           rc.ID,
+			Label:            rc.Label,
+			NodeType:         rc.Type,
+			Namespace:        rc.Namespace,
+			ToState:          rc.State,
+			AffectedServices: affected,
+		}
+		for _, n := range next.Nodes {
+			if n.ID == rc.ID {
+				ev.Cluster = n.Cluster
+				break
+			}
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func labelOr(labels map[string]string, id string) string {
+	if l := labels[id]; l != "" {
+		return l
+	}
+	return id
+}
@@ -0,0 +1,104 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// testTopology returns web → api → pg with the given api/pg states.
+func testTopology(apiState, pgState string) *topology.TopologyResponse {
+	edgeState := func(s string) string {
+		if s == "down" {
+			return "down"
+		}
+		return "ok"
+	}
+	return &topology.TopologyResponse{
+		Nodes: []topology.Node{
+			{ID: "web", Label: "web", Type: "service", State: "ok", Namespace: "prod"},
+			{ID: "api", Label: "api", Type: "service", State: apiState, Namespace: "prod"},
+			{ID: "pg", Label: "pg", Type: "postgres", State: pgState, Namespace: "prod"},
+		},
+		Edges: []topology.Edge{
+			{Source: "web", Target: "api", Type: "http", Dependency: "api", Critical: true, State: edgeState(apiState)},
+			{Source: "api", Target: "pg", Type: "postgres", Dependency: "pg-main", Critical: true, State: edgeState(pgState)},
+		},
+		Meta: topology.TopologyMeta{CachedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+}
+
+func kinds(events []Event) map[string]int {
+	m := make(map[string]int)
+	for _, ev := range events {
+		m[ev.Kind]++
+	}
+	return m
+}
+
+func TestDetector_BaselineAndTransitions(t *testing.T) {
+	var d Detector
+	if evs := d.Next(testTopology("ok", "ok")); len(evs) != 0 {
+		t.Fatalf("first topology should only set the baseline, got %+v", evs)
+	}
+	if evs := d.Next(testTopology("ok", "ok")); len(evs) != 0 {
+		t.Fatalf("unchanged topology produced events: %+v", evs)
+	}
+
+	evs := d.Next(testTopology("down", "down"))
+	got := kinds(evs)
+	if got[KindNodeState] != 2 || got[KindEdgeState] != 2 || got[KindRootCause] != 1 {
+		t.Fatalf("event kinds = %v, want 2 node, 2 edge, 1 root cause", got)
+	}
+	for _, ev := range evs {
+		switch ev.Kind {
+		case KindEdgeState:
+			if ev.Source == "api" && (ev.FromState != "ok" || ev.ToState != "down" || ev.Dependency != "pg-main" || !ev.Critical) {
+				t.Errorf("api → pg edge event = %+v", ev)
+			}
+		case KindRootCause:
+			if ev.NodeID != "pg" || len(ev.AffectedServices) != 1 || ev.AffectedServices[0] != "web" {
+				t.Errorf("root cause event = %+v, want pg affecting web", ev)
+			}
+		}
+		if ev.Summary == "" || !ev.Time.Equal(time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)) {
+			t.Errorf("event %+v lacks summary or build time", ev)
+		}
+	}
+
+	// The same root cause is not reported again while it persists.
+	if evs := d.Next(testTopology("down", "down")); len(evs) != 0 {
+		t.Errorf("persisting failure produced events: %+v", evs)
+	}
+}
+
+func TestDetector_ServicesAppearAndDisappear(t *testing.T) {
+	var d Detector
+	d.Next(testTopology("ok", "ok"))
+
+	next := testTopology("ok", "ok")
+	next.Nodes = append(next.Nodes[:1], next.Nodes[2], topology.Node{ID: "billing", Label: "billing", Type: "service", State: "ok"})
+	evs := d.Next(next)
+	got := kinds(evs)
+	if got[KindServiceAppeared] != 1 || got[KindServiceDisappeared] != 1 || len(evs) != 2 {
+		t.Fatalf("events = %+v, want billing appeared and api disappeared", evs)
+	}
+}
+
+func TestDetector_IgnoresStaleAndHistory(t *testing.T) {
+	var d Detector
+	d.Next(testTopology("ok", "ok"))
+
+	stale := testTopology("down", "down")
+	stale.Meta.Stale = true
+	history := testTopology("down", "down")
+	history.Meta.IsHistory = true
+	if evs := append(d.Next(stale), d.Next(history)...); len(evs) != 0 {
+		t.Errorf("stale/history responses produced events: %+v", evs)
+	}
+	// The baseline is still the last live topology.
+	if evs := d.Next(testTopology("ok", "ok")); len(evs) != 0 {
+		t.Errorf("events after stale responses = %+v, want none", evs)
+	}
+}
@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// Request headers set on every webhook delivery.
+const (
+	HeaderDelivery  = "X-Dephealth-Delivery"  // unique per batch, identical across retries
+	HeaderTimestamp = "X-Dephealth-Timestamp" // Unix seconds of the attempt
+	HeaderSignature = "X-Dephealth-Signature" // "sha256=<hex>", only with a secret
+)
+
+// queueSize bounds the events waiting for delivery per webhook. When a
+// receiver is down long enough to fill it, new events are dropped.
+const queueSize = 1000
+
+// Payload is the JSON body of a webhook request.
+type Payload struct {
+	Version string    `json:"version"`
+	Webhook string    `json:"webhook"`
+	SentAt  time.Time `json:"sentAt"`
+	Events  []Event   `json:"events"`
+}
+
+// Notifier turns background-built topologies into transition events and
+// delivers them, batched, to the configured webhooks.
+type Notifier struct {
+	cfg      config.NotificationsConfig
+	logger   *slog.Logger
+	client   *http.Client
+	webhooks []*webhook
+
+	mu       sync.Mutex
+	detector Detector
+}
+
+// webhook is the delivery queue of a single configured webhook.
+type webhook struct {
+	cfg   config.WebhookConfig
+	queue chan Event
+}
+
+// New creates a Notifier for the configured webhooks. Deliveries start with Run.
+func New(cfg config.NotificationsConfig, logger *slog.Logger) *Notifier {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	n := &Notifier{
+		cfg:    cfg,
+		logger: logger,
+		client: &http.Client{Timeout: cfg.Timeout},
+	}
+	for _, wh := range cfg.Webhooks {
+		n.webhooks = append(n.webhooks, &webhook{cfg: wh, queue: make(chan Event, queueSize)})
+	}
+	return n
+}
+
+// Observe compares resp with the previously observed topology and queues
+// the transitions for delivery. It never blocks on delivery.
+func (n *Notifier) Observe(resp *topology.TopologyResponse) {
+	n.mu.Lock()
+	events := n.detector.Next(resp)
+	n.mu.Unlock()
+
+	for _, ev := range events {
+		for _, wh := range n.webhooks {
+			if len(wh.cfg.Events) > 0 && !slices.Contains(wh.cfg.Events, ev.Kind) {
+				continue
+			}
+			select {
+			case wh.queue <- ev:
+			default:
+				n.logger.Warn("webhook queue full, dropping event", "webhook", wh.cfg.Name, "kind", ev.Kind)
+			}
+		}
+	}
+}
+
+// Run delivers queued events until ctx is cancelled.
+func (n *Notifier) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, wh := range n.webhooks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.runWebhook(ctx, wh)
+		}()
+	}
+	wg.Wait()
+}
+
+// runWebhook collects events for up to BatchWindow (or MaxBatchSize events)
+// after the first one arrives and delivers them in one request.
+func (n *Notifier) runWebhook(ctx context.Context, wh *webhook) {
+	for {
+		var batch []Event
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-wh.queue:
+			batch = append(batch, ev)
+		}
+
+		timer := time.NewTimer(n.cfg.BatchWindow)
+	collect:
+		for len(batch) < n.cfg.MaxBatchSize {
+			select {
+			case ev := <-wh.queue:
+				batch = append(batch, ev)
+			case <-timer.C:
+				break collect
+			case <-ctx.Done():
+				break collect
+			}
+		}
+		timer.Stop()
+
+		if err := n.deliver(ctx, wh.cfg, batch); err != nil {
+			n.logger.Error("webhook delivery failed", "webhook", wh.cfg.Name, "events", len(batch), "error", err)
+		}
+	}
+}
+
+// deliver posts batch to the webhook, retrying network errors, 429 and 5xx
+// responses up to MaxAttempts times with exponential backoff.
+func (n *Notifier) deliver(ctx context.Context, wh config.WebhookConfig, batch []Event) error {
+	body, err := json.Marshal(Payload{
+		Version: "1",
+		Webhook: wh.Name,
+		SentAt:  time.Now().UTC(),
+		Events:  batch,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding payload: %w", err)
+	}
+	delivery := newDeliveryID()
+
+	backoff := n.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := n.post(ctx, wh, delivery, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.cfg.MaxAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+		n.logger.Warn("webhook delivery attempt failed, retrying", "webhook", wh.Name, "attempt", attempt, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("attempt %d: %w (shutting down)", attempt, err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post makes a single delivery attempt and reports whether a failure is worth retrying.
+func (n *Notifier) post(ctx context.Context, wh config.WebhookConfig, delivery string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, delivery)
+	req.Header.Set(HeaderTimestamp, ts)
+	if wh.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(wh.Secret, ts, body))
+	}
+	for k, v := range wh.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %d", resp.StatusCode)
+}
+
+// Sign returns the signature header value for a request body: the hex
+// HMAC-SHA256 of "<timestamp>.<body>" keyed with secret, prefixed "sha256=".
+// Receivers recompute it from the X-Dephealth-Timestamp header and the raw body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,175 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+func testNotificationsConfig(webhooks ...config.WebhookConfig) config.NotificationsConfig {
+	return config.NotificationsConfig{
+		Webhooks:     webhooks,
+		BatchWindow:  20 * time.Millisecond,
+		MaxBatchSize: 100,
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+		Timeout:      time.Second,
+	}
+}
+
+// receiver records webhook requests and answers with the queued status codes
+// (200 once they run out).
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	payloads []Payload
+	bodies   [][]byte
+	got      chan struct{}
+}
+
+func newReceiver(statuses ...int) (*receiver, *httptest.Server) {
+	rc := &receiver{statuses: statuses, got: make(chan struct{}, 10)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var p Payload
+		_ = json.Unmarshal(body, &p)
+
+		rc.mu.Lock()
+		status := http.StatusOK
+		if len(rc.statuses) > 0 {
+			status, rc.statuses = rc.statuses[0], rc.statuses[1:]
+		}
+		rc.requests = append(rc.requests, r)
+		rc.payloads = append(rc.payloads, p)
+		rc.bodies = append(rc.bodies, body)
+		rc.mu.Unlock()
+
+		w.WriteHeader(status)
+		if status < 300 {
+			rc.got <- struct{}{}
+		}
+	}))
+	return rc, srv
+}
+
+func (rc *receiver) wait(t *testing.T) {
+	t.Helper()
+	select {
+	case <-rc.got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a webhook delivery")
+	}
+}
+
+// observeOutage feeds the notifier a healthy and then a failing topology.
+func observeOutage(n *Notifier) {
+	n.Observe(testTopology("ok", "ok"))
+	n.Observe(testTopology("down", "down"))
+}
+
+func TestNotifier_BatchesAndSigns(t *testing.T) {
+	rc, srv := newReceiver()
+	defer srv.Close()
+
+	n := New(testNotificationsConfig(config.WebhookConfig{
+		Name:    "ops",
+		URL:     srv.URL,
+		Secret:  "s3cret",
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	observeOutage(n)
+	rc.wait(t)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.payloads) != 1 {
+		t.Fatalf("requests = %d, want 1 batched request", len(rc.payloads))
+	}
+	p := rc.payloads[0]
+	if p.Webhook != "ops" || len(p.Events) != 5 {
+		t.Errorf("payload webhook = %q, events = %d; want ops, 5", p.Webhook, len(p.Events))
+	}
+	r := rc.requests[0]
+	if r.Header.Get("Authorization") != "Bearer token" || r.Header.Get(HeaderDelivery) == "" {
+		t.Errorf("headers = %v", r.Header)
+	}
+	want := Sign("s3cret", r.Header.Get(HeaderTimestamp), rc.bodies[0])
+	if got := r.Header.Get(HeaderSignature); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+}
+
+func TestNotifier_RetriesServerErrors(t *testing.T) {
+	rc, srv := newReceiver(http.StatusServiceUnavailable, http.StatusBadGateway)
+	defer srv.Close()
+
+	n := New(testNotificationsConfig(config.WebhookConfig{Name: "ops", URL: srv.URL}), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	observeOutage(n)
+	rc.wait(t)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.requests) != 3 {
+		t.Fatalf("attempts = %d, want 3", len(rc.requests))
+	}
+	id := rc.requests[0].Header.Get(HeaderDelivery)
+	for _, r := range rc.requests[1:] {
+		if r.Header.Get(HeaderDelivery) != id {
+			t.Error("retries should reuse the delivery ID")
+		}
+	}
+}
+
+func TestNotifier_DoesNotRetryClientErrors(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	n := New(testNotificationsConfig(config.WebhookConfig{Name: "ops", URL: srv.URL}), nil)
+	err := n.deliver(context.Background(), n.cfg.Webhooks[0], []Event{{Kind: KindNodeState}})
+	if err == nil {
+		t.Fatal("deliver() should fail on 400")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("attempts = %d, want 1", attempts.Load())
+	}
+}
+
+func TestNotifier_FiltersEventKinds(t *testing.T) {
+	rc, srv := newReceiver()
+	defer srv.Close()
+
+	n := New(testNotificationsConfig(config.WebhookConfig{Name: "rca", URL: srv.URL, Events: []string{KindRootCause}}), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go n.Run(ctx)
+
+	observeOutage(n)
+	rc.wait(t)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if evs := rc.payloads[0].Events; len(evs) != 1 || evs[0].Kind != KindRootCause {
+		t.Errorf("events = %+v, want only the root cause", evs)
+	}
+}
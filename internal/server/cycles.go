@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// cyclesResponse is the JSON body of GET /api/v1/cycles.
+type cyclesResponse struct {
+	Cycles  []topology.Cycle `json:"cycles"`
+	Summary struct {
+		Cycles         int `json:"cycles"`
+		CriticalCycles int `json:"criticalCycles"`
+		Nodes          int `json:"nodes"` // nodes in any reported cycle
+	} `json:"summary"`
+}
+
+// handleCycles handles GET /api/v1/cycles.
+// Query parameters:
+//   - namespace: optional; only cycles with a node in this namespace
+//   - critical: optional; "true" reports only cycles of critical edges
+//   - time: optional RFC3339 timestamp to analyze the historical topology
+func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	namespace := q.Get("namespace")
+	criticalOnly := q.Get("critical") == "true"
+
+	var resp *topology.TopologyResponse
+	var err error
+	if ts := q.Get("time"); ts != "" {
+		t, perr := time.Parse(time.RFC3339, ts)
+		if perr != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid time parameter: must be RFC3339 format")
+			return
+		}
+		resp, err = s.historicalTopology(r.Context(), topology.QueryOptions{Time: &t})
+	} else {
+		resp, _, err = s.live(r.Context()).get(r.Context())
+	}
+	if err != nil {
+		s.logger.Error("failed to build topology for cycle detection", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
+		return
+	}
+
+	nsOf := make(map[string]string, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		nsOf[n.ID] = n.Namespace
+	}
+
+	out := cyclesResponse{Cycles: []topology.Cycle{}}
+	for _, c := range topology.FindCycles(resp.Nodes, resp.Edges) {
+		if criticalOnly && !c.Critical {
+			continue
+		}
+		if namespace != "" && !slices.ContainsFunc(c.Nodes, func(id string) bool { return nsOf[id] == namespace }) {
+			continue
+		}
+		out.Cycles = append(out.Cycles, c)
+		out.Summary.Nodes += len(c.Nodes)
+		if c.Critical {
+			out.Summary.CriticalCycles++
+		}
+	}
+	out.Summary.Cycles = len(out.Cycles)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		s.logger.Error("failed to encode cycles", "error", err)
+	}
+}
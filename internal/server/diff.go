@@ -0,0 +1,170 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/export"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// handleTopologyDiff handles GET /api/v1/topology/diff.
+// Query parameters:
+//   - from: RFC3339 timestamp of the baseline topology (required)
+//   - to: RFC3339 timestamp of the compared topology (default: now)
+//   - namespace, group: optional filters applied to both topologies
+//   - format: empty for an inline JSON diff, or json/csv/dot/png/svg for a file download
+//   - scale: PNG scale factor 1-4 (default 2)
+func (s *Server) handleTopologyDiff(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	fromStr := q.Get("from")
+	if fromStr == "" {
+		writeJSONError(w, http.StatusBadRequest, "missing required query parameter: from")
+		return
+	}
+	from, err := time.Parse(time.RFC3339, fromStr)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid from parameter: must be RFC3339 format")
+		return
+	}
+
+	to := time.Now().UTC()
+	if toStr := q.Get("to"); toStr != "" {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid to parameter: must be RFC3339 format")
+			return
+		}
+	}
+
+	if !from.Before(to) {
+		writeJSONError(w, http.StatusBadRequest, "from must be before to")
+		return
+	}
+
+	format := q.Get("format")
+	switch format {
+	case "", "json", "csv", "dot", "png", "svg":
+		// valid
+	default:
+		writeJSONError(w, http.StatusBadRequest, "unsupported export format: "+format)
+		return
+	}
+
+	scale := 2
+	if scaleStr := q.Get("scale"); scaleStr != "" {
+		v, err := strconv.Atoi(scaleStr)
+		if err != nil || v < 1 || v > 4 {
+			writeJSONError(w, http.StatusBadRequest, "scale must be an integer between 1 and 4")
+			return
+		}
+		scale = v
+	}
+
+	namespace := q.Get("namespace")
+	group := q.Get("group")
+
+	// Build both historical topologies concurrently.
+	var (
+		wg               sync.WaitGroup
+		fromResp, toResp *topology.TopologyResponse
+		fromErr, toErr   error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		fromResp, fromErr = s.historicalTopology(r.Context(), topology.QueryOptions{Namespace: namespace, Group: group, Time: &from})
+	}()
+	go func() {
+		defer wg.Done()
+		toResp, toErr = s.historicalTopology(r.Context(), topology.QueryOptions{Namespace: namespace, Group: group, Time: &to})
+	}()
+	wg.Wait()
+
+	if buildErr := firstError(fromErr, toErr); buildErr != nil {
+		s.logger.Error("failed to build topology for diff", "error", buildErr)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+buildErr.Error())
+		return
+	}
+
+	diff := topology.DiffTopologies(fromResp, toResp, from, to)
+
+	if format == "" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(diff); err != nil {
+			s.logger.Error("failed to encode topology diff response", "error", err)
+		}
+		return
+	}
+
+	filters := map[string]string{}
+	if namespace != "" {
+		filters["namespace"] = namespace
+	}
+	if group != "" {
+		filters["group"] = group
+	}
+	data := export.ConvertDiff(diff, filters)
+
+	var output []byte
+	var contentType string
+	var fileExt string
+
+	switch format {
+	case "json":
+		output, err = export.ExportDiffJSON(data)
+		contentType = "application/json"
+		fileExt = "json"
+	case "csv":
+		output, err = export.ExportDiffCSV(data)
+		contentType = "application/zip"
+		fileExt = "zip"
+	case "dot":
+		output, err = export.ExportDiffDOT(data, export.DOTOptions{RankDir: "TB"})
+		contentType = "text/vnd.graphviz"
+		fileExt = "dot"
+	case "png", "svg":
+		if !export.GraphvizAvailable() {
+			writeJSONError(w, http.StatusServiceUnavailable, "Graphviz is not installed on the server")
+			return
+		}
+		dot, dotErr := export.ExportDiffDOT(data, export.DOTOptions{RankDir: "TB"})
+		if dotErr != nil {
+			err = dotErr
+			break
+		}
+		output, err = export.RenderDOT(dot, format, scale)
+		contentType = "image/png"
+		if format == "svg" {
+			contentType = "image/svg+xml"
+		}
+		fileExt = format
+	}
+
+	if err != nil {
+		s.logger.Error("diff export failed", "format", format, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "export failed: "+err.Error())
+		return
+	}
+
+	filename := export.DiffFilename(fileExt)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(output)))
+	_, _ = w.Write(output)
+}
+
+// firstError returns the first non-nil error.
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestTopologyDiffValidation(t *testing.T) {
+	srv := newTestServer()
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"missing from", ""},
+		{"invalid from", "?from=yesterday"},
+		{"invalid to", "?from=2026-01-15T12:00:00Z&to=now"},
+		{"from after to", "?from=2026-01-15T12:00:00Z&to=2026-01-15T11:00:00Z"},
+		{"unsupported format", "?from=2026-01-15T12:00:00Z&format=xml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/topology/diff"+tt.query, nil)
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestTopologyDiff(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/topology/diff?from=2026-01-15T12:00:00Z&to=2026-01-15T13:00:00Z", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var diff topology.TopologyDiff
+	if err := json.NewDecoder(w.Body).Decode(&diff); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if diff.From.Format("15:04") != "12:00" || diff.To.Format("15:04") != "13:00" {
+		t.Errorf("From/To = %v/%v", diff.From, diff.To)
+	}
+	// The test Prometheus returns the same data regardless of time.
+	if diff.Summary != (topology.DiffSummary{}) {
+		t.Errorf("Summary = %+v, want no changes", diff.Summary)
+	}
+}
+
+func TestTopologyDiffExportCSV(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/topology/diff?from=2026-01-15T12:00:00Z&format=csv", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); cd == "" {
+		t.Error("missing Content-Disposition header")
+	}
+}
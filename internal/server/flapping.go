@@ -0,0 +1,61 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+)
+
+// handleFlapping handles GET /api/v1/flapping.
+// Query parameters:
+//   - time: optional RFC3339 end of the flap window (default: now)
+//   - namespace: optional namespace filter
+//   - window: optional override of topology.flapping.window
+func (s *Server) handleFlapping(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := timeline.FlappingRequest{
+		Time:      time.Now(),
+		Namespace: q.Get("namespace"),
+		Config:    s.cfg.Topology.Flapping,
+	}
+
+	if ts := q.Get("time"); ts != "" {
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid time parameter: must be RFC3339 format")
+			return
+		}
+		req.Time = t
+	}
+	if ws := q.Get("window"); ws != "" {
+		d, err := time.ParseDuration(ws)
+		if err != nil || d < time.Minute || d > 12*time.Hour {
+			writeJSONError(w, http.StatusBadRequest, "invalid window parameter: must be a duration between 1m and 12h")
+			return
+		}
+		req.Config.Window = d
+		// The thresholds are only validated at startup with a window set.
+		if err := req.Config.ValidateThresholds(); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid flapping thresholds for window: "+err.Error())
+			return
+		}
+	}
+	if req.Config.Window == 0 {
+		writeJSONError(w, http.StatusBadRequest, "flap detection is disabled: set topology.flapping.window or pass window")
+		return
+	}
+
+	edges, err := s.queryFlapping(r.Context(), req)
+	if err != nil {
+		s.logger.Error("failed to detect flapping edges", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to detect flapping edges: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(edges); err != nil {
+		s.logger.Error("failed to encode flapping edges", "error", err)
+	}
+}
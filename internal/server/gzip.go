@@ -51,3 +51,12 @@ func (g *gzipResponseWriter) WriteHeader(statusCode int) {
 	g.ResponseWriter.Header().Del("Content-Length")
 	g.ResponseWriter.WriteHeader(statusCode)
 }
+
+// Flush writes buffered compressed data to the client.
+// Required for streaming responses such as Server-Sent Events.
+func (g *gzipResponseWriter) Flush() {
+	_ = g.writer.Flush()
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
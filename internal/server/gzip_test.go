@@ -74,3 +74,24 @@ func TestGzipMiddleware_WithoutAcceptEncoding(t *testing.T) {
 		t.Errorf("unexpected body: %s", body)
 	}
 }
+
+func TestGzipMiddleware_Flush(t *testing.T) {
+	handler := gzipMiddleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("event: snapshot\ndata: {}\n\n"))
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("gzip response writer does not implement http.Flusher")
+		}
+		f.Flush()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if !rec.Flushed {
+		t.Error("expected underlying writer to be flushed")
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// maxIncidentAnalyses caps the number of historical topology builds per
+// incidents request. Each build issues a full set of Prometheus queries, so
+// only the most recent incidents get root cause analysis.
+const maxIncidentAnalyses = 20
+
+// handleIncidents handles GET /api/v1/incidents.
+// Clusters overlapping edge outages in the range into incidents and attaches
+// the probable root cause from a cascade analysis of the topology at the
+// start of each incident.
+func (s *Server) handleIncidents(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseEventsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	gap := timeline.DefaultIncidentGap
+	if g := r.URL.Query().Get("gap"); g != "" {
+		d, err := time.ParseDuration(g)
+		if err != nil || d < 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid gap parameter: must be a non-negative duration (e.g. \"5m\")")
+			return
+		}
+		gap = d
+	}
+
+	outages, err := s.queryOutages(r.Context(), req)
+	if err != nil {
+		s.logger.Error("failed to query outages for incidents", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch incidents: "+err.Error())
+		return
+	}
+
+	incidents := timeline.ClusterOutages(outages, gap)
+	for i := len(incidents) - 1; i >= 0; i-- {
+		inc := &incidents[i]
+		if len(incidents)-i > maxIncidentAnalyses {
+			inc.AnalysisError = fmt.Sprintf("root cause analysis is limited to the %d most recent incidents", maxIncidentAnalyses)
+			continue
+		}
+		at := inc.Start
+		resp, err := s.historicalTopology(r.Context(), topology.QueryOptions{Namespace: req.Namespace, Time: &at})
+		if err != nil {
+			s.logger.Warn("failed to build topology for incident", "incident", inc.ID, "error", err)
+			inc.AnalysisError = "failed to fetch topology data: " + err.Error()
+			continue
+		}
+		inc.AttachCascade(cascade.Analyze(resp.Nodes, resp.Edges, cascade.Options{Namespace: req.Namespace}), resp.Nodes, resp.Edges)
+	}
+
+	if incidents == nil {
+		incidents = []timeline.Incident{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(incidents); err != nil {
+		s.logger.Error("failed to encode incidents response", "error", err)
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"github.com/BigKAA/dephealth-ui/internal/notify"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// SetNotifier enables webhook notifications: every background-built topology
+// is compared with the previous one and the transitions are delivered by n.
+// Deliveries run while Run is active.
+func (s *Server) SetNotifier(n *notify.Notifier) {
+	s.notifier = n
+}
+
+// notifyTransitions passes a background-built topology to the notifier.
+func (s *Server) notifyTransitions(resp *topology.TopologyResponse) {
+	if s.notifier == nil {
+		return
+	}
+	s.notifier.Observe(resp)
+}
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cache"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// refreshTimeout bounds a single background topology build.
+const refreshTimeout = 30 * time.Second
+
+// buildCall is an in-flight topology build shared by concurrent callers.
+type buildCall struct {
+	done chan struct{}
+	seq  uint64 // order in which the builds started, see notify
+	resp *topology.TopologyResponse
+	err  error
+}
+
+// refresher keeps the unfiltered live topology in the cache.
+// It rebuilds on a fixed interval in the background, deduplicates concurrent
+// builds so that cache misses never stampede Prometheus, and falls back to
+// last-known-good data (marked stale) when a rebuild fails.
+type refresher struct {
+	builder  *topology.GraphBuilder
+	cache    *cache.Cache
+	interval time.Duration
+	logger   *slog.Logger
+	// tenant is the tenant whose topology is kept (empty for the defaults).
+	tenant string
+	// onUpdate, when set, receives every successfully built response and,
+	// after a failed rebuild, the last-known-good response marked stale.
+	onUpdate func(*topology.TopologyResponse)
+
+	mu       sync.Mutex
+	inflight *buildCall
+	builds   uint64    // number of builds started
+	failedAt time.Time // time of the last failed build; zero after a success
+	lastErr  error
+
+	notifyMu sync.Mutex
+	notified uint64 // seq of the last build passed to onUpdate
+}
+
+func newRefresher(builder *topology.GraphBuilder, c *cache.Cache, interval time.Duration, logger *slog.Logger) *refresher {
+	return &refresher{
+		builder:  builder,
+		cache:    c,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// run rebuilds the topology immediately and then on every tick until ctx is cancelled.
+func (r *refresher) run(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.refresh(ctx); err != nil {
+			r.logger.Warn("background topology refresh failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh rebuilds the unfiltered topology and stores it in the cache.
+// Concurrent callers share a single build. The build runs detached from the
+// caller's cancellation so that one aborted request does not fail the others.
+// The callers waiting on the build are released before onUpdate runs, which
+// only delays the caller that started it.
+func (r *refresher) refresh(ctx context.Context) (*topology.TopologyResponse, error) {
+	r.mu.Lock()
+	if c := r.inflight; c != nil {
+		r.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.resp, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	r.builds++
+	c := &buildCall{done: make(chan struct{}), seq: r.builds}
+	r.inflight = c
+	r.mu.Unlock()
+
+	buildCtx, cancel := context.WithTimeout(topology.WithTenant(context.WithoutCancel(ctx), r.tenant), refreshTimeout)
+	c.resp, c.err = r.builder.Build(buildCtx, topology.QueryOptions{})
+	cancel()
+
+	if c.err == nil {
+		r.cache.Set(c.resp)
+	}
+
+	r.mu.Lock()
+	r.inflight = nil
+	if c.err != nil {
+		r.failedAt = time.Now()
+		r.lastErr = c.err
+	} else {
+		r.failedAt = time.Time{}
+		r.lastErr = nil
+	}
+	r.mu.Unlock()
+	close(c.done)
+
+	r.notify(c.seq, c.resp, c.err)
+	return c.resp, c.err
+}
+
+// notify passes the outcome of build seq to onUpdate. Builds are notified
+// in the order they started: the outcome of a build overtaken by a later
+// one is dropped.
+func (r *refresher) notify(seq uint64, resp *topology.TopologyResponse, err error) {
+	if r.onUpdate == nil {
+		return
+	}
+	r.notifyMu.Lock()
+	defer r.notifyMu.Unlock()
+	if seq <= r.notified {
+		return
+	}
+	r.notified = seq
+	if err == nil {
+		r.onUpdate(resp)
+		return
+	}
+	if last, _, ok := r.cache.GetLastKnown(); ok {
+		r.onUpdate(markStale(last, err))
+	}
+}
+
+// get returns the unfiltered live topology and its ETag.
+// Fresh cached data is returned as is. On a cache miss the topology is rebuilt
+// (shared with any concurrent build). When the rebuild fails, or failed
+// recently, the last-known-good response is returned with Meta.Stale set and
+// an ETag of its own, so that clients revalidating the fresh payload see it.
+func (r *refresher) get(ctx context.Context) (*topology.TopologyResponse, string, error) {
+	if cached, etag, ok := r.cache.GetWithETag(); ok {
+		return cached, etag, nil
+	}
+
+	err := r.recentFailure()
+	if err == nil {
+		if _, err = r.refresh(ctx); err == nil {
+			if resp, etag, ok := r.cache.GetLastKnown(); ok {
+				return resp, etag, nil
+			}
+		}
+	}
+
+	last, etag, ok := r.cache.GetLastKnown()
+	if !ok {
+		return nil, "", err
+	}
+	return markStale(last, err), staleETag(etag), nil
+}
+
+// staleETag derives the ETag of the stale variant of the payload tagged etag.
+func staleETag(etag string) string {
+	return strings.TrimSuffix(etag, `"`) + `-stale"`
+}
+
+// recentFailure returns the last build error if it happened less than one
+// refresh interval ago, so that an unavailable Prometheus is not retried on
+// every request.
+func (r *refresher) recentFailure() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastErr == nil || r.interval <= 0 || time.Since(r.failedAt) >= r.interval {
+		return nil
+	}
+	return r.lastErr
+}
+
+// markStale returns a shallow copy of resp flagged as stale with the rebuild error recorded.
+func markStale(resp *topology.TopologyResponse, err error) *topology.TopologyResponse {
+	stale := *resp
+	stale.Meta.Stale = true
+	stale.Meta.Partial = true
+	stale.Meta.Errors = append(append([]string(nil), resp.Meta.Errors...), fmt.Sprintf("refresh: %v", err))
+	return &stale
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cache"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// newFlakyPromServer returns a Prometheus stub that counts requests and
+// answers with 503 while fail is set. Each response is delayed so that
+// concurrent callers overlap.
+func newFlakyPromServer(requests *atomic.Int32, fail *atomic.Bool, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		time.Sleep(delay)
+		if fail.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"name":"svc-go","dependency":"postgres","type":"postgres","host":"pg","port":"5432"},"value":[1700000000,"1"]}
+		]}}`))
+	}))
+}
+
+func newTestRefresher(promURL string, ttl, interval time.Duration) *refresher {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	promClient := topology.NewPrometheusClient(topology.PrometheusConfig{URL: promURL})
+	builder := topology.NewGraphBuilder(promClient, nil, topology.GrafanaConfig{}, ttl, 0, logger, nil)
+	return newRefresher(builder, cache.New(ttl), interval, logger)
+}
+
+func TestRefresherDeduplicatesConcurrentBuilds(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	promSrv := newFlakyPromServer(&requests, &fail, 20*time.Millisecond)
+	defer promSrv.Close()
+
+	r := newTestRefresher(promSrv.URL, time.Minute, time.Minute)
+
+	// Measure how many requests a single Build issues.
+	if _, err := r.builder.Build(context.Background(), topology.QueryOptions{}); err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	perBuild := requests.Swap(0)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := r.get(context.Background()); err != nil {
+				t.Errorf("get() error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	single := requests.Load()
+	if single == 0 || single > perBuild {
+		t.Errorf("prometheus requests = %d, want at most %d (one build)", single, perBuild)
+	}
+
+	// Subsequent gets are served from cache.
+	if _, _, err := r.get(context.Background()); err != nil {
+		t.Fatalf("get() error: %v", err)
+	}
+	if got := requests.Load(); got != single {
+		t.Errorf("prometheus requests after cached get = %d, want %d", got, single)
+	}
+}
+
+func TestRefresherReleasesWaitersBeforeUpdate(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	promSrv := newFlakyPromServer(&requests, &fail, 100*time.Millisecond)
+	defer promSrv.Close()
+
+	r := newTestRefresher(promSrv.URL, time.Minute, time.Minute)
+	updating := make(chan struct{})
+	release := make(chan struct{})
+	r.onUpdate = func(*topology.TopologyResponse) {
+		close(updating)
+		<-release
+	}
+
+	go func() { _, _ = r.refresh(context.Background()) }()
+	// Join the build while it is in flight.
+	for {
+		r.mu.Lock()
+		inflight := r.inflight
+		r.mu.Unlock()
+		if inflight != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.refresh(context.Background())
+		done <- err
+	}()
+
+	<-updating
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("refresh() error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("waiting caller was held until the update hook finished")
+	}
+	close(release)
+}
+
+func TestRefresherServesStaleOnFailure(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	promSrv := newFlakyPromServer(&requests, &fail, 0)
+	defer promSrv.Close()
+
+	r := newTestRefresher(promSrv.URL, time.Millisecond, time.Minute)
+
+	fresh, etag, err := r.get(context.Background())
+	if err != nil {
+		t.Fatalf("initial get() error: %v", err)
+	}
+	if fresh.Meta.Stale {
+		t.Error("fresh response should not be stale")
+	}
+
+	fail.Store(true)
+	time.Sleep(5 * time.Millisecond) // let the cache entry expire
+
+	stale, staleETag, err := r.get(context.Background())
+	if err != nil {
+		t.Fatalf("get() after failure error: %v", err)
+	}
+	if !stale.Meta.Stale {
+		t.Error("expected Meta.Stale=true after failed rebuild")
+	}
+	if !stale.Meta.Partial || len(stale.Meta.Errors) == 0 {
+		t.Errorf("expected partial response with errors, got %+v", stale.Meta)
+	}
+	if want := strings.TrimSuffix(etag, `"`) + `-stale"`; staleETag != want {
+		t.Errorf("stale ETag = %q, want %q", staleETag, want)
+	}
+	if len(stale.Nodes) != len(fresh.Nodes) {
+		t.Errorf("stale nodes = %d, want %d", len(stale.Nodes), len(fresh.Nodes))
+	}
+	if fresh.Meta.Stale {
+		t.Error("marking stale must not mutate the cached response")
+	}
+
+	// A recent failure is not retried until the refresh interval elapses.
+	before := requests.Load()
+	if _, _, err := r.get(context.Background()); err != nil {
+		t.Fatalf("get() error: %v", err)
+	}
+	if got := requests.Load(); got != before {
+		t.Errorf("prometheus requests = %d, want %d (no retry within interval)", got, before)
+	}
+}
+
+func TestRefresherErrorWithoutLastKnown(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	fail.Store(true)
+	promSrv := newFlakyPromServer(&requests, &fail, 0)
+	defer promSrv.Close()
+
+	r := newTestRefresher(promSrv.URL, time.Minute, time.Minute)
+
+	if _, _, err := r.get(context.Background()); err == nil {
+		t.Error("expected error when no last-known-good data exists")
+	}
+}
+
+func TestRefresherRunPopulatesCache(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	promSrv := newFlakyPromServer(&requests, &fail, 0)
+	defer promSrv.Close()
+
+	r := newTestRefresher(promSrv.URL, time.Minute, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.run(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := r.cache.Get(); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background refresher did not populate the cache")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("refresher did not stop after context cancellation")
+	}
+}
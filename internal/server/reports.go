@@ -0,0 +1,90 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/BigKAA/dephealth-ui/internal/export"
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+)
+
+// handleAvailabilityReport handles GET /api/v1/reports/availability.
+// Query parameters:
+//   - start, end: RFC3339 range (required)
+//   - namespace: optional namespace filter
+//   - criticalWeight: optional weight of critical edges in the service score
+//   - format: empty for an inline JSON report, or json/csv for a file download
+func (s *Server) handleAvailabilityReport(w http.ResponseWriter, r *http.Request) {
+	ev, ok := parseEventsRequest(w, r)
+	if !ok {
+		return
+	}
+	req := timeline.AvailabilityRequest{EventsRequest: ev}
+
+	q := r.URL.Query()
+	if cw := q.Get("criticalWeight"); cw != "" {
+		v, err := strconv.ParseFloat(cw, 64)
+		if err != nil || v <= 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid criticalWeight parameter: must be a positive number")
+			return
+		}
+		req.CriticalWeight = v
+	}
+
+	format := q.Get("format")
+	switch format {
+	case "", "json", "csv":
+	default:
+		writeJSONError(w, http.StatusBadRequest, "unsupported report format: "+format)
+		return
+	}
+
+	report, err := s.queryAvailability(r.Context(), req)
+	if err != nil {
+		s.logger.Error("failed to compute availability report", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to compute availability report: "+err.Error())
+		return
+	}
+
+	if format == "" {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			s.logger.Error("failed to encode availability report", "error", err)
+		}
+		return
+	}
+
+	filters := map[string]string{}
+	if ev.Namespace != "" {
+		filters["namespace"] = ev.Namespace
+	}
+	data := export.ConvertAvailability(report, filters)
+
+	var output []byte
+	var contentType string
+	var fileExt string
+
+	switch format {
+	case "json":
+		output, err = export.ExportAvailabilityJSON(data)
+		contentType = "application/json"
+		fileExt = "json"
+	case "csv":
+		output, err = export.ExportAvailabilityCSV(data)
+		contentType = "application/zip"
+		fileExt = "zip"
+	}
+
+	if err != nil {
+		s.logger.Error("availability export failed", "format", format, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "export failed: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.AvailabilityFilename(fileExt)))
+	w.Header().Set("Content-Length", strconv.Itoa(len(output)))
+	_, _ = w.Write(output)
+}
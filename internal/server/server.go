@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -19,6 +21,8 @@ import (
 	"github.com/BigKAA/dephealth-ui/internal/cascade"
 	"github.com/BigKAA/dephealth-ui/internal/config"
 	"github.com/BigKAA/dephealth-ui/internal/logging"
+	"github.com/BigKAA/dephealth-ui/internal/notify"
+	"github.com/BigKAA/dephealth-ui/internal/snapshot"
 	"github.com/BigKAA/dephealth-ui/internal/timeline"
 	"github.com/BigKAA/dephealth-ui/internal/topology"
 )
@@ -29,23 +33,41 @@ type Server struct {
 	logger  *slog.Logger
 	router  *chi.Mux
 	builder *topology.GraphBuilder
-	prom    topology.PrometheusClient
 	am      alerts.AlertManagerClient
 	cache   *cache.Cache
 	auth    auth.Authenticator
+
+	refresher *refresher
+	hub       *topologyHub
+	tenantsMu sync.Mutex
+	tenants   map[string]*refresher // live topology per selected tenant, see live
+	snapshots *snapshot.Store       // nil when the snapshot store is disabled
+	notifier  *notify.Notifier      // nil when no webhooks are configured
 }
 
 // New creates a new Server instance with configured routes and middleware.
-func New(cfg *config.Config, logger *slog.Logger, builder *topology.GraphBuilder, prom topology.PrometheusClient, am alerts.AlertManagerClient, c *cache.Cache, authenticator auth.Authenticator) *Server {
+func New(cfg *config.Config, logger *slog.Logger, builder *topology.GraphBuilder, am alerts.AlertManagerClient, c *cache.Cache, authenticator auth.Authenticator) *Server {
 	s := &Server{
 		cfg:     cfg,
 		logger:  logger,
 		router:  chi.NewRouter(),
 		builder: builder,
-		prom:    prom,
 		am:      am,
 		cache:   c,
 		auth:    authenticator,
+		tenants: make(map[string]*refresher),
+	}
+
+	interval := cfg.Cache.RefreshInterval
+	if interval == 0 {
+		interval = cfg.Cache.TTL
+	}
+	s.hub = newTopologyHub()
+	s.refresher = newRefresher(builder, c, interval, logger)
+	s.refresher.onUpdate = func(resp *topology.TopologyResponse) {
+		s.hub.publish(resp)
+		s.saveSnapshot(resp)
+		s.notifyTransitions(resp)
 	}
 
 	s.setupMiddleware()
@@ -54,8 +76,14 @@ func New(cfg *config.Config, logger *slog.Logger, builder *topology.GraphBuilder
 	return s
 }
 
-// Run starts the HTTP server and blocks until the context is cancelled.
+// Run starts the HTTP server, the background topology refresher and the
+// webhook notifier, and blocks until the context is cancelled.
 func (s *Server) Run(ctx context.Context) error {
+	go s.refresher.run(ctx)
+	if s.notifier != nil {
+		go s.notifier.Run(ctx)
+	}
+
 	srv := &http.Server{
 		Addr:              s.cfg.Server.Listen,
 		Handler:           s.router,
@@ -93,7 +121,7 @@ func (s *Server) setupMiddleware() {
 	s.router.Use(gzipMiddleware)
 	s.router.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Content-Type", "If-None-Match"},
 		AllowCredentials: false,
 		MaxAge:           300,
@@ -116,12 +144,25 @@ func (s *Server) setupRoutes() {
 	// API v1 (requires auth)
 	s.router.Route("/api/v1", func(r chi.Router) {
 		r.Use(s.auth.Middleware())
+		r.Use(s.tenantMiddleware)
 		r.Get("/topology", s.handleTopology)
+		r.Get("/topology/stream", s.handleTopologyStream)
+		r.Get("/topology/diff", s.handleTopologyDiff)
 		r.Get("/alerts", s.handleAlerts)
+		r.Get("/silences", s.handleListSilences)
+		r.Post("/silences", s.handleCreateSilence)
+		r.Delete("/silences/{id}", s.handleExpireSilence)
 		r.Get("/instances", s.handleInstances)
 		r.Get("/cascade-analysis", s.handleCascadeAnalysis)
 		r.Get("/cascade-graph", s.handleCascadeGraph)
+		r.Post("/cascade/simulate", s.handleCascadeSimulate)
+		r.Get("/cascade/spof", s.handleCascadeSPOF)
+		r.Get("/cycles", s.handleCycles)
 		r.Get("/timeline/events", s.handleTimelineEvents)
+		r.Get("/timeline/outages", s.handleTimelineOutages)
+		r.Get("/incidents", s.handleIncidents)
+		r.Get("/reports/availability", s.handleAvailabilityReport)
+		r.Get("/flapping", s.handleFlapping)
 		r.Get("/export/{format}", s.handleExport)
 	})
 
@@ -135,11 +176,30 @@ func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
 	_, _ = fmt.Fprint(w, `{"status":"ok"}`)
 }
 
-func (s *Server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
-	// TODO: check datasource connectivity
+// handleReadyz reports not ready (503) while the circuit breaker of every
+// Prometheus source is open, i.e. no topology can currently be built.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	resp := struct {
+		Status      string                      `json:"status"`
+		Datasources []topology.DatasourceStatus `json:"datasources,omitempty"`
+	}{Status: "ok"}
+	status := http.StatusOK
+	if s.builder != nil {
+		resp.Datasources = s.builder.Datasources(r.Context())
+		open := 0
+		for _, ds := range resp.Datasources {
+			if ds.Circuit == topology.CircuitOpen {
+				open++
+			}
+		}
+		if open > 0 && open == len(resp.Datasources) {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		}
+	}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprint(w, `{"status":"ok"}`)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
 }
 
 func (s *Server) handleTopology(w http.ResponseWriter, r *http.Request) {
@@ -151,46 +211,40 @@ func (s *Server) handleTopology(w http.ResponseWriter, r *http.Request) {
 	if timeStr := r.URL.Query().Get("time"); timeStr != "" {
 		t, err := time.Parse(time.RFC3339, timeStr)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = fmt.Fprintf(w, `{"error":"invalid time parameter: must be RFC3339 format"}`)
+			writeJSONError(w, http.StatusBadRequest, "invalid time parameter: must be RFC3339 format")
 			return
 		}
 		opts.Time = &t
 	}
 
-	// Historical and filtered requests bypass cache entirely.
+	// Unfiltered live requests are served by the refresher (cache-backed);
+	// historical and filtered requests bypass the cache entirely.
 	if opts.Time == nil && namespace == "" && group == "" {
-		if cached, etag, ok := s.cache.GetWithETag(); ok {
-			if clientETag := r.Header.Get("If-None-Match"); clientETag == etag {
-				w.WriteHeader(http.StatusNotModified)
-				return
-			}
-			w.Header().Set("Content-Type", "application/json")
-			w.Header().Set("ETag", etag)
-			if err := json.NewEncoder(w).Encode(cached); err != nil {
-				s.logger.Error("failed to encode cached topology response", "error", err)
-			}
+		cached, etag, err := s.live(r.Context()).get(r.Context())
+		if err != nil {
+			s.logger.Error("failed to build topology", "error", err)
+			writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
 			return
 		}
+		if clientETag := r.Header.Get("If-None-Match"); clientETag == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		if err := json.NewEncoder(w).Encode(cached); err != nil {
+			s.logger.Error("failed to encode cached topology response", "error", err)
+		}
+		return
 	}
 
-	resp, err := s.builder.Build(r.Context(), opts)
+	resp, err := s.historicalTopology(r.Context(), opts)
 	if err != nil {
 		s.logger.Error("failed to build topology", "error", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		_, _ = fmt.Fprintf(w, `{"error":"failed to fetch topology data: %s"}`, err.Error())
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
 		return
 	}
 
-	// Only cache unfiltered live requests.
-	if opts.Time == nil && namespace == "" && group == "" {
-		s.cache.Set(resp)
-		_, etag, _ := s.cache.GetWithETag()
-		w.Header().Set("ETag", etag)
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		s.logger.Error("failed to encode topology response", "error", err)
@@ -204,25 +258,35 @@ type alertsResponse struct {
 }
 
 type alertsMeta struct {
-	Total    int    `json:"total"`
-	Critical int    `json:"critical"`
-	Warning  int    `json:"warning"`
-	FetchedAt string `json:"fetchedAt"`
+	Total        int      `json:"total"`
+	Critical     int      `json:"critical"`
+	Warning      int      `json:"warning"`
+	Acknowledged int      `json:"acknowledged"` // silenced or inhibited; not in critical/warning
+	FetchedAt    string   `json:"fetchedAt"`
+	Errors       []string `json:"errors,omitempty"` // AlertManager endpoints that failed
 }
 
 func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	if s.am == nil {
 		w.Header().Set("Content-Type", "application/json")
-		_, _ = fmt.Fprint(w, `{"alerts":[],"meta":{"total":0,"critical":0,"warning":0,"fetchedAt":""}}`)
+		_, _ = fmt.Fprint(w, `{"alerts":[],"meta":{"total":0,"critical":0,"warning":0,"acknowledged":0,"fetchedAt":""}}`)
 		return
 	}
 
 	fetched, err := s.am.FetchAlerts(r.Context())
+	var partial *alerts.PartialError
+	var endpointErrors []string
+	if errors.As(err, &partial) {
+		// Serve what the reachable endpoints returned.
+		s.logger.Warn("some alertmanager endpoints failed", "error", err)
+		for _, ep := range partial.Endpoints {
+			endpointErrors = append(endpointErrors, ep.Error())
+		}
+		err = nil
+	}
 	if err != nil {
 		s.logger.Error("failed to fetch alerts", "error", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		_, _ = fmt.Fprintf(w, `{"error":"failed to fetch alerts: %s"}`, err.Error())
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch alerts: "+err.Error())
 		return
 	}
 
@@ -230,8 +294,12 @@ func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 		fetched = []alerts.Alert{}
 	}
 
-	var critical, warning int
+	var critical, warning, acknowledged int
 	for _, a := range fetched {
+		if a.Acknowledged() {
+			acknowledged++
+			continue
+		}
 		switch a.Severity {
 		case "critical":
 			critical++
@@ -243,10 +311,12 @@ func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 	resp := alertsResponse{
 		Alerts: fetched,
 		Meta: alertsMeta{
-			Total:     len(fetched),
-			Critical:  critical,
-			Warning:   warning,
-			FetchedAt: time.Now().UTC().Format(time.RFC3339),
+			Total:        len(fetched),
+			Critical:     critical,
+			Warning:      warning,
+			Acknowledged: acknowledged,
+			FetchedAt:    time.Now().UTC().Format(time.RFC3339),
+			Errors:       endpointErrors,
 		},
 	}
 
@@ -260,18 +330,14 @@ func (s *Server) handleAlerts(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleInstances(w http.ResponseWriter, r *http.Request) {
 	serviceName := r.URL.Query().Get("service")
 	if serviceName == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprint(w, `{"error":"missing required query parameter: service"}`)
+		writeJSONError(w, http.StatusBadRequest, "missing required query parameter: service")
 		return
 	}
 
 	instances, err := s.builder.QueryInstances(r.Context(), serviceName)
 	if err != nil {
 		s.logger.Error("failed to fetch instances", "error", err, "service", serviceName)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		_, _ = fmt.Fprintf(w, `{"error":"failed to fetch instances: %s"}`, err.Error())
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch instances: "+err.Error())
 		return
 	}
 
@@ -294,7 +360,7 @@ type configResponse struct {
 }
 
 type configAlerts struct {
-	Enabled        bool                  `json:"enabled"`
+	Enabled        bool                   `json:"enabled"`
 	SeverityLevels []config.SeverityLevel `json:"severityLevels"`
 }
 
@@ -303,16 +369,16 @@ type configAuth struct {
 }
 
 type configGrafana struct {
-	BaseURL    string            `json:"baseUrl"`
-	Dashboards configDashboards  `json:"dashboards"`
+	BaseURL    string           `json:"baseUrl"`
+	Dashboards configDashboards `json:"dashboards"`
 }
 
 type configDashboards struct {
-	ServiceStatus   string `json:"serviceStatus"`
-	LinkStatus      string `json:"linkStatus"`
-	ServiceList     string `json:"serviceList"`
-	ServicesStatus  string `json:"servicesStatus"`
-	LinksStatus     string `json:"linksStatus"`
+	ServiceStatus         string `json:"serviceStatus"`
+	LinkStatus            string `json:"linkStatus"`
+	ServiceList           string `json:"serviceList"`
+	ServicesStatus        string `json:"servicesStatus"`
+	LinksStatus           string `json:"linksStatus"`
 	CascadeOverview       string `json:"cascadeOverview"`
 	RootCause             string `json:"rootCause"`
 	ConnectionDiagnostics string `json:"connectionDiagnostics"`
@@ -327,11 +393,11 @@ func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
 		Grafana: configGrafana{
 			BaseURL: s.cfg.Grafana.BaseURL,
 			Dashboards: configDashboards{
-				ServiceStatus:   s.cfg.Grafana.Dashboards.ServiceStatus,
-				LinkStatus:      s.cfg.Grafana.Dashboards.LinkStatus,
-				ServiceList:     s.cfg.Grafana.Dashboards.ServiceList,
-				ServicesStatus:  s.cfg.Grafana.Dashboards.ServicesStatus,
-				LinksStatus:     s.cfg.Grafana.Dashboards.LinksStatus,
+				ServiceStatus:         s.cfg.Grafana.Dashboards.ServiceStatus,
+				LinkStatus:            s.cfg.Grafana.Dashboards.LinkStatus,
+				ServiceList:           s.cfg.Grafana.Dashboards.ServiceList,
+				ServicesStatus:        s.cfg.Grafana.Dashboards.ServicesStatus,
+				LinksStatus:           s.cfg.Grafana.Dashboards.LinksStatus,
 				CascadeOverview:       s.cfg.Grafana.Dashboards.CascadeOverview,
 				RootCause:             s.cfg.Grafana.Dashboards.RootCause,
 				ConnectionDiagnostics: s.cfg.Grafana.Dashboards.ConnectionDiagnostics,
@@ -344,7 +410,7 @@ func (s *Server) handleConfig(w http.ResponseWriter, _ *http.Request) {
 			Type: s.cfg.Auth.Type,
 		},
 		Alerts: configAlerts{
-			Enabled:        s.cfg.Datasources.Alertmanager.URL != "",
+			Enabled:        len(s.cfg.Datasources.Alertmanager.Endpoints()) > 0,
 			SeverityLevels: s.cfg.Alerts.SeverityLevels,
 		},
 	}
@@ -362,11 +428,25 @@ func (s *Server) handleCascadeAnalysis(w http.ResponseWriter, r *http.Request) {
 	maxDepth := 0
 	if d := r.URL.Query().Get("depth"); d != "" {
 		if _, err := fmt.Sscanf(d, "%d", &maxDepth); err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = fmt.Fprint(w, `{"error":"invalid depth parameter: must be an integer"}`)
+			writeJSONError(w, http.StatusBadRequest, "invalid depth parameter: must be an integer")
+			return
+		}
+	}
+
+	mode := r.URL.Query().Get("mode")
+	if mode != "" && mode != cascade.ModeStrict && mode != cascade.ModeWeighted {
+		writeJSONError(w, http.StatusBadRequest, "invalid mode parameter: must be strict or weighted")
+		return
+	}
+
+	var nonCriticalWeight *float64
+	if v := r.URL.Query().Get("nonCriticalWeight"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			writeJSONError(w, http.StatusBadRequest, "invalid nonCriticalWeight parameter: must be a number between 0 and 1")
 			return
 		}
+		nonCriticalWeight = &f
 	}
 
 	// Parse optional ?time= parameter for historical queries.
@@ -374,9 +454,7 @@ func (s *Server) handleCascadeAnalysis(w http.ResponseWriter, r *http.Request) {
 	if timeStr := r.URL.Query().Get("time"); timeStr != "" {
 		t, err := time.Parse(time.RFC3339, timeStr)
 		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = fmt.Fprintf(w, `{"error":"invalid time parameter: must be RFC3339 format"}`)
+			writeJSONError(w, http.StatusBadRequest, "invalid time parameter: must be RFC3339 format")
 			return
 		}
 		queryTime = &t
@@ -390,36 +468,30 @@ func (s *Server) handleCascadeAnalysis(w http.ResponseWriter, r *http.Request) {
 	if queryTime != nil {
 		// History mode: always build fresh with historical timestamp.
 		opts := topology.QueryOptions{Namespace: namespace, Time: queryTime}
-		resp, err := s.builder.Build(r.Context(), opts)
+		resp, err := s.historicalTopology(r.Context(), opts)
 		if err != nil {
 			s.logger.Error("failed to build historical topology for cascade analysis", "error", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			_, _ = fmt.Fprintf(w, `{"error":"failed to fetch topology data: %s"}`, err.Error())
+			writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
 			return
 		}
 		nodes = resp.Nodes
 		edges = resp.Edges
-	} else if cached, ok := s.cache.Get(); ok {
-		nodes = cached.Nodes
-		edges = cached.Edges
 	} else {
-		resp, err := s.builder.Build(r.Context(), topology.QueryOptions{})
+		resp, _, err := s.live(r.Context()).get(r.Context())
 		if err != nil {
 			s.logger.Error("failed to build topology for cascade analysis", "error", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			_, _ = fmt.Fprintf(w, `{"error":"failed to fetch topology data: %s"}`, err.Error())
+			writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
 			return
 		}
-		s.cache.Set(resp)
 		nodes = resp.Nodes
 		edges = resp.Edges
 	}
 
 	opts := cascade.Options{
-		MaxDepth:  maxDepth,
-		Namespace: namespace,
+		MaxDepth:          maxDepth,
+		Namespace:         namespace,
+		Mode:              mode,
+		NonCriticalWeight: nonCriticalWeight,
 	}
 
 	var result *cascade.AnalysisResult
@@ -468,33 +540,23 @@ func (s *Server) handleCascadeGraph(w http.ResponseWriter, r *http.Request) {
 	maxDepth := 0
 	if d := r.URL.Query().Get("depth"); d != "" {
 		if _, err := fmt.Sscanf(d, "%d", &maxDepth); err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
-			_, _ = fmt.Fprint(w, `{"error":"invalid depth parameter: must be an integer"}`)
+			writeJSONError(w, http.StatusBadRequest, "invalid depth parameter: must be an integer")
 			return
 		}
 	}
 
-	// Get topology data from cache or build fresh.
+	// Get topology data from the refresher (cache or shared rebuild).
 	var topoNodes []topology.Node
 	var topoEdges []topology.Edge
 
-	if cached, ok := s.cache.Get(); ok {
-		topoNodes = cached.Nodes
-		topoEdges = cached.Edges
-	} else {
-		resp, err := s.builder.Build(r.Context(), topology.QueryOptions{})
-		if err != nil {
-			s.logger.Error("failed to build topology for cascade graph", "error", err)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadGateway)
-			_, _ = fmt.Fprintf(w, `{"error":"failed to fetch topology data: %s"}`, err.Error())
-			return
-		}
-		s.cache.Set(resp)
-		topoNodes = resp.Nodes
-		topoEdges = resp.Edges
+	topo, _, err := s.live(r.Context()).get(r.Context())
+	if err != nil {
+		s.logger.Error("failed to build topology for cascade graph", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
+		return
 	}
+	topoNodes = topo.Nodes
+	topoEdges = topo.Edges
 
 	opts := cascade.Options{
 		MaxDepth:  maxDepth,
@@ -616,54 +678,51 @@ func (s *Server) handleCascadeGraph(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) handleTimelineEvents(w http.ResponseWriter, r *http.Request) {
+// parseEventsRequest parses the start, end and namespace query parameters of
+// the timeline endpoints. On failure it writes 400 and returns false.
+func parseEventsRequest(w http.ResponseWriter, r *http.Request) (timeline.EventsRequest, bool) {
 	startStr := r.URL.Query().Get("start")
 	endStr := r.URL.Query().Get("end")
 
 	if startStr == "" || endStr == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprint(w, `{"error":"missing required query parameters: start and end"}`)
-		return
+		writeJSONError(w, http.StatusBadRequest, "missing required query parameters: start and end")
+		return timeline.EventsRequest{}, false
 	}
 
 	start, err := time.Parse(time.RFC3339, startStr)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprint(w, `{"error":"invalid start parameter: must be RFC3339 format"}`)
-		return
+		writeJSONError(w, http.StatusBadRequest, "invalid start parameter: must be RFC3339 format")
+		return timeline.EventsRequest{}, false
 	}
 
 	end, err := time.Parse(time.RFC3339, endStr)
 	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprint(w, `{"error":"invalid end parameter: must be RFC3339 format"}`)
-		return
+		writeJSONError(w, http.StatusBadRequest, "invalid end parameter: must be RFC3339 format")
+		return timeline.EventsRequest{}, false
 	}
 
 	if !start.Before(end) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_, _ = fmt.Fprint(w, `{"error":"start must be before end"}`)
-		return
+		writeJSONError(w, http.StatusBadRequest, "start must be before end")
+		return timeline.EventsRequest{}, false
 	}
 
-	namespace := r.URL.Query().Get("namespace")
-
-	req := timeline.EventsRequest{
+	return timeline.EventsRequest{
 		Start:     start,
 		End:       end,
-		Namespace: namespace,
+		Namespace: r.URL.Query().Get("namespace"),
+	}, true
+}
+
+func (s *Server) handleTimelineEvents(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseEventsRequest(w, r)
+	if !ok {
+		return
 	}
 
-	events, err := timeline.QueryStatusTransitions(r.Context(), s.prom, req)
+	events, err := s.queryEvents(r.Context(), req)
 	if err != nil {
 		s.logger.Error("failed to query timeline events", "error", err)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadGateway)
-		_, _ = fmt.Fprintf(w, `{"error":"failed to fetch timeline events: %s"}`, err.Error())
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch timeline events: "+err.Error())
 		return
 	}
 
@@ -677,3 +736,27 @@ func (s *Server) handleTimelineEvents(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleTimelineOutages handles GET /api/v1/timeline/outages.
+// Returns per-edge outages paired from degradation and recovery transitions.
+func (s *Server) handleTimelineOutages(w http.ResponseWriter, r *http.Request) {
+	req, ok := parseEventsRequest(w, r)
+	if !ok {
+		return
+	}
+
+	outages, err := s.queryOutages(r.Context(), req)
+	if err != nil {
+		s.logger.Error("failed to query timeline outages", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch timeline outages: "+err.Error())
+		return
+	}
+
+	if outages == nil {
+		outages = []timeline.Outage{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(outages); err != nil {
+		s.logger.Error("failed to encode timeline outages response", "error", err)
+	}
+}
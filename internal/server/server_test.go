@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -59,7 +60,7 @@ func newTestServer() *Server {
 	builder := topology.NewGraphBuilder(promClient, nil, grafanaCfg, cfg.Cache.TTL, 0, logger, cfg.Alerts.SeverityLevels)
 	topologyCache := cache.New(cfg.Cache.TTL)
 	authenticator, _ := auth.NewFromConfig(config.AuthConfig{Type: "none"})
-	return New(cfg, logger, builder, promClient, nil, topologyCache, authenticator)
+	return New(cfg, logger, builder, nil, topologyCache, authenticator)
 }
 
 func TestRoutes(t *testing.T) {
@@ -76,6 +77,8 @@ func TestRoutes(t *testing.T) {
 		{"GET", "/api/v1/alerts", http.StatusOK},
 		{"GET", "/api/v1/config", http.StatusOK},
 		{"GET", "/api/v1/cascade-analysis", http.StatusOK},
+		{"GET", "/api/v1/cascade/spof", http.StatusOK},
+		{"GET", "/api/v1/cycles", http.StatusOK},
 		{"GET", "/api/v1/cascade-graph", http.StatusOK},
 		{"GET", "/", http.StatusOK},
 	}
@@ -173,7 +176,7 @@ func TestConfigAlertsEnabled(t *testing.T) {
 	builder := topology.NewGraphBuilder(promClient, nil, grafanaCfg, cfg.Cache.TTL, 0, logger, cfg.Alerts.SeverityLevels)
 	topologyCache := cache.New(cfg.Cache.TTL)
 	authenticator, _ := auth.NewFromConfig(config.AuthConfig{Type: "none"})
-	srv := New(cfg, logger, builder, promClient, nil, topologyCache, authenticator)
+	srv := New(cfg, logger, builder, nil, topologyCache, authenticator)
 
 	req := httptest.NewRequest("GET", "/api/v1/config", nil)
 	w := httptest.NewRecorder()
@@ -200,6 +203,79 @@ func TestHealthzJSON(t *testing.T) {
 	}
 }
 
+func TestReadyzCircuitOpen(t *testing.T) {
+	srv := newTestServer()
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 with a closed circuit", w.Code)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	prom := topology.NewPrometheusClient(topology.PrometheusConfig{
+		URL:            down.URL,
+		CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	})
+	srv.builder.SetSources([]topology.Source{{Name: "vm", Prom: prom}})
+	if _, err := prom.QueryTopologyEdges(req.Context(), topology.QueryOptions{}); err == nil {
+		t.Fatal("expected query against a failing datasource to fail")
+	}
+
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 with every circuit open", w.Code)
+	}
+	var body struct {
+		Status      string                      `json:"status"`
+		Datasources []topology.DatasourceStatus `json:"datasources"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Status != "unavailable" || len(body.Datasources) != 1 || body.Datasources[0].Circuit != topology.CircuitOpen {
+		t.Errorf("body = %+v, want unavailable with open vm circuit", body)
+	}
+}
+
+func TestTopologyTenant(t *testing.T) {
+	srv := newTestServer()
+	srv.cfg.Datasources.Prometheus.Tenant = config.TenantConfig{Mode: config.TenantModeHeader, ID: "team-a", Allowed: []string{"team-b"}}
+
+	req := httptest.NewRequest("GET", "/api/v1/topology?tenant=team-b", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp topology.TopologyResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.Meta.Tenant != "team-b" {
+		t.Errorf("meta.tenant = %q, want %q", resp.Meta.Tenant, "team-b")
+	}
+	if _, ok := srv.tenants["team-b"]; !ok {
+		t.Error("expected a separate cache for tenant team-b")
+	}
+	if _, ok := srv.cache.Get(); ok {
+		t.Error("tenant topology must not be stored in the default cache")
+	}
+
+	for _, path := range []string{"/api/v1/topology?tenant=team-c", "/api/v1/topology/stream?tenant=team-b"} {
+		w = httptest.NewRecorder()
+		srv.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, w.Code)
+		}
+	}
+}
+
 func TestTopologyETag(t *testing.T) {
 	srv := newTestServer()
 
@@ -316,6 +392,87 @@ func TestCascadeAnalysisInvalidDepth(t *testing.T) {
 	}
 }
 
+func TestCascadeAnalysisMode(t *testing.T) {
+	srv := newTestServer()
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"mode=weighted", http.StatusOK},
+		{"mode=strict", http.StatusOK},
+		{"mode=weighted&nonCriticalWeight=0.5", http.StatusOK},
+		{"mode=fuzzy", http.StatusBadRequest},
+		{"mode=weighted&nonCriticalWeight=2", http.StatusBadRequest},
+		{"mode=weighted&nonCriticalWeight=abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/cascade-analysis?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.query, w.Code, tt.want)
+		}
+	}
+}
+
+func TestCascadeSPOF(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/cascade/spof?limit=1", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var result map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, field := range []string{"articulationPoints", "bridges", "ranking", "summary"} {
+		if _, ok := result[field]; !ok {
+			t.Errorf("missing field %q in response", field)
+		}
+	}
+
+	for _, query := range []string{"depth=-1", "limit=abc", "time=bad"} {
+		req := httptest.NewRequest("GET", "/api/v1/cascade/spof?"+query, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", query, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestCycles(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/cycles?critical=true", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var result struct {
+		Cycles  []any          `json:"cycles"`
+		Summary map[string]int `json:"summary"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if result.Cycles == nil || len(result.Cycles) != 0 {
+		t.Errorf("cycles = %v, want empty array for an acyclic topology", result.Cycles)
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/cycles?time=bad", nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
 func TestCascadeGraphReturnsJSON(t *testing.T) {
 	srv := newTestServer()
 	req := httptest.NewRequest("GET", "/api/v1/cascade-graph", nil)
@@ -554,6 +711,117 @@ func TestTimelineEventsReturnsJSON(t *testing.T) {
 	}
 }
 
+func TestTimelineOutagesReturnsJSON(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/timeline/outages?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var outages []any
+	if err := json.NewDecoder(w.Body).Decode(&outages); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if outages == nil {
+		t.Error("expected non-nil array (even if empty)")
+	}
+}
+
+func TestTimelineOutagesMissingParams(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/timeline/outages?start=2026-01-15T12:00:00Z", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestIncidentsReturnsJSON(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/incidents?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&gap=10m", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var incidents []any
+	if err := json.NewDecoder(w.Body).Decode(&incidents); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if incidents == nil {
+		t.Error("expected non-nil array (even if empty)")
+	}
+}
+
+func TestIncidentsInvalidGap(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/incidents?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&gap=soon", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAvailabilityReport(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/reports/availability?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&criticalWeight=2", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var report map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if _, ok := report["services"]; !ok {
+		t.Error("expected services in report")
+	}
+}
+
+func TestAvailabilityReportCSVDownload(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/reports/availability?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&format=csv", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/zip" {
+		t.Errorf("Content-Type = %q, want application/zip", ct)
+	}
+	if cd := w.Header().Get("Content-Disposition"); !strings.Contains(cd, "dephealth-availability-") {
+		t.Errorf("Content-Disposition = %q, want availability filename", cd)
+	}
+}
+
+func TestAvailabilityReportBadParams(t *testing.T) {
+	srv := newTestServer()
+	for _, url := range []string{
+		"/api/v1/reports/availability?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&format=png",
+		"/api/v1/reports/availability?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z&criticalWeight=-1",
+		"/api/v1/reports/availability?start=2026-01-15T12:00:00Z",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", url, w.Code, http.StatusBadRequest)
+		}
+	}
+}
+
 func TestCORSHeaders(t *testing.T) {
 	srv := newTestServer()
 	req := httptest.NewRequest("OPTIONS", "/api/v1/topology", nil)
@@ -566,3 +834,35 @@ func TestCORSHeaders(t *testing.T) {
 		t.Error("expected Access-Control-Allow-Origin header")
 	}
 }
+
+func TestFlapping(t *testing.T) {
+	srv := newTestServer()
+	srv.cfg.Topology.Flapping = config.FlappingConfig{Threshold: 6, ClearThreshold: 2}
+	req := httptest.NewRequest("GET", "/api/v1/flapping?window=30m&namespace=default", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "[]" {
+		t.Errorf("body = %s, want empty list", body)
+	}
+}
+
+func TestFlappingBadParams(t *testing.T) {
+	srv := newTestServer()
+	for _, url := range []string{
+		"/api/v1/flapping", // flap detection disabled in the test config
+		"/api/v1/flapping?window=10s",
+		"/api/v1/flapping?window=30m", // no thresholds in the test config
+		"/api/v1/flapping?window=30m&time=yesterday",
+	} {
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", url, w.Code, http.StatusBadRequest)
+		}
+	}
+}
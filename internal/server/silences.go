@@ -0,0 +1,174 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/BigKAA/dephealth-ui/internal/alerts"
+	"github.com/BigKAA/dephealth-ui/internal/auth"
+)
+
+// anonymousCreator is recorded as silence creator when anonymous silencing
+// is enabled (alerts.silences.allowAnonymous) and the user is not authenticated.
+const anonymousCreator = "dephealth-ui"
+
+// createSilenceRequest is the body of POST /api/v1/silences.
+type createSilenceRequest struct {
+	Service    string `json:"service"`
+	Dependency string `json:"dependency"`
+	Namespace  string `json:"namespace"`
+	Duration   string `json:"duration"` // Go duration, e.g. "2h"
+	Comment    string `json:"comment"`
+}
+
+// silenceClient returns the AlertManager client as a SilenceClient, or
+// writes 503 and returns nil when AlertManager is not configured.
+func (s *Server) silenceClient(w http.ResponseWriter) alerts.SilenceClient {
+	sc, ok := s.am.(alerts.SilenceClient)
+	if !ok || len(s.cfg.Datasources.Alertmanager.Endpoints()) == 0 {
+		writeJSONError(w, http.StatusServiceUnavailable, "alertmanager is not configured")
+		return nil
+	}
+	return sc
+}
+
+// silenceUser returns the user managing a silence. Unauthenticated requests
+// get 401 unless anonymous silencing is enabled; ok is false when the
+// response has been written.
+func (s *Server) silenceUser(w http.ResponseWriter, r *http.Request) (user string, ok bool) {
+	if user := auth.Username(r.Context()); user != "" {
+		return user, true
+	}
+	if s.cfg.Alerts.Silences.AllowAnonymous {
+		return anonymousCreator, true
+	}
+	writeJSONError(w, http.StatusUnauthorized, "silences require an authenticated user")
+	return "", false
+}
+
+// handleCreateSilence handles POST /api/v1/silences.
+// Creates an AlertManager silence for the alerts of a service, optionally
+// narrowed to one dependency and namespace. The authenticated user is
+// recorded as creator.
+func (s *Server) handleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	sc := s.silenceClient(w)
+	if sc == nil {
+		return
+	}
+	creator, ok := s.silenceUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req createSilenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.Service == "" {
+		writeJSONError(w, http.StatusBadRequest, "service is required")
+		return
+	}
+	if req.Comment == "" {
+		writeJSONError(w, http.StatusBadRequest, "comment is required")
+		return
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "duration must be a positive duration (e.g. \"2h\")")
+		return
+	}
+	if limit := s.cfg.Alerts.Silences.MaxDuration; limit > 0 && duration > limit {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("duration must not exceed %s", limit))
+		return
+	}
+
+	silence, err := sc.CreateSilence(r.Context(), alerts.SilenceRequest{
+		Service:    req.Service,
+		Dependency: req.Dependency,
+		Namespace:  req.Namespace,
+		Duration:   duration,
+		Comment:    req.Comment,
+		CreatedBy:  creator,
+	})
+	// With several endpoints, those that failed are reported in errors.
+	resp := struct {
+		alerts.Silence
+		Errors []string `json:"errors,omitempty"`
+	}{Silence: silence}
+	var partial *alerts.PartialError
+	switch {
+	case errors.As(err, &partial):
+		s.logger.Warn("silence not created on every alertmanager endpoint", "id", silence.ID, "error", err)
+		for _, ep := range partial.Endpoints {
+			resp.Errors = append(resp.Errors, ep.Error())
+		}
+	case err != nil:
+		s.logger.Error("failed to create silence", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to create silence: "+err.Error())
+		return
+	}
+	s.logger.Info("silence created", "id", silence.ID, "service", req.Service,
+		"dependency", req.Dependency, "createdBy", creator, "endsAt", silence.EndsAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		s.logger.Error("failed to encode silence response", "error", err)
+	}
+}
+
+// handleListSilences handles GET /api/v1/silences.
+// Returns silences created through dephealth-ui, including expired ones
+// AlertManager still retains.
+func (s *Server) handleListSilences(w http.ResponseWriter, r *http.Request) {
+	sc := s.silenceClient(w)
+	if sc == nil {
+		return
+	}
+
+	silences, err := sc.ListSilences(r.Context())
+	if err != nil {
+		s.logger.Error("failed to list silences", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to list silences: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]any{"silences": silences}); err != nil {
+		s.logger.Error("failed to encode silences response", "error", err)
+	}
+}
+
+// handleExpireSilence handles DELETE /api/v1/silences/{id}.
+// Only silences created through dephealth-ui can be expired, by the same
+// users who may create them.
+func (s *Server) handleExpireSilence(w http.ResponseWriter, r *http.Request) {
+	sc := s.silenceClient(w)
+	if sc == nil {
+		return
+	}
+	user, ok := s.silenceUser(w, r)
+	if !ok {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	err := sc.ExpireSilence(r.Context(), id)
+	if errors.Is(err, alerts.ErrSilenceNotFound) {
+		writeJSONError(w, http.StatusNotFound, "silence not found")
+		return
+	}
+	if err != nil {
+		s.logger.Error("failed to expire silence", "id", id, "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to expire silence: "+err.Error())
+		return
+	}
+	s.logger.Info("silence expired", "id", id, "by", user)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/BigKAA/dephealth-ui/internal/alerts"
+	"github.com/BigKAA/dephealth-ui/internal/auth"
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// newTestServerWithAlertManager returns a test server whose AlertManager
+// client talks to amURL. Authentication is disabled, so anonymous silencing
+// is enabled.
+func newTestServerWithAlertManager(amURL string) *Server {
+	srv := newTestServer()
+	srv.cfg.Datasources.Alertmanager.URL = amURL
+	srv.cfg.Alerts.Silences = config.SilencesConfig{AllowAnonymous: true, MaxDuration: 24 * time.Hour}
+	srv.am = alerts.NewClient(alerts.Config{URL: amURL})
+	return srv
+}
+
+func TestSilencesWithoutAlertManager(t *testing.T) {
+	srv := newTestServer()
+	req := httptest.NewRequest("GET", "/api/v1/silences", nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
+
+func TestCreateSilenceValidation(t *testing.T) {
+	srv := newTestServerWithAlertManager("http://am.invalid")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"invalid json", `{`},
+		{"missing service", `{"duration":"1h","comment":"x"}`},
+		{"missing comment", `{"service":"svc","duration":"1h"}`},
+		{"invalid duration", `{"service":"svc","duration":"soon","comment":"x"}`},
+		{"negative duration", `{"service":"svc","duration":"-1h","comment":"x"}`},
+		{"duration above maximum", `{"service":"svc","duration":"87600h","comment":"x"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/silences", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			srv.router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestCreateSilence(t *testing.T) {
+	var createdBy string
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			CreatedBy string `json:"createdBy"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		createdBy = body.CreatedBy
+		_, _ = w.Write([]byte(`{"silenceID":"s-1"}`))
+	}))
+	defer am.Close()
+
+	srv := newTestServerWithAlertManager(am.URL)
+	req := httptest.NewRequest("POST", "/api/v1/silences",
+		strings.NewReader(`{"service":"svc-go","dependency":"postgres","duration":"2h","comment":"maintenance"}`))
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusCreated, w.Body.String())
+	}
+	var silence alerts.Silence
+	if err := json.NewDecoder(w.Body).Decode(&silence); err != nil {
+		t.Fatalf("failed to decode JSON: %v", err)
+	}
+	if silence.ID != "s-1" {
+		t.Errorf("ID = %q, want s-1", silence.ID)
+	}
+	// Authentication is disabled in the test server and anonymous silencing enabled.
+	if createdBy != anonymousCreator {
+		t.Errorf("createdBy = %q, want %q", createdBy, anonymousCreator)
+	}
+}
+
+func TestSilencesRequireAuthentication(t *testing.T) {
+	var createdBy string
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			CreatedBy string `json:"createdBy"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		createdBy = body.CreatedBy
+		_, _ = w.Write([]byte(`{"silenceID":"s-1"}`))
+	}))
+	defer am.Close()
+
+	srv := newTestServerWithAlertManager(am.URL)
+	srv.cfg.Alerts.Silences.AllowAnonymous = false
+	body := `{"service":"svc-go","duration":"2h","comment":"maintenance"}`
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/api/v1/silences", strings.NewReader(body)),
+		httptest.NewRequest("DELETE", "/api/v1/silences/s-1", nil),
+	} {
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s %s status = %d, want 401 without an authenticated user", req.Method, req.URL.Path, w.Code)
+		}
+	}
+	if createdBy != "" {
+		t.Fatal("AlertManager must not be called for anonymous requests")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.auth = auth.NewBasic([]auth.User{{Username: "alice", PasswordHash: string(hash)}})
+	srv.router = chi.NewRouter()
+	srv.setupMiddleware()
+	srv.setupRoutes()
+
+	req := httptest.NewRequest("POST", "/api/v1/silences", strings.NewReader(body))
+	req.SetBasicAuth("alice", "secret")
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want 201; body: %s", w.Code, w.Body.String())
+	}
+	if createdBy != "alice" {
+		t.Errorf("createdBy = %q, want alice", createdBy)
+	}
+}
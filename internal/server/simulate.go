@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// simulateRequest is the JSON body of POST /api/v1/cascade/simulate.
+type simulateRequest struct {
+	Nodes             []string           `json:"nodes"`
+	Edges             []topology.EdgeRef `json:"edges"`
+	Namespace         string             `json:"namespace"`
+	Depth             int                `json:"depth"`
+	Mode              string             `json:"mode"`
+	NonCriticalWeight *float64           `json:"nonCriticalWeight"`
+	Time              string             `json:"time"` // optional RFC3339; simulate on the historical topology
+}
+
+// handleCascadeSimulate handles POST /api/v1/cascade/simulate: it forces the
+// requested nodes and edges down on the current (or historical) topology and
+// returns the resulting cascade analysis and the entry points that would
+// lose functionality.
+func (s *Server) handleCascadeSimulate(w http.ResponseWriter, r *http.Request) {
+	var req simulateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Nodes) == 0 && len(req.Edges) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "nodes or edges is required")
+		return
+	}
+	if req.Depth < 0 {
+		writeJSONError(w, http.StatusBadRequest, "depth must not be negative")
+		return
+	}
+	if req.Mode != "" && req.Mode != cascade.ModeStrict && req.Mode != cascade.ModeWeighted {
+		writeJSONError(w, http.StatusBadRequest, "mode must be strict or weighted")
+		return
+	}
+	if ncw := req.NonCriticalWeight; ncw != nil && (*ncw < 0 || *ncw > 1) {
+		writeJSONError(w, http.StatusBadRequest, "nonCriticalWeight must be between 0 and 1")
+		return
+	}
+
+	var resp *topology.TopologyResponse
+	var err error
+	if req.Time != "" {
+		t, perr := time.Parse(time.RFC3339, req.Time)
+		if perr != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid time: must be RFC3339 format")
+			return
+		}
+		resp, err = s.historicalTopology(r.Context(), topology.QueryOptions{Time: &t})
+	} else {
+		resp, _, err = s.live(r.Context()).get(r.Context())
+	}
+	if err != nil {
+		s.logger.Error("failed to build topology for cascade simulation", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
+		return
+	}
+
+	result, err := cascade.Simulate(resp.Nodes, resp.Edges, cascade.Simulation{
+		Nodes: req.Nodes,
+		Edges: req.Edges,
+	}, cascade.Options{
+		MaxDepth:          req.Depth,
+		Namespace:         req.Namespace,
+		Mode:              req.Mode,
+		NonCriticalWeight: req.NonCriticalWeight,
+	})
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error("failed to encode cascade simulation", "error", err)
+	}
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+)
+
+func TestCascadeSimulate(t *testing.T) {
+	srv := newTestServer()
+	resp, _, err := srv.refresher.get(context.Background())
+	if err != nil {
+		t.Fatalf("refresher.get() error: %v", err)
+	}
+	var depID string
+	for _, n := range resp.Nodes {
+		if n.Type != "service" {
+			depID = n.ID
+		}
+	}
+	if depID == "" {
+		t.Fatal("no dependency node in test topology")
+	}
+
+	body := `{"nodes":["` + depID + `"]}`
+	req := httptest.NewRequest("POST", "/api/v1/cascade/simulate", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	var result cascade.SimulationResult
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(result.FailedNodes) != 1 || result.FailedNodes[0] != depID {
+		t.Errorf("failedNodes = %v, want [%s]", result.FailedNodes, depID)
+	}
+	if len(result.RootCauses) != 1 || result.RootCauses[0].ID != depID {
+		t.Errorf("rootCauses = %v, want %s", result.RootCauses, depID)
+	}
+	if result.EntryPoints == nil {
+		t.Error("entryPoints = null, want an array")
+	}
+}
+
+func TestCascadeSimulateBadRequest(t *testing.T) {
+	srv := newTestServer()
+	for _, body := range []string{
+		`not json`,
+		`{}`,
+		`{"nodes":["no-such-node"]}`,
+		`{"edges":[{"source":"a","target":"b"}]}`,
+		`{"nodes":["x"],"mode":"fuzzy"}`,
+		`{"nodes":["x"],"time":"yesterday"}`,
+	} {
+		req := httptest.NewRequest("POST", "/api/v1/cascade/simulate", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		srv.router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/snapshot"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// SetSnapshotStore enables the persistent snapshot store: background-built
+// topologies are saved to it and historical requests are served from it
+// when a snapshot covers the requested time.
+func (s *Server) SetSnapshotStore(st *snapshot.Store) {
+	s.snapshots = st
+}
+
+// saveSnapshot persists a background-built topology to the snapshot store.
+func (s *Server) saveSnapshot(resp *topology.TopologyResponse) {
+	if s.snapshots == nil {
+		return
+	}
+	if _, err := s.snapshots.Save(resp, time.Now()); err != nil {
+		s.logger.Warn("failed to save topology snapshot", "error", err)
+	}
+}
+
+// historicalTopology returns the topology at opts.Time, read from the
+// nearest covering snapshot when the snapshot store is enabled, and built
+// from Prometheus otherwise.
+func (s *Server) historicalTopology(ctx context.Context, opts topology.QueryOptions) (*topology.TopologyResponse, error) {
+	// Snapshots hold the default tenants' topology only.
+	if s.snapshots != nil && opts.Time != nil && topology.TenantFromContext(ctx) == "" {
+		resp, takenAt, ok, err := s.snapshots.Nearest(*opts.Time)
+		if err != nil {
+			s.logger.Warn("failed to read topology snapshot, querying Prometheus", "time", opts.Time, "error", err)
+		}
+		if ok {
+			resp = snapshot.Filter(resp, opts.Namespace, opts.Group)
+			at := *opts.Time
+			resp.Meta.Time = &at
+			resp.Meta.IsHistory = true
+			resp.Meta.Stale = false
+			resp.Meta.SnapshotAt = &takenAt
+			// Query timings and breaker states describe the build that took
+			// the snapshot, not this request.
+			resp.Meta.Queries = nil
+			resp.Meta.Datasources = nil
+			return resp, nil
+		}
+	}
+	return s.builder.Build(ctx, opts)
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/snapshot"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestHistoricalTopologyFromSnapshot(t *testing.T) {
+	srv := newTestServer()
+	st, err := snapshot.Open(t.TempDir(), snapshot.Options{Interval: time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("snapshot.Open() error: %v", err)
+	}
+	srv.SetSnapshotStore(st)
+
+	takenAt := time.Now().Add(-5 * time.Minute).Truncate(time.Second)
+	snap := &topology.TopologyResponse{
+		Nodes: []topology.Node{{ID: "from-snapshot", Label: "from-snapshot", Type: "service", State: "down"}},
+		Edges: []topology.Edge{},
+	}
+	if _, err := st.Save(snap, takenAt); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	at := takenAt.Add(30 * time.Second).UTC().Format(time.RFC3339)
+	req := httptest.NewRequest("GET", "/api/v1/topology?time="+at, nil)
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp topology.TopologyResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Nodes) != 1 || resp.Nodes[0].ID != "from-snapshot" {
+		t.Errorf("nodes = %+v, want the snapshot node", resp.Nodes)
+	}
+	if !resp.Meta.IsHistory || resp.Meta.SnapshotAt == nil || !resp.Meta.SnapshotAt.Equal(takenAt) {
+		t.Errorf("meta = %+v, want history served from snapshot taken at %v", resp.Meta, takenAt)
+	}
+
+	// A time not covered by any snapshot falls back to Prometheus.
+	req = httptest.NewRequest("GET", "/api/v1/topology?time="+takenAt.Add(-time.Hour).UTC().Format(time.RFC3339), nil)
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, req)
+	resp = topology.TopologyResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Meta.SnapshotAt != nil {
+		t.Error("uncovered time should not be served from a snapshot")
+	}
+}
+
+func TestSaveSnapshotOnRefresh(t *testing.T) {
+	srv := newTestServer()
+	st, err := snapshot.Open(t.TempDir(), snapshot.Options{Interval: time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("snapshot.Open() error: %v", err)
+	}
+	srv.SetSnapshotStore(st)
+
+	if _, err := srv.refresher.refresh(t.Context()); err != nil {
+		t.Fatalf("refresh() error: %v", err)
+	}
+	if st.Len() != 1 {
+		t.Errorf("snapshots = %d, want 1 after a background build", st.Len())
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/BigKAA/dephealth-ui/internal/timeline"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// querySources calls query in parallel for every source, i.e. every one
+// serving the tenant selected in ctx (see GraphBuilder.Sources), with the
+// source's index and cluster, the name its results are tagged with (empty
+// for a single source). Unlike a topology
+// build, every source has to answer: a report silently missing a cluster
+// would be wrong rather than partial.
+func (s *Server) querySources(ctx context.Context, sources []topology.Source, query func(ctx context.Context, i int, cluster string, prom topology.PrometheusClient) error) error {
+	if len(sources) == 0 {
+		return fmt.Errorf("%w: %q", topology.ErrTenantNotAllowed, topology.TenantFromContext(ctx))
+	}
+	errs := make([]error, len(sources))
+	var wg sync.WaitGroup
+	for i, src := range sources {
+		cluster := ""
+		if s.builder.MultiSource() {
+			cluster = src.Name
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := query(ctx, i, cluster, src.Prom); err != nil && cluster != "" {
+				errs[i] = fmt.Errorf("%s: %w", cluster, err)
+			} else {
+				errs[i] = err
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// queryEvents returns the status transitions of every source.
+func (s *Server) queryEvents(ctx context.Context, req timeline.EventsRequest) ([]timeline.Event, error) {
+	sources := s.builder.Sources(ctx)
+	results := make([][]timeline.Event, len(sources))
+	err := s.querySources(ctx, sources, func(ctx context.Context, i int, cluster string, prom topology.PrometheusClient) error {
+		events, err := timeline.QueryStatusTransitions(ctx, prom, req)
+		for j := range events {
+			events[j].Cluster = cluster
+		}
+		results[i] = events
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var events []timeline.Event
+	for _, r := range results {
+		events = append(events, r...)
+	}
+	timeline.SortEvents(events)
+	return events, nil
+}
+
+// queryOutages returns the outages of every source.
+func (s *Server) queryOutages(ctx context.Context, req timeline.EventsRequest) ([]timeline.Outage, error) {
+	sources := s.builder.Sources(ctx)
+	results := make([][]timeline.Outage, len(sources))
+	err := s.querySources(ctx, sources, func(ctx context.Context, i int, cluster string, prom topology.PrometheusClient) error {
+		outages, err := timeline.QueryOutages(ctx, prom, req)
+		for j := range outages {
+			outages[j].Cluster = cluster
+		}
+		results[i] = outages
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	var outages []timeline.Outage
+	for _, r := range results {
+		outages = append(outages, r...)
+	}
+	timeline.SortOutages(outages)
+	return outages, nil
+}
+
+// queryFlapping returns the flapping edges of every source.
+func (s *Server) queryFlapping(ctx context.Context, req timeline.FlappingRequest) ([]timeline.FlappingEdge, error) {
+	sources := s.builder.Sources(ctx)
+	results := make([][]timeline.FlappingEdge, len(sources))
+	err := s.querySources(ctx, sources, func(ctx context.Context, i int, cluster string, prom topology.PrometheusClient) error {
+		edges, err := timeline.QueryFlapping(ctx, prom, req)
+		for j := range edges {
+			edges[j].Cluster = cluster
+		}
+		results[i] = edges
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	edges := []timeline.FlappingEdge{}
+	for _, r := range results {
+		edges = append(edges, r...)
+	}
+	timeline.SortFlapping(edges)
+	return edges, nil
+}
+
+// queryAvailability returns the availability report of every source merged
+// into one.
+func (s *Server) queryAvailability(ctx context.Context, req timeline.AvailabilityRequest) (*timeline.AvailabilityReport, error) {
+	sources := s.builder.Sources(ctx)
+	results := make([]*timeline.AvailabilityReport, len(sources))
+	err := s.querySources(ctx, sources, func(ctx context.Context, i int, cluster string, prom topology.PrometheusClient) error {
+		report, err := timeline.QueryAvailability(ctx, prom, req)
+		if err != nil {
+			return err
+		}
+		for j := range report.Edges {
+			report.Edges[j].Cluster = cluster
+		}
+		for j := range report.Services {
+			report.Services[j].Cluster = cluster
+		}
+		results[i] = report
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	// All sources share the range and step; only the rows are merged.
+	merged := *results[0]
+	merged.Edges = []timeline.EdgeAvailability{}
+	merged.Services = []timeline.ServiceAvailability{}
+	for _, r := range results {
+		merged.Edges = append(merged.Edges, r.Edges...)
+		merged.Services = append(merged.Services, r.Services...)
+	}
+	timeline.SortAvailability(&merged)
+	return &merged, nil
+}
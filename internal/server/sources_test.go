@@ -0,0 +1,83 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// newClusterPromServer serves one cluster: svc-go's postgres edge goes from
+// ok to timeout at the second sample, and svc-go has one instance named
+// after the cluster. Instant queries are recorded in queries.
+func newClusterPromServer(cluster string, mu *sync.Mutex, queries *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/query_range") {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+				{"metric":{"name":"svc-go","host":"pg","port":"5432","status":"ok"},"values":[[1768478400,"1"],[1768478415,"0"]]},
+				{"metric":{"name":"svc-go","host":"pg","port":"5432","status":"timeout"},"values":[[1768478400,"0"],[1768478415,"1"]]}
+			]}}`))
+			return
+		}
+		mu.Lock()
+		*queries = append(*queries, cluster+": "+r.FormValue("query"))
+		mu.Unlock()
+		_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"instance":"%s-pod:8080","pod":"%s-pod"},"value":[1768478400,"1"]}
+		]}}`, cluster, cluster)
+	}))
+}
+
+func TestMultipleSources(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	srv := newTestServer()
+	var sources []topology.Source
+	for _, cluster := range []string{"eu", "us"} {
+		prom := newClusterPromServer(cluster, &mu, &queries)
+		defer prom.Close()
+		sources = append(sources, topology.Source{
+			Name: cluster,
+			Prom: topology.NewPrometheusClient(topology.PrometheusConfig{URL: prom.URL}),
+		})
+	}
+	srv.builder.SetSources(sources)
+
+	w := httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/timeline/outages?start=2026-01-15T12:00:00Z&end=2026-01-15T13:00:00Z", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("outages status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var outages []struct {
+		Service string `json:"service"`
+		Cluster string `json:"cluster"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&outages); err != nil {
+		t.Fatalf("decoding outages: %v", err)
+	}
+	if len(outages) != 2 || outages[0].Cluster != "eu" || outages[1].Cluster != "us" {
+		t.Errorf("outages = %+v, want one svc-go outage per cluster", outages)
+	}
+
+	w = httptest.NewRecorder()
+	srv.router.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/instances?service=us/svc-go", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("instances status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var instances []topology.Instance
+	if err := json.NewDecoder(w.Body).Decode(&instances); err != nil {
+		t.Fatalf("decoding instances: %v", err)
+	}
+	if len(instances) != 1 || instances[0].Instance != "us-pod:8080" {
+		t.Errorf("instances = %+v, want the us instance only", instances)
+	}
+	if len(queries) != 1 || !strings.HasPrefix(queries[0], "us: ") || !strings.Contains(queries[0], `name="svc-go"`) {
+		t.Errorf("instance queries = %q, want one query for svc-go on us", queries)
+	}
+}
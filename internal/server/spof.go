@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// handleCascadeSPOF handles GET /api/v1/cascade/spof.
+// Query parameters:
+//   - namespace: optional namespace filter for the reported nodes and bridges
+//   - depth: optional maximum traversal depth for blast radius (0 = unlimited)
+//   - limit: optional maximum number of ranked nodes (0 = all)
+//   - time: optional RFC3339 timestamp to analyze the historical topology
+func (s *Server) handleCascadeSPOF(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := cascade.Options{Namespace: q.Get("namespace")}
+
+	if d := q.Get("depth"); d != "" {
+		v, err := strconv.Atoi(d)
+		if err != nil || v < 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid depth parameter: must be a non-negative integer")
+			return
+		}
+		opts.MaxDepth = v
+	}
+	limit := 0
+	if l := q.Get("limit"); l != "" {
+		v, err := strconv.Atoi(l)
+		if err != nil || v < 0 {
+			writeJSONError(w, http.StatusBadRequest, "invalid limit parameter: must be a non-negative integer")
+			return
+		}
+		limit = v
+	}
+
+	var resp *topology.TopologyResponse
+	var err error
+	if ts := q.Get("time"); ts != "" {
+		t, perr := time.Parse(time.RFC3339, ts)
+		if perr != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid time parameter: must be RFC3339 format")
+			return
+		}
+		resp, err = s.historicalTopology(r.Context(), topology.QueryOptions{Time: &t})
+	} else {
+		resp, _, err = s.live(r.Context()).get(r.Context())
+	}
+	if err != nil {
+		s.logger.Error("failed to build topology for SPOF analysis", "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
+		return
+	}
+
+	result := cascade.AnalyzeSPOF(resp.Nodes, resp.Edges, opts)
+	if limit > 0 && len(result.Ranking) > limit {
+		result.Ranking = result.Ranking[:limit]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.Error("failed to encode SPOF analysis", "error", err)
+	}
+}
@@ -0,0 +1,171 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+const (
+	// streamBufferSize is the number of pending events a subscriber may queue.
+	// A subscriber that falls further behind is disconnected and must reconnect
+	// to receive a fresh snapshot.
+	streamBufferSize = 8
+	// streamKeepAlive is the interval between SSE comment heartbeats.
+	streamKeepAlive = 15 * time.Second
+)
+
+// streamEvent is a pre-encoded Server-Sent Event.
+type streamEvent struct {
+	name string
+	data []byte
+}
+
+// topologyHub fans out topology updates from the refresher to SSE subscribers.
+// The delta against the previous snapshot is computed once per update and
+// shared by all subscribers.
+type topologyHub struct {
+	mu   sync.Mutex
+	last *topology.TopologyResponse
+	subs map[chan streamEvent]struct{}
+}
+
+func newTopologyHub() *topologyHub {
+	return &topologyHub{subs: make(map[chan streamEvent]struct{})}
+}
+
+// subscribe registers a new subscriber and returns its event channel together
+// with the latest published snapshot (nil if nothing was published yet).
+func (h *topologyHub) subscribe() (chan streamEvent, *topology.TopologyResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan streamEvent, streamBufferSize)
+	h.subs[ch] = struct{}{}
+	return ch, h.last
+}
+
+// unsubscribe removes a subscriber. It is safe to call for an already dropped subscriber.
+func (h *topologyHub) unsubscribe(ch chan streamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
+
+// publish records resp as the latest snapshot and broadcasts either a full
+// snapshot (first publish) or the delta against the previous snapshot.
+// Updates without node, edge, alert or staleness changes are not broadcast.
+func (h *topologyHub) publish(resp *topology.TopologyResponse) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	prev := h.last
+	h.last = resp
+
+	var ev streamEvent
+	if prev == nil {
+		ev = streamEvent{name: "snapshot"}
+		ev.data, _ = json.Marshal(resp)
+	} else {
+		delta := topology.ComputeDelta(prev, resp)
+		if delta.Empty() && prev.Meta.Stale == resp.Meta.Stale {
+			return
+		}
+		ev = streamEvent{name: "delta"}
+		ev.data, _ = json.Marshal(delta)
+	}
+
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			// Slow subscriber: drop it so that it reconnects and resyncs.
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// handleTopologyStream handles GET /api/v1/topology/stream.
+// It sends the current unfiltered topology as a "snapshot" event and then
+// "delta" events with node, edge and alert changes after each background rebuild.
+// Only the default tenants' topology is streamed.
+func (s *Server) handleTopologyStream(w http.ResponseWriter, r *http.Request) {
+	if topology.TenantFromContext(r.Context()) != "" {
+		writeJSONError(w, http.StatusBadRequest, "tenant selection is not supported by the topology stream")
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSONError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	ch, snapshot := s.hub.subscribe()
+	defer s.hub.unsubscribe(ch)
+
+	if snapshot == nil {
+		resp, _, err := s.refresher.get(r.Context())
+		if err != nil {
+			s.logger.Error("failed to build topology for stream", "error", err)
+			writeJSONError(w, http.StatusBadGateway, "failed to fetch topology data: "+err.Error())
+			return
+		}
+		snapshot = resp
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		s.logger.Error("failed to encode topology snapshot", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to encode topology")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeSSE(w, streamEvent{name: "snapshot", data: data}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, ev); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// writeSSE writes a single named event in text/event-stream format.
+func writeSSE(w http.ResponseWriter, ev streamEvent) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, ev.data)
+	return err
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestTopologyHubPublishesSnapshotThenDelta(t *testing.T) {
+	h := newTopologyHub()
+	ch, last := h.subscribe()
+	defer h.unsubscribe(ch)
+
+	if last != nil {
+		t.Fatal("expected nil snapshot before first publish")
+	}
+
+	first := &topology.TopologyResponse{
+		Nodes: []topology.Node{{ID: "svc-a", State: "ok"}},
+	}
+	h.publish(first)
+
+	ev := <-ch
+	if ev.name != "snapshot" {
+		t.Errorf("first event = %q, want snapshot", ev.name)
+	}
+
+	// Unchanged topology is not broadcast.
+	h.publish(first)
+	select {
+	case ev := <-ch:
+		t.Fatalf("unexpected event for unchanged topology: %s", ev.name)
+	default:
+	}
+
+	second := &topology.TopologyResponse{
+		Nodes: []topology.Node{{ID: "svc-a", State: "degraded"}},
+	}
+	h.publish(second)
+
+	ev = <-ch
+	if ev.name != "delta" {
+		t.Fatalf("second event = %q, want delta", ev.name)
+	}
+	var delta topology.TopologyDelta
+	if err := json.Unmarshal(ev.data, &delta); err != nil {
+		t.Fatalf("failed to decode delta: %v", err)
+	}
+	if len(delta.Nodes.Changed) != 1 || delta.Nodes.Changed[0].State != "degraded" {
+		t.Errorf("delta.Nodes.Changed = %+v, want svc-a degraded", delta.Nodes.Changed)
+	}
+
+	// Late subscribers receive the latest snapshot.
+	ch2, last := h.subscribe()
+	defer h.unsubscribe(ch2)
+	if last != second {
+		t.Error("subscribe() should return the latest published snapshot")
+	}
+}
+
+func TestTopologyHubDropsSlowSubscriber(t *testing.T) {
+	h := newTopologyHub()
+	ch, _ := h.subscribe()
+	defer h.unsubscribe(ch)
+
+	for i := range streamBufferSize + 2 {
+		state := "ok"
+		if i%2 == 1 {
+			state = "down"
+		}
+		h.publish(&topology.TopologyResponse{Nodes: []topology.Node{{ID: "svc-a", State: state}}})
+	}
+
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != streamBufferSize {
+		t.Errorf("received %d events before close, want %d", n, streamBufferSize)
+	}
+}
+
+func TestTopologyStreamSendsSnapshot(t *testing.T) {
+	srv := newTestServer()
+	ts := httptest.NewServer(srv.router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/v1/topology/stream")
+	if err != nil {
+		t.Fatalf("GET stream: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	lines := make(chan string)
+	go func() {
+		sc := bufio.NewScanner(resp.Body)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		close(lines)
+	}()
+
+	var event, data string
+	timeout := time.After(5 * time.Second)
+	for data == "" {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatal("stream closed before snapshot")
+			}
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for snapshot event")
+		}
+	}
+
+	if event != "snapshot" {
+		t.Errorf("event = %q, want snapshot", event)
+	}
+	var snap topology.TopologyResponse
+	if err := json.Unmarshal([]byte(data), &snap); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	if len(snap.Nodes) == 0 {
+		t.Error("expected nodes in snapshot")
+	}
+}
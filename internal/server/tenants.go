@@ -0,0 +1,48 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/BigKAA/dephealth-ui/internal/cache"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// tenantMiddleware selects the tenant of multi-tenant datasources from the
+// tenant query parameter. Only tenants configured as a datasource default or
+// in its allowed list are accepted.
+func (s *Server) tenantMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.URL.Query().Get("tenant")
+		if tenant == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.cfg.Datasources.TenantAllowed(tenant) {
+			writeJSONError(w, http.StatusBadRequest, "unknown tenant: "+tenant)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(topology.WithTenant(r.Context(), tenant)))
+	})
+}
+
+// live returns the refresher holding the live topology of the tenant
+// selected for ctx. The default tenants' topology is refreshed in the
+// background; a selected tenant's is built on demand and cached separately.
+func (s *Server) live(ctx context.Context) *refresher {
+	tenant := topology.TenantFromContext(ctx)
+	if tenant == "" {
+		return s.refresher
+	}
+
+	s.tenantsMu.Lock()
+	defer s.tenantsMu.Unlock()
+	r, ok := s.tenants[tenant]
+	if !ok {
+		// Tenants are limited to the configured ones, so the map stays bounded.
+		r = newRefresher(s.builder, cache.New(s.cfg.Cache.TTL), s.refresher.interval, s.logger)
+		r.tenant = tenant
+		s.tenants[tenant] = r
+	}
+	return r
+}
@@ -0,0 +1,63 @@
+package snapshot
+
+import "github.com/BigKAA/dephealth-ui/internal/topology"
+
+// Filter narrows an unfiltered snapshot to a namespace and/or group the way
+// a filtered Prometheus query would: it keeps the edges whose source service
+// matches, the matching service nodes and the nodes those edges point to,
+// and the alerts of the kept services. Empty filters keep everything.
+func Filter(resp *topology.TopologyResponse, namespace, group string) *topology.TopologyResponse {
+	if namespace == "" && group == "" {
+		return resp
+	}
+
+	nodeByID := make(map[string]topology.Node, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		nodeByID[n.ID] = n
+	}
+	matches := func(n topology.Node) bool {
+		return n.Type == "service" &&
+			(namespace == "" || n.Namespace == namespace) &&
+			(group == "" || n.Group == group)
+	}
+
+	keep := make(map[string]bool)
+	for _, n := range resp.Nodes {
+		if matches(n) {
+			keep[n.ID] = true
+		}
+	}
+
+	out := *resp
+	out.Edges = []topology.Edge{}
+	for _, e := range resp.Edges {
+		if keep[e.Source] && matches(nodeByID[e.Source]) {
+			out.Edges = append(out.Edges, e)
+		}
+	}
+	for _, e := range out.Edges {
+		keep[e.Target] = true
+	}
+
+	out.Nodes = []topology.Node{}
+	services := make(map[string]bool)
+	for _, n := range resp.Nodes {
+		if keep[n.ID] {
+			out.Nodes = append(out.Nodes, n)
+			if matches(n) {
+				services[n.Label] = true
+			}
+		}
+	}
+
+	out.Alerts = []topology.AlertInfo{}
+	for _, a := range resp.Alerts {
+		if services[a.Service] {
+			out.Alerts = append(out.Alerts, a)
+		}
+	}
+
+	out.Meta.NodeCount = len(out.Nodes)
+	out.Meta.EdgeCount = len(out.Edges)
+	return &out
+}
@@ -0,0 +1,49 @@
+package snapshot
+
+import (
+	"testing"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestFilter(t *testing.T) {
+	resp := &topology.TopologyResponse{
+		Nodes: []topology.Node{
+			{ID: "orders", Label: "orders", Type: "service", Namespace: "prod"},
+			{ID: "search", Label: "search", Type: "service", Namespace: "staging"},
+			{ID: "pg:5432", Label: "pg", Type: "postgres", Namespace: "db"},
+			{ID: "redis:6379", Label: "redis", Type: "redis", Namespace: "db"},
+		},
+		Edges: []topology.Edge{
+			{Source: "orders", Target: "pg:5432"},
+			{Source: "search", Target: "redis:6379"},
+			{Source: "search", Target: "orders"},
+		},
+		Alerts: []topology.AlertInfo{
+			{AlertName: "DependencyDown", Service: "orders"},
+			{AlertName: "DependencyDown", Service: "search"},
+		},
+	}
+
+	got := Filter(resp, "prod", "")
+	if len(got.Nodes) != 2 || got.Nodes[0].ID != "orders" || got.Nodes[1].ID != "pg:5432" {
+		t.Errorf("Nodes = %+v, want orders and pg", got.Nodes)
+	}
+	if len(got.Edges) != 1 || got.Edges[0].Target != "pg:5432" {
+		t.Errorf("Edges = %+v, want orders → pg", got.Edges)
+	}
+	if len(got.Alerts) != 1 || got.Alerts[0].Service != "orders" {
+		t.Errorf("Alerts = %+v, want the orders alert", got.Alerts)
+	}
+	if got.Meta.NodeCount != 2 || got.Meta.EdgeCount != 1 {
+		t.Errorf("Meta counts = %d/%d, want 2/1", got.Meta.NodeCount, got.Meta.EdgeCount)
+	}
+
+	// The original response is not modified.
+	if len(resp.Nodes) != 4 || len(resp.Edges) != 3 {
+		t.Error("Filter() modified its input")
+	}
+	if Filter(resp, "", "") != resp {
+		t.Error("Filter() without filters should return the input")
+	}
+}
@@ -0,0 +1,237 @@
+// Package snapshot implements a file-backed store of topology snapshots
+// used to serve historical views without re-querying Prometheus.
+package snapshot
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// fileSuffix is the extension of snapshot files; the base name is the Unix
+// timestamp of the snapshot in seconds.
+const fileSuffix = ".json.gz"
+
+// DownsampleRule keeps at most one snapshot per Resolution for snapshots
+// older than After.
+type DownsampleRule struct {
+	After      time.Duration
+	Resolution time.Duration
+}
+
+// Options configures a Store.
+type Options struct {
+	// Interval is the minimum time between two saved snapshots.
+	Interval time.Duration
+	// Retention is the maximum age of a snapshot.
+	Retention    time.Duration
+	Downsampling []DownsampleRule
+}
+
+// Store persists unfiltered TopologyResponse snapshots as gzipped JSON
+// files, one per snapshot, and keeps an in-memory index of their times.
+// It is safe for concurrent use.
+type Store struct {
+	dir  string
+	opts Options
+
+	mu    sync.RWMutex
+	times []int64 // sorted Unix seconds of stored snapshots
+}
+
+// Open opens (creating if needed) the snapshot directory and indexes the
+// snapshots already stored there.
+func Open(dir string, opts Options) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating snapshot dir: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot dir: %w", err)
+	}
+
+	rules := make([]DownsampleRule, len(opts.Downsampling))
+	copy(rules, opts.Downsampling)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].After < rules[j].After })
+	opts.Downsampling = rules
+
+	s := &Store{dir: dir, opts: opts}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, fileSuffix) {
+			continue
+		}
+		ts, err := strconv.ParseInt(strings.TrimSuffix(name, fileSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		s.times = append(s.times, ts)
+	}
+	sort.Slice(s.times, func(i, j int) bool { return s.times[i] < s.times[j] })
+	return s, nil
+}
+
+// Len returns the number of stored snapshots.
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.times)
+}
+
+// Save stores resp as the snapshot taken at at, unless the previous snapshot
+// is less than Interval old. Stale responses (served after a failed rebuild)
+// are not stored. After saving, retention and downsampling are applied.
+// It reports whether a snapshot was written.
+func (s *Store) Save(resp *topology.TopologyResponse, at time.Time) (bool, error) {
+	if resp == nil || resp.Meta.Stale {
+		return false, nil
+	}
+	ts := at.Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n := len(s.times); n > 0 && ts-s.times[n-1] < int64(s.opts.Interval/time.Second) {
+		return false, nil
+	}
+
+	if err := s.write(ts, resp); err != nil {
+		return false, err
+	}
+	s.times = append(s.times, ts)
+	sort.Slice(s.times, func(i, j int) bool { return s.times[i] < s.times[j] })
+
+	return true, s.compact(at)
+}
+
+// write stores resp atomically under the file name for ts.
+func (s *Store) write(ts int64, resp *topology.TopologyResponse) error {
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return fmt.Errorf("creating snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(resp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("compressing snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(ts)); err != nil {
+		return fmt.Errorf("storing snapshot: %w", err)
+	}
+	return nil
+}
+
+// Nearest returns the latest snapshot taken at or before at, provided it is
+// no older than the snapshot resolution at its age (Interval, or the
+// Resolution of the applicable downsampling rule). ok is false when no
+// snapshot covers at. The returned response is a fresh copy.
+func (s *Store) Nearest(at time.Time) (*topology.TopologyResponse, time.Time, bool, error) {
+	target := at.Unix()
+
+	s.mu.RLock()
+	i := sort.Search(len(s.times), func(i int) bool { return s.times[i] > target }) - 1
+	if i < 0 {
+		s.mu.RUnlock()
+		return nil, time.Time{}, false, nil
+	}
+	ts := s.times[i]
+	s.mu.RUnlock()
+
+	taken := time.Unix(ts, 0).UTC()
+	if at.Sub(taken) > s.resolution(time.Since(taken)) {
+		return nil, time.Time{}, false, nil
+	}
+
+	resp, err := s.read(ts)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	return resp, taken, true, nil
+}
+
+func (s *Store) read(ts int64) (*topology.TopologyResponse, error) {
+	f, err := os.Open(s.path(ts))
+	if err != nil {
+		return nil, fmt.Errorf("opening snapshot: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	var resp topology.TopologyResponse
+	if err := json.NewDecoder(zr).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decoding snapshot: %w", err)
+	}
+	return &resp, nil
+}
+
+// resolution returns the expected spacing of snapshots of the given age.
+func (s *Store) resolution(age time.Duration) time.Duration {
+	res := s.opts.Interval
+	for _, r := range s.opts.Downsampling {
+		if age >= r.After {
+			res = r.Resolution
+		}
+	}
+	return res
+}
+
+// compact deletes snapshots past retention and thins out snapshots covered
+// by downsampling rules, keeping the earliest snapshot of each resolution
+// bucket. Must be called with mu held.
+func (s *Store) compact(now time.Time) error {
+	var keep []int64
+	var firstErr error
+	lastBucket := map[time.Duration]int64{}
+	for _, ts := range s.times {
+		age := now.Sub(time.Unix(ts, 0))
+		drop := s.opts.Retention > 0 && age > s.opts.Retention
+		if !drop {
+			if res := s.resolution(age); res != s.opts.Interval {
+				bucket := ts / max(int64(res/time.Second), 1)
+				if b, ok := lastBucket[res]; ok && b == bucket {
+					drop = true
+				}
+				lastBucket[res] = bucket
+			}
+		}
+		if !drop {
+			keep = append(keep, ts)
+			continue
+		}
+		if err := os.Remove(s.path(ts)); err != nil && !os.IsNotExist(err) {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("removing snapshot: %w", err)
+			}
+			keep = append(keep, ts)
+		}
+	}
+	s.times = keep
+	return firstErr
+}
+
+func (s *Store) path(ts int64) string {
+	return filepath.Join(s.dir, strconv.FormatInt(ts, 10)+fileSuffix)
+}
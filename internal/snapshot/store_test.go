@@ -0,0 +1,134 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func testResponse(state string) *topology.TopologyResponse {
+	return &topology.TopologyResponse{
+		Nodes: []topology.Node{{ID: "orders", Label: "orders", Type: "service", State: state, Namespace: "prod"}},
+		Edges: []topology.Edge{},
+		Meta:  topology.TopologyMeta{NodeCount: 1},
+	}
+}
+
+func TestStoreSaveAndNearest(t *testing.T) {
+	st, err := Open(t.TempDir(), Options{Interval: time.Minute, Retention: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	t0 := now.Add(-10 * time.Minute)
+	if saved, err := st.Save(testResponse("ok"), t0); err != nil || !saved {
+		t.Fatalf("Save() = %v, %v; want saved", saved, err)
+	}
+	// Within Interval of the previous snapshot: skipped.
+	if saved, _ := st.Save(testResponse("down"), t0.Add(30*time.Second)); saved {
+		t.Error("Save() within interval should be skipped")
+	}
+	if saved, _ := st.Save(testResponse("down"), t0.Add(time.Minute)); !saved {
+		t.Error("Save() after interval should be stored")
+	}
+	if st.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", st.Len())
+	}
+
+	resp, takenAt, ok, err := st.Nearest(t0.Add(90 * time.Second))
+	if err != nil || !ok {
+		t.Fatalf("Nearest() = ok %v, err %v", ok, err)
+	}
+	if !takenAt.Equal(t0.Add(time.Minute)) || resp.Nodes[0].State != "down" {
+		t.Errorf("Nearest() = %v (%s), want snapshot at %v (down)", takenAt, resp.Nodes[0].State, t0.Add(time.Minute))
+	}
+
+	// Before the first snapshot, or too far after the last one: not covered.
+	if _, _, ok, _ := st.Nearest(t0.Add(-time.Second)); ok {
+		t.Error("Nearest() before first snapshot should not be covered")
+	}
+	if _, _, ok, _ := st.Nearest(t0.Add(5 * time.Minute)); ok {
+		t.Error("Nearest() beyond the interval should not be covered")
+	}
+}
+
+func TestStoreSkipsStale(t *testing.T) {
+	st, err := Open(t.TempDir(), Options{Interval: time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	resp := testResponse("ok")
+	resp.Meta.Stale = true
+	if saved, _ := st.Save(resp, time.Now()); saved || st.Len() != 0 {
+		t.Error("stale responses should not be stored")
+	}
+}
+
+func TestStoreReopen(t *testing.T) {
+	dir := t.TempDir()
+	st, _ := Open(dir, Options{Interval: time.Minute, Retention: time.Hour})
+	at := time.Now().Add(-time.Minute)
+	if _, err := st.Save(testResponse("ok"), at); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	// Unrelated files are ignored.
+	_ = os.WriteFile(filepath.Join(dir, "README"), []byte("x"), 0o644)
+
+	reopened, err := Open(dir, Options{Interval: time.Minute, Retention: time.Hour})
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+	if reopened.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", reopened.Len())
+	}
+	if _, _, ok, err := reopened.Nearest(at); !ok || err != nil {
+		t.Errorf("Nearest() after reopen = %v, %v", ok, err)
+	}
+}
+
+func TestStoreRetentionAndDownsampling(t *testing.T) {
+	dir := t.TempDir()
+	st, _ := Open(dir, Options{
+		Interval:     time.Minute,
+		Retention:    48 * time.Hour,
+		Downsampling: []DownsampleRule{{After: 24 * time.Hour, Resolution: time.Hour}},
+	})
+
+	now := time.Now().Truncate(time.Hour)
+	// One snapshot past retention, four within one hour past the
+	// downsampling age, and a recent one.
+	old := []time.Time{
+		now.Add(-72 * time.Hour),
+		now.Add(-30 * time.Hour),
+		now.Add(-30*time.Hour + 10*time.Minute),
+		now.Add(-30*time.Hour + 20*time.Minute),
+		now.Add(-30*time.Hour + 30*time.Minute),
+	}
+	for _, at := range old {
+		if err := st.write(at.Unix(), testResponse("ok")); err != nil {
+			t.Fatal(err)
+		}
+		st.times = append(st.times, at.Unix())
+	}
+	if _, err := st.Save(testResponse("ok"), now); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	// Past retention deleted; the downsampled hour keeps only its first snapshot.
+	if st.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", st.Len())
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+fileSuffix))
+	if len(files) != 2 {
+		t.Errorf("%d snapshot files on disk, want 2", len(files))
+	}
+
+	// Downsampled snapshots cover requests within their resolution.
+	if _, takenAt, ok, _ := st.Nearest(now.Add(-30*time.Hour + 45*time.Minute)); !ok || !takenAt.Equal(old[1]) {
+		t.Errorf("Nearest() in downsampled range = %v, %v; want %v", takenAt, ok, old[1])
+	}
+}
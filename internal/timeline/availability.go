@@ -0,0 +1,241 @@
+package timeline
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// AvailabilityRequest holds parameters for an availability report.
+type AvailabilityRequest struct {
+	EventsRequest
+	// CriticalWeight enables the weighted service score: critical edges count
+	// CriticalWeight times as much as non-critical ones. 0 disables the score.
+	CriticalWeight float64
+}
+
+// AvailabilityStats summarizes the sampled status of an edge or service.
+// Each status sample stands for one query step. An outage is a run of
+// non-"ok" samples; it is recovered when an "ok" sample follows it.
+type AvailabilityStats struct {
+	Availability    float64 `json:"availability"` // percentage of samples in "ok"
+	Samples         int     `json:"samples"`
+	DowntimeSeconds float64 `json:"downtimeSeconds"`
+	Outages         int     `json:"outages"`
+	MTTRSeconds     float64 `json:"mttrSeconds"` // mean time to recovery of recovered outages
+	MTBFSeconds     float64 `json:"mtbfSeconds"` // uptime divided by outages; 0 without outages
+}
+
+// EdgeAvailability is the availability of a single dependency edge.
+type EdgeAvailability struct {
+	Service    string `json:"service"`
+	Namespace  string `json:"namespace,omitempty"`
+	Dependency string `json:"dependency,omitempty"`
+	Type       string `json:"type,omitempty"`
+	Host       string `json:"host,omitempty"`
+	Port       string `json:"port,omitempty"`
+	Critical   bool   `json:"critical"`
+	Cluster    string `json:"cluster,omitempty"` // Prometheus source (with several sources)
+	AvailabilityStats
+}
+
+// ServiceAvailability is the availability of a service node. A service is
+// unavailable at a sample when any of its critical dependencies is not "ok";
+// services without critical dependencies consider all of them.
+type ServiceAvailability struct {
+	Service      string `json:"service"`
+	Namespace    string `json:"namespace,omitempty"`
+	Cluster      string `json:"cluster,omitempty"` // Prometheus source (with several sources)
+	Dependencies int    `json:"dependencies"`
+	AvailabilityStats
+	// Score is the criticality-weighted mean of the edge availabilities,
+	// set when AvailabilityRequest.CriticalWeight > 0.
+	Score *float64 `json:"score,omitempty"`
+}
+
+// AvailabilityReport is the result of QueryAvailability.
+type AvailabilityReport struct {
+	Start    time.Time             `json:"start"`
+	End      time.Time             `json:"end"`
+	Step     string                `json:"step"`
+	Edges    []EdgeAvailability    `json:"edges"`
+	Services []ServiceAvailability `json:"services"`
+}
+
+// QueryAvailability queries the dependency status metric over a time range
+// and computes availability, outage count, MTTR and MTBF per edge and per
+// service. Edges and services are sorted by service, then dependency.
+func QueryAvailability(ctx context.Context, prom topology.PrometheusClient, req AvailabilityRequest) (*AvailabilityReport, error) {
+	edges, sortedTS, err := collectEdges(ctx, prom, req.EventsRequest, false)
+	if err != nil {
+		return nil, err
+	}
+	step := AutoStep(req.End.Sub(req.Start))
+
+	report := &AvailabilityReport{
+		Start:    req.Start.UTC(),
+		End:      req.End.UTC(),
+		Step:     step.String(),
+		Edges:    []EdgeAvailability{},
+		Services: []ServiceAvailability{},
+	}
+
+	type serviceKey struct{ name, namespace string }
+	byService := make(map[serviceKey][]*edgeSeries)
+	for _, e := range edges {
+		report.Edges = append(report.Edges, EdgeAvailability{
+			Service:    e.info.Key.Name,
+			Namespace:  e.info.Namespace,
+			Dependency: e.info.Dependency,
+			Type:       e.info.Type,
+			Host:       e.info.Key.Host,
+			Port:       e.info.Key.Port,
+			Critical:   e.info.Critical,
+			AvailabilityStats: sampleStats(sortedTS, step, func(ts int64) (bool, bool) {
+				status, ok := e.status[ts]
+				return status == "ok", ok
+			}),
+		})
+		k := serviceKey{e.info.Key.Name, e.info.Namespace}
+		byService[k] = append(byService[k], e)
+	}
+
+	for k, svcEdges := range byService {
+		relevant := criticalOrAll(svcEdges)
+		svc := ServiceAvailability{
+			Service:      k.name,
+			Namespace:    k.namespace,
+			Dependencies: len(svcEdges),
+			AvailabilityStats: sampleStats(sortedTS, step, func(ts int64) (bool, bool) {
+				up, present := true, false
+				for _, e := range relevant {
+					if status, ok := e.status[ts]; ok {
+						present = true
+						up = up && status == "ok"
+					}
+				}
+				return up, present
+			}),
+		}
+		if req.CriticalWeight > 0 {
+			score := weightedScore(svcEdges, sortedTS, step, req.CriticalWeight)
+			svc.Score = &score
+		}
+		report.Services = append(report.Services, svc)
+	}
+
+	SortAvailability(report)
+
+	return report, nil
+}
+
+// criticalOrAll returns the critical edges, or all edges when none is critical.
+func criticalOrAll(edges []*edgeSeries) []*edgeSeries {
+	var critical []*edgeSeries
+	for _, e := range edges {
+		if e.info.Critical {
+			critical = append(critical, e)
+		}
+	}
+	if len(critical) == 0 {
+		return edges
+	}
+	return critical
+}
+
+// weightedScore returns the mean availability percentage of edges, with
+// critical edges weighted criticalWeight and others 1.
+func weightedScore(edges []*edgeSeries, sortedTS []int64, step time.Duration, criticalWeight float64) float64 {
+	var sum, weights float64
+	for _, e := range edges {
+		stats := sampleStats(sortedTS, step, func(ts int64) (bool, bool) {
+			status, ok := e.status[ts]
+			return status == "ok", ok
+		})
+		if stats.Samples == 0 {
+			continue
+		}
+		w := 1.0
+		if e.info.Critical {
+			w = criticalWeight
+		}
+		sum += w * stats.Availability
+		weights += w
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// sampleStats computes availability statistics over the sorted timestamps.
+// sample reports whether the entity was up at ts and whether it was sampled
+// at all; unsampled timestamps are skipped.
+func sampleStats(sortedTS []int64, step time.Duration, sample func(ts int64) (up, present bool)) AvailabilityStats {
+	var stats AvailabilityStats
+	var upSamples, recovered int
+	var repairTotal float64
+	downSince := int64(-1)
+	for _, ts := range sortedTS {
+		up, present := sample(ts)
+		if !present {
+			continue
+		}
+		stats.Samples++
+		switch {
+		case up && downSince >= 0:
+			repairTotal += float64(ts - downSince)
+			recovered++
+			downSince = -1
+			upSamples++
+		case up:
+			upSamples++
+		case downSince < 0:
+			downSince = ts
+			stats.Outages++
+		}
+	}
+	if stats.Samples == 0 {
+		return stats
+	}
+
+	stats.Availability = float64(upSamples) / float64(stats.Samples) * 100
+	stats.DowntimeSeconds = float64(stats.Samples-upSamples) * step.Seconds()
+	if recovered > 0 {
+		stats.MTTRSeconds = repairTotal / float64(recovered)
+	}
+	if stats.Outages > 0 {
+		stats.MTBFSeconds = float64(upSamples) * step.Seconds() / float64(stats.Outages)
+	}
+	return stats
+}
+
+// SortAvailability orders the edges and services of report by service, then
+// dependency (edges) or namespace (services), and source.
+func SortAvailability(report *AvailabilityReport) {
+	sort.Slice(report.Edges, func(i, j int) bool {
+		a, b := report.Edges[i], report.Edges[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Dependency != b.Dependency {
+			return a.Dependency < b.Dependency
+		}
+		if ea, eb := a.Host+":"+a.Port, b.Host+":"+b.Port; ea != eb {
+			return ea < eb
+		}
+		return a.Cluster < b.Cluster
+	})
+	sort.Slice(report.Services, func(i, j int) bool {
+		a, b := report.Services[i], report.Services[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Cluster < b.Cluster
+	})
+}
@@ -0,0 +1,117 @@
+package timeline
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestSampleStats(t *testing.T) {
+	step := time.Minute
+	// up, down, down, up, up, down, up, up → 2 outages, both recovered.
+	states := []bool{true, false, false, true, true, false, true, true}
+	ts := make([]int64, len(states))
+	for i := range states {
+		ts[i] = int64(i * 60)
+	}
+
+	stats := sampleStats(ts, step, func(t int64) (bool, bool) { return states[t/60], true })
+
+	if stats.Samples != 8 || stats.Outages != 2 {
+		t.Fatalf("samples/outages = %d/%d, want 8/2", stats.Samples, stats.Outages)
+	}
+	if stats.Availability != 62.5 {
+		t.Errorf("Availability = %v, want 62.5", stats.Availability)
+	}
+	if stats.DowntimeSeconds != 180 {
+		t.Errorf("DowntimeSeconds = %v, want 180", stats.DowntimeSeconds)
+	}
+	// Repairs: 60→180 (120s) and 300→360 (60s).
+	if stats.MTTRSeconds != 90 {
+		t.Errorf("MTTRSeconds = %v, want 90", stats.MTTRSeconds)
+	}
+	// Uptime 5 samples × 60s over 2 outages.
+	if stats.MTBFSeconds != 150 {
+		t.Errorf("MTBFSeconds = %v, want 150", stats.MTBFSeconds)
+	}
+}
+
+func TestSampleStats_UnrecoveredAndEmpty(t *testing.T) {
+	ts := []int64{0, 60, 120}
+	states := map[int64]bool{0: true, 60: false, 120: false}
+
+	stats := sampleStats(ts, time.Minute, func(t int64) (bool, bool) { return states[t], true })
+	if stats.Outages != 1 || stats.MTTRSeconds != 0 {
+		t.Errorf("outages/mttr = %d/%v, want 1/0 (not recovered)", stats.Outages, stats.MTTRSeconds)
+	}
+
+	empty := sampleStats(ts, time.Minute, func(int64) (bool, bool) { return false, false })
+	if empty.Samples != 0 || empty.Availability != 0 {
+		t.Errorf("empty stats = %+v, want zero", empty)
+	}
+}
+
+func TestQueryAvailability(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	step := 15 * time.Second
+
+	pg := statusSeries(topology.EdgeKey{Name: "orders", Host: "pg-main", Port: "5432"}, base, step,
+		"ok", "error", "ok", "ok")
+	cache := statusSeries(topology.EdgeKey{Name: "orders", Host: "redis", Port: "6379"}, base, step,
+		"ok", "ok", "ok", "timeout")
+	for i := range cache {
+		cache[i].Dependency = "redis"
+		cache[i].Critical = false
+	}
+
+	mock := &mockPromClient{statusRange: append(pg, cache...)}
+	report, err := QueryAvailability(context.Background(), mock, AvailabilityRequest{
+		EventsRequest:  EventsRequest{Start: base, End: base.Add(time.Minute)},
+		CriticalWeight: 3,
+	})
+	if err != nil {
+		t.Fatalf("QueryAvailability() error: %v", err)
+	}
+
+	if len(report.Edges) != 2 {
+		t.Fatalf("got %d edges, want 2", len(report.Edges))
+	}
+	if e := report.Edges[0]; e.Dependency != "postgres-main" || e.Availability != 75 || e.Outages != 1 || e.MTTRSeconds != 15 {
+		t.Errorf("edges[0] = %+v, want postgres-main 75%% with 1 outage, MTTR 15s", e)
+	}
+	if e := report.Edges[1]; e.Dependency != "redis" || e.Availability != 75 || e.MTTRSeconds != 0 {
+		t.Errorf("edges[1] = %+v, want redis 75%% without recovery", e)
+	}
+
+	if len(report.Services) != 1 {
+		t.Fatalf("got %d services, want 1", len(report.Services))
+	}
+	svc := report.Services[0]
+	// Only the critical postgres edge counts for service availability.
+	if svc.Service != "orders" || svc.Dependencies != 2 || svc.Availability != 75 || svc.Outages != 1 {
+		t.Errorf("service = %+v, want orders with 2 dependencies, 75%%, 1 outage", svc)
+	}
+	if svc.Score == nil || math.Abs(*svc.Score-75) > 1e-9 {
+		t.Errorf("service.Score = %v, want 75", svc.Score)
+	}
+}
+
+func TestQueryAvailability_NoScoreByDefault(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	mock := &mockPromClient{
+		statusRange: statusSeries(topology.EdgeKey{Name: "orders", Host: "pg-main", Port: "5432"}, base, 15*time.Second, "ok", "ok"),
+	}
+
+	report, err := QueryAvailability(context.Background(), mock, AvailabilityRequest{
+		EventsRequest: EventsRequest{Start: base, End: base.Add(time.Minute)},
+	})
+	if err != nil {
+		t.Fatalf("QueryAvailability() error: %v", err)
+	}
+	if len(report.Services) != 1 || report.Services[0].Score != nil || report.Services[0].Availability != 100 {
+		t.Errorf("services = %+v, want one fully available service without score", report.Services)
+	}
+}
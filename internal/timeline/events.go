@@ -3,20 +3,57 @@ package timeline
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 	"time"
 
 	"github.com/BigKAA/dephealth-ui/internal/topology"
 )
 
-// Event represents a state transition detected on the timeline.
+// Event represents a state transition of a single dependency edge detected
+// on the timeline.
 type Event struct {
-	Timestamp time.Time `json:"timestamp"`
-	Service   string    `json:"service"`
-	Namespace string    `json:"namespace,omitempty"`
-	FromState string    `json:"fromState"`
-	ToState   string    `json:"toState"`
-	Kind      string    `json:"kind"` // "degradation", "recovery", "change"
+	Timestamp  time.Time `json:"timestamp"`
+	Service    string    `json:"service"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Dependency string    `json:"dependency,omitempty"`
+	Type       string    `json:"type,omitempty"`
+	Host       string    `json:"host,omitempty"`
+	Port       string    `json:"port,omitempty"`
+	Critical   bool      `json:"critical"`
+	Cluster    string    `json:"cluster,omitempty"` // Prometheus source (with several sources)
+	FromState  string    `json:"fromState"`
+	ToState    string    `json:"toState"`
+	Detail     string    `json:"detail,omitempty"` // status detail after the transition, e.g. "http_503"
+	Kind       string    `json:"kind"`             // "degradation", "recovery", "change"
+	// Set on recovery events back to "ok" that close an outage.
+	OutageStart    *time.Time `json:"outageStart,omitempty"`
+	OutageDuration float64    `json:"outageDurationSeconds,omitempty"`
+}
+
+// Outage is a period during which a dependency edge was not "ok": it starts
+// with a transition away from "ok" and ends with the recovery back to "ok".
+// Outages still open at the end of the range are reported as ongoing, with
+// the duration measured up to the range end. An edge already not "ok" at its
+// first sample opens an outage there, marked StartTruncated since it began
+// earlier.
+type Outage struct {
+	Service         string     `json:"service"`
+	Namespace       string     `json:"namespace,omitempty"`
+	Dependency      string     `json:"dependency,omitempty"`
+	Type            string     `json:"type,omitempty"`
+	Host            string     `json:"host,omitempty"`
+	Port            string     `json:"port,omitempty"`
+	Critical        bool       `json:"critical"`
+	Cluster         string     `json:"cluster,omitempty"` // Prometheus source (with several sources)
+	Start           time.Time  `json:"start"`
+	StartTruncated  bool       `json:"startTruncated,omitempty"` // already down at the first sample
+	End             *time.Time `json:"end,omitempty"`            // nil while ongoing
+	Ongoing         bool       `json:"ongoing,omitempty"`
+	Duration        string     `json:"duration"` // human-readable "12m30s"
+	DurationSeconds float64    `json:"durationSeconds"`
+	WorstState      string     `json:"worstState"`       // worst status seen during the outage
+	Detail          string     `json:"detail,omitempty"` // status detail of the worst state
 }
 
 // EventsRequest holds parameters for querying timeline events.
@@ -84,51 +121,122 @@ func AutoStep(d time.Duration) time.Duration {
 // QueryStatusTransitions queries the dependency status metric over a time range
 // and detects state transitions. Returns a sorted list of events.
 func QueryStatusTransitions(ctx context.Context, prom topology.PrometheusClient, req EventsRequest) ([]Event, error) {
+	events, _, err := queryTransitions(ctx, prom, req)
+	return events, err
+}
+
+// QueryOutages queries the dependency status metric over a time range and
+// pairs degradation and recovery transitions into per-edge outages, sorted
+// by start time.
+func QueryOutages(ctx context.Context, prom topology.PrometheusClient, req EventsRequest) ([]Outage, error) {
+	_, outages, err := queryTransitions(ctx, prom, req)
+	return outages, err
+}
+
+// edgeSeries holds the identity and sampled status history of one edge.
+type edgeSeries struct {
+	info   topology.RangeResult // identity labels of the first series seen
+	status map[int64]string     // timestamp → active status
+	detail map[int64]string     // timestamp → active status detail
+}
+
+func (e *edgeSeries) event(ts time.Time, from, to string) Event {
+	return Event{
+		Timestamp:  ts,
+		Service:    e.info.Key.Name,
+		Namespace:  e.info.Namespace,
+		Dependency: e.info.Dependency,
+		Type:       e.info.Type,
+		Host:       e.info.Key.Host,
+		Port:       e.info.Key.Port,
+		Critical:   e.info.Critical,
+		FromState:  from,
+		ToState:    to,
+		Detail:     e.detail[ts.Unix()],
+		Kind:       classifyTransition(from, to),
+	}
+}
+
+func (e *edgeSeries) outage(start time.Time, state, detail string) *Outage {
+	return &Outage{
+		Service:    e.info.Key.Name,
+		Namespace:  e.info.Namespace,
+		Dependency: e.info.Dependency,
+		Type:       e.info.Type,
+		Host:       e.info.Key.Host,
+		Port:       e.info.Key.Port,
+		Critical:   e.info.Critical,
+		Start:      start,
+		WorstState: state,
+		Detail:     detail,
+	}
+}
+
+// closeOutage sets the end and duration of o.
+func closeOutage(o *Outage, end time.Time, ongoing bool) {
+	d := end.Sub(o.Start)
+	if !ongoing {
+		o.End = &end
+	}
+	o.Ongoing = ongoing
+	o.Duration = d.Round(time.Second).String()
+	o.DurationSeconds = d.Seconds()
+}
+
+// collectEdges runs the status range queries for req and returns the sampled
+// status history per edge together with the sorted union of sample timestamps.
+// withDetail also queries the optional status detail metric.
+func collectEdges(ctx context.Context, prom topology.PrometheusClient, req EventsRequest, withDetail bool) (map[topology.EdgeKey]*edgeSeries, []int64, error) {
 	rangeDuration := req.End.Sub(req.Start)
 	if rangeDuration <= 0 {
-		return nil, fmt.Errorf("invalid range: start must be before end")
+		return nil, nil, fmt.Errorf("invalid range: start must be before end")
 	}
 
 	step := AutoStep(rangeDuration)
 	results, err := prom.QueryStatusRange(ctx, req.Start, req.End, step, req.Namespace)
 	if err != nil {
-		return nil, fmt.Errorf("querying status range: %w", err)
+		return nil, nil, fmt.Errorf("querying status range: %w", err)
 	}
 
 	// Group range results by EdgeKey to track status changes per edge.
 	// Each edge can have multiple series (one per status value), but only
 	// the one with value == 1 is "active" at any given time.
-	type edgeKey struct {
-		Name string
-		Host string
-		Port string
-	}
-
-	// Build a map: edgeKey → timestamp → active status.
-	edgeStatusAtTime := make(map[edgeKey]map[int64]string)
-
+	edges := make(map[topology.EdgeKey]*edgeSeries)
 	for _, r := range results {
-		ek := edgeKey{
-			Name: r.Key.Name,
-			Host: r.Key.Host,
-			Port: r.Key.Port,
+		e, ok := edges[r.Key]
+		if !ok {
+			e = &edgeSeries{info: r, status: make(map[int64]string), detail: make(map[int64]string)}
+			edges[r.Key] = e
 		}
-
-		if _, ok := edgeStatusAtTime[ek]; !ok {
-			edgeStatusAtTime[ek] = make(map[int64]string)
-		}
-
 		for _, tv := range r.Values {
 			if tv.Value == 1 {
-				edgeStatusAtTime[ek][tv.Timestamp.Unix()] = r.Status
+				e.status[tv.Timestamp.Unix()] = r.Status
+			}
+		}
+	}
+
+	// Status detail is optional (older SDKs do not export it), so a failed
+	// detail query only leaves events without detail.
+	if withDetail {
+		if details, err := prom.QueryStatusDetailRange(ctx, req.Start, req.End, step, req.Namespace); err == nil {
+			for _, r := range details {
+				e, ok := edges[r.Key]
+				if !ok {
+					continue
+				}
+				for _, tv := range r.Values {
+					if tv.Value == 1 {
+						e.detail[tv.Timestamp.Unix()] = r.Detail
+					}
+				}
 			}
 		}
 	}
 
 	// Collect all unique timestamps across all edges.
 	allTimestamps := make(map[int64]bool)
-	for _, statusMap := range edgeStatusAtTime {
-		for ts := range statusMap {
+	for _, e := range edges {
+		for ts := range e.status {
 			allTimestamps[ts] = true
 		}
 	}
@@ -139,32 +247,97 @@ func QueryStatusTransitions(ctx context.Context, prom topology.PrometheusClient,
 	}
 	sort.Slice(sortedTS, func(i, j int) bool { return sortedTS[i] < sortedTS[j] })
 
-	// Detect transitions for each edge.
+	return edges, sortedTS, nil
+}
+
+// queryTransitions runs the status range queries and returns the detected
+// transition events and the outages paired from them.
+func queryTransitions(ctx context.Context, prom topology.PrometheusClient, req EventsRequest) ([]Event, []Outage, error) {
+	edges, sortedTS, err := collectEdges(ctx, prom, req, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Detect transitions for each edge, pairing transitions away from "ok"
+	// with the recovery back to "ok" into outages.
 	var events []Event
-	for ek, statusMap := range edgeStatusAtTime {
+	var outages []Outage
+	for _, e := range edges {
 		var prevStatus string
-		for _, ts := range sortedTS {
-			status, exists := statusMap[ts]
+		var open *Outage
+		for _, unix := range sortedTS {
+			status, exists := e.status[unix]
 			if !exists {
 				continue
 			}
+			if prevStatus == "" && status != "ok" {
+				// The outage began before the first sample.
+				open = e.outage(time.Unix(unix, 0).UTC(), status, e.detail[unix])
+				open.StartTruncated = true
+			}
 			if prevStatus != "" && status != prevStatus {
-				events = append(events, Event{
-					Timestamp: time.Unix(ts, 0).UTC(),
-					Service:   ek.Name,
-					FromState: prevStatus,
-					ToState:   status,
-					Kind:      classifyTransition(prevStatus, status),
-				})
+				ts := time.Unix(unix, 0).UTC()
+				ev := e.event(ts, prevStatus, status)
+				switch {
+				case prevStatus == "ok":
+					open = e.outage(ts, status, ev.Detail)
+				case open != nil && status == "ok":
+					closeOutage(open, ts, false)
+					ev.OutageStart = &open.Start
+					ev.OutageDuration = open.DurationSeconds
+					outages = append(outages, *open)
+					open = nil
+				case open != nil && statusSeverity(status) > statusSeverity(open.WorstState):
+					open.WorstState = status
+					open.Detail = ev.Detail
+				}
+				events = append(events, ev)
 			}
 			prevStatus = status
 		}
+		if open != nil {
+			closeOutage(open, req.End.UTC(), true)
+			outages = append(outages, *open)
+		}
 	}
 
-	// Sort events by timestamp.
-	sort.Slice(events, func(i, j int) bool {
-		return events[i].Timestamp.Before(events[j].Timestamp)
+	SortEvents(events)
+	SortOutages(outages)
+
+	return events, outages, nil
+}
+
+// SortEvents orders events by timestamp, breaking ties by edge so that the
+// order does not depend on map iteration. The sort is stable, so events of
+// the same edge and timestamp keep their order.
+func SortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		a, b := events[i], events[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return slices.Compare(a.edge(), b.edge()) < 0
 	})
+}
+
+// SortOutages orders outages by start time, breaking ties by edge like
+// SortEvents.
+func SortOutages(outages []Outage) {
+	sort.SliceStable(outages, func(i, j int) bool {
+		a, b := outages[i], outages[j]
+		if !a.Start.Equal(b.Start) {
+			return a.Start.Before(b.Start)
+		}
+		return slices.Compare(a.edge(), b.edge()) < 0
+	})
+}
+
+// edge returns the fields identifying the event's edge, in sort order.
+func (e Event) edge() []string {
+	return []string{e.Service, e.Namespace, e.Dependency, e.Host, e.Port, e.Cluster}
+}
 
-	return events, nil
+// edge returns the fields identifying the outage's edge, in sort order.
+func (o Outage) edge() []string {
+	return []string{o.Service, o.Namespace, o.Dependency, o.Host, o.Port, o.Cluster}
 }
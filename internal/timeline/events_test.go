@@ -2,6 +2,8 @@ package timeline
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -66,13 +68,19 @@ func TestClassifyTransition(t *testing.T) {
 // mockPromClient implements topology.PrometheusClient for timeline tests.
 type mockPromClient struct {
 	statusRange []topology.RangeResult
+	detailRange []topology.RangeResult
 	err         error
+	detailErr   error
 }
 
 func (m *mockPromClient) QueryStatusRange(_ context.Context, _, _ time.Time, _ time.Duration, _ string) ([]topology.RangeResult, error) {
 	return m.statusRange, m.err
 }
 
+func (m *mockPromClient) QueryStatusDetailRange(_ context.Context, _, _ time.Time, _ time.Duration, _ string) ([]topology.RangeResult, error) {
+	return m.detailRange, m.detailErr
+}
+
 // Stub methods to satisfy the full interface.
 func (m *mockPromClient) QueryTopologyEdges(_ context.Context, _ topology.QueryOptions) ([]topology.TopologyEdge, error) {
 	return nil, nil
@@ -83,7 +91,10 @@ func (m *mockPromClient) QueryHealthState(_ context.Context, _ topology.QueryOpt
 func (m *mockPromClient) QueryAvgLatency(_ context.Context, _ topology.QueryOptions) (map[topology.EdgeKey]float64, error) {
 	return nil, nil
 }
-func (m *mockPromClient) QueryP99Latency(_ context.Context, _ topology.QueryOptions) (map[topology.EdgeKey]float64, error) {
+func (m *mockPromClient) QueryLatencyQuantile(_ context.Context, _ topology.QueryOptions, _ float64) (map[topology.EdgeKey]float64, error) {
+	return nil, nil
+}
+func (m *mockPromClient) QueryErrorRatio(_ context.Context, _ topology.QueryOptions) (map[topology.EdgeKey]float64, error) {
 	return nil, nil
 }
 func (m *mockPromClient) QueryTopologyEdgesLookback(_ context.Context, _ topology.QueryOptions, _ time.Duration) ([]topology.TopologyEdge, error) {
@@ -270,3 +281,234 @@ func TestQueryStatusTransitions_Recovery(t *testing.T) {
 		t.Errorf("transition = %s→%s, want timeout→ok", events[0].FromState, events[0].ToState)
 	}
 }
+
+// statusSeries builds one status series per entry in states, where states[i]
+// is the active status at base + i*step.
+func statusSeries(key topology.EdgeKey, base time.Time, step time.Duration, states ...string) []topology.RangeResult {
+	byStatus := make(map[string]*topology.RangeResult)
+	var order []string
+	for i, st := range states {
+		r, ok := byStatus[st]
+		if !ok {
+			r = &topology.RangeResult{
+				Key:        key,
+				Namespace:  "prod",
+				Dependency: "postgres-main",
+				Type:       "postgres",
+				Critical:   true,
+				Status:     st,
+			}
+			byStatus[st] = r
+			order = append(order, st)
+		}
+		r.Values = append(r.Values, topology.TimeValue{Timestamp: base.Add(time.Duration(i) * step), Value: 1})
+	}
+	results := make([]topology.RangeResult, 0, len(order))
+	for _, st := range order {
+		results = append(results, *byStatus[st])
+	}
+	return results
+}
+
+func TestQueryStatusTransitions_EdgeIdentityAndDetail(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	key := topology.EdgeKey{Name: "order-service", Host: "pg-main", Port: "5432"}
+
+	mock := &mockPromClient{
+		statusRange: statusSeries(key, base, 15*time.Second, "ok", "connection_error"),
+		detailRange: []topology.RangeResult{{
+			Key:    key,
+			Detail: "connection_refused",
+			Values: []topology.TimeValue{{Timestamp: base.Add(15 * time.Second), Value: 1}},
+		}},
+	}
+
+	events, err := QueryStatusTransitions(context.Background(), mock, EventsRequest{Start: base, End: base.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("QueryStatusTransitions() error: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+
+	ev := events[0]
+	if ev.Service != "order-service" || ev.Namespace != "prod" || ev.Dependency != "postgres-main" ||
+		ev.Type != "postgres" || ev.Host != "pg-main" || ev.Port != "5432" || !ev.Critical {
+		t.Errorf("event identity = %+v, want order-service/prod → postgres-main (postgres, pg-main:5432, critical)", ev)
+	}
+	if ev.Detail != "connection_refused" {
+		t.Errorf("event.Detail = %q, want connection_refused", ev.Detail)
+	}
+}
+
+func TestQueryStatusTransitions_DetailQueryFailure(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	key := topology.EdgeKey{Name: "order-service", Host: "pg-main", Port: "5432"}
+
+	mock := &mockPromClient{
+		statusRange: statusSeries(key, base, 15*time.Second, "ok", "timeout"),
+		detailErr:   errors.New("detail metric unavailable"),
+	}
+
+	events, err := QueryStatusTransitions(context.Background(), mock, EventsRequest{Start: base, End: base.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("QueryStatusTransitions() error: %v", err)
+	}
+	if len(events) != 1 || events[0].Detail != "" {
+		t.Errorf("events = %+v, want one event without detail", events)
+	}
+}
+
+func TestQueryOutages_StartsInsideOutage(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	down := topology.EdgeKey{Name: "order-service", Host: "pg-main", Port: "5432"}
+	stuck := topology.EdgeKey{Name: "order-service", Host: "redis", Port: "6379"}
+	step := time.Minute
+
+	mock := &mockPromClient{
+		statusRange: append(
+			statusSeries(down, base, step, "error", "error", "ok", "ok"),
+			statusSeries(stuck, base, step, "timeout", "timeout", "timeout", "timeout")...),
+	}
+	req := EventsRequest{Start: base, End: base.Add(5 * step)}
+
+	outages, err := QueryOutages(context.Background(), mock, req)
+	if err != nil {
+		t.Fatalf("QueryOutages() error: %v", err)
+	}
+	byHost := make(map[string]Outage)
+	for _, o := range outages {
+		byHost[o.Host] = o
+	}
+	if len(outages) != 2 {
+		t.Fatalf("got %d outages, want 2: %+v", len(outages), outages)
+	}
+
+	o := byHost["pg-main"]
+	if !o.StartTruncated || !o.Start.Equal(base) || o.End == nil || !o.End.Equal(base.Add(2*step)) {
+		t.Errorf("pg-main outage = %+v, want truncated %v..%v", o, base, base.Add(2*step))
+	}
+	if o.WorstState != "error" || o.DurationSeconds != 120 {
+		t.Errorf("pg-main outage worst = %q, duration = %vs, want error, 120s", o.WorstState, o.DurationSeconds)
+	}
+
+	// Down for the whole range: truncated at both ends.
+	o = byHost["redis"]
+	if !o.StartTruncated || !o.Ongoing || o.End != nil || o.DurationSeconds != 300 {
+		t.Errorf("redis outage = %+v, want truncated, ongoing, 300s", o)
+	}
+
+	events, err := QueryStatusTransitions(context.Background(), mock, req)
+	if err != nil {
+		t.Fatalf("QueryStatusTransitions() error: %v", err)
+	}
+	if len(events) != 1 || events[0].OutageStart == nil || !events[0].OutageStart.Equal(base) || events[0].OutageDuration != 120 {
+		t.Errorf("events = %+v, want one pg-main recovery closing the outage from %v", events, base)
+	}
+}
+
+func TestQueryTransitions_OrderOnEqualTimestamps(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	step := time.Minute
+	var series []topology.RangeResult
+	for _, host := range []string{"pg-c", "pg-a", "pg-b"} {
+		key := topology.EdgeKey{Name: "order-service", Host: host, Port: "5432"}
+		series = append(series, statusSeries(key, base, step, "ok", "error", "ok")...)
+	}
+	mock := &mockPromClient{statusRange: series}
+	req := EventsRequest{Start: base, End: base.Add(3 * step)}
+
+	// Edges are collected in a map; repeat so a random order would show.
+	for range 10 {
+		events, err := QueryStatusTransitions(context.Background(), mock, req)
+		if err != nil {
+			t.Fatalf("QueryStatusTransitions() error: %v", err)
+		}
+		var got []string
+		for _, ev := range events {
+			got = append(got, ev.Host+" "+ev.ToState)
+		}
+		want := []string{"pg-a error", "pg-b error", "pg-c error", "pg-a ok", "pg-b ok", "pg-c ok"}
+		if !slices.Equal(got, want) {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+
+		outages, err := QueryOutages(context.Background(), mock, req)
+		if err != nil {
+			t.Fatalf("QueryOutages() error: %v", err)
+		}
+		got = got[:0]
+		for _, o := range outages {
+			got = append(got, o.Host)
+		}
+		if want := []string{"pg-a", "pg-b", "pg-c"}; !slices.Equal(got, want) {
+			t.Fatalf("outages = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestQueryOutages_PairsDegradationAndRecovery(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	key := topology.EdgeKey{Name: "order-service", Host: "pg-main", Port: "5432"}
+	step := time.Minute
+
+	mock := &mockPromClient{
+		statusRange: statusSeries(key, base, step,
+			"ok", "timeout", "error", "error", "ok", "ok", "unhealthy"),
+		detailRange: []topology.RangeResult{{
+			Key:    key,
+			Detail: "http_503",
+			Values: []topology.TimeValue{{Timestamp: base.Add(2 * step), Value: 1}},
+		}},
+	}
+	req := EventsRequest{Start: base, End: base.Add(10 * step)}
+
+	outages, err := QueryOutages(context.Background(), mock, req)
+	if err != nil {
+		t.Fatalf("QueryOutages() error: %v", err)
+	}
+	if len(outages) != 2 {
+		t.Fatalf("got %d outages, want 2", len(outages))
+	}
+
+	o := outages[0]
+	if !o.Start.Equal(base.Add(step)) || o.End == nil || !o.End.Equal(base.Add(4*step)) {
+		t.Errorf("outage[0] = %v..%v, want %v..%v", o.Start, o.End, base.Add(step), base.Add(4*step))
+	}
+	if o.DurationSeconds != 180 || o.Duration != "3m0s" {
+		t.Errorf("outage[0] duration = %q (%vs), want 3m0s (180s)", o.Duration, o.DurationSeconds)
+	}
+	if o.WorstState != "error" || o.Detail != "http_503" {
+		t.Errorf("outage[0] worst = %q/%q, want error/http_503", o.WorstState, o.Detail)
+	}
+	if o.Ongoing || o.Dependency != "postgres-main" || o.Host != "pg-main" {
+		t.Errorf("outage[0] = %+v, unexpected identity or ongoing flag", o)
+	}
+
+	// The second outage is still open at the end of the range.
+	o = outages[1]
+	if !o.Ongoing || o.End != nil {
+		t.Errorf("outage[1] ongoing = %v, end = %v, want ongoing without end", o.Ongoing, o.End)
+	}
+	if o.DurationSeconds != 240 {
+		t.Errorf("outage[1].DurationSeconds = %v, want 240 (up to range end)", o.DurationSeconds)
+	}
+
+	// The recovery event carries the outage it closes.
+	events, err := QueryStatusTransitions(context.Background(), mock, req)
+	if err != nil {
+		t.Fatalf("QueryStatusTransitions() error: %v", err)
+	}
+	var recovery *Event
+	for i := range events {
+		if events[i].ToState == "ok" {
+			recovery = &events[i]
+		}
+	}
+	if recovery == nil {
+		t.Fatal("no recovery event found")
+	}
+	if recovery.OutageStart == nil || !recovery.OutageStart.Equal(base.Add(step)) || recovery.OutageDuration != 180 {
+		t.Errorf("recovery outage = %v/%vs, want %v/180s", recovery.OutageStart, recovery.OutageDuration, base.Add(step))
+	}
+}
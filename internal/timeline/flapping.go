@@ -0,0 +1,83 @@
+package timeline
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// FlappingRequest holds parameters for listing flapping edges.
+type FlappingRequest struct {
+	Time      time.Time // end of the flap window
+	Namespace string
+	Config    config.FlappingConfig
+}
+
+// FlappingEdge is a dependency edge that is flapping at the request time.
+type FlappingEdge struct {
+	Service        string     `json:"service"`
+	Namespace      string     `json:"namespace,omitempty"`
+	Dependency     string     `json:"dependency,omitempty"`
+	Type           string     `json:"type,omitempty"`
+	Host           string     `json:"host,omitempty"`
+	Port           string     `json:"port,omitempty"`
+	Critical       bool       `json:"critical"`
+	Cluster        string     `json:"cluster,omitempty"` // Prometheus source (with several sources)
+	Status         string     `json:"status"`            // last sampled status
+	Transitions    int        `json:"transitions"`       // status transitions within the window
+	Since          time.Time  `json:"since"`             // when the edge started flapping
+	LastTransition *time.Time `json:"lastTransition,omitempty"`
+}
+
+// QueryFlapping runs flap detection for the window ending at req.Time and
+// returns the flapping edges, noisiest first.
+func QueryFlapping(ctx context.Context, prom topology.PrometheusClient, req FlappingRequest) ([]FlappingEdge, error) {
+	flaps, err := topology.DetectFlapping(ctx, prom, req.Config, req.Time, req.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []FlappingEdge{}
+	for _, f := range flaps {
+		if !f.Flapping {
+			continue
+		}
+		result = append(result, FlappingEdge{
+			Service:        f.Info.Key.Name,
+			Namespace:      f.Info.Namespace,
+			Dependency:     f.Info.Dependency,
+			Type:           f.Info.Type,
+			Host:           f.Info.Key.Host,
+			Port:           f.Info.Key.Port,
+			Critical:       f.Info.Critical,
+			Status:         f.Info.Status,
+			Transitions:    f.Transitions,
+			Since:          *f.Since,
+			LastTransition: f.LastTransition,
+		})
+	}
+
+	SortFlapping(result)
+	return result, nil
+}
+
+// SortFlapping orders flapping edges noisiest first, then by service,
+// dependency and source.
+func SortFlapping(edges []FlappingEdge) {
+	sort.Slice(edges, func(i, j int) bool {
+		a, b := edges[i], edges[j]
+		if a.Transitions != b.Transitions {
+			return a.Transitions > b.Transitions
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Dependency != b.Dependency {
+			return a.Dependency < b.Dependency
+		}
+		return a.Cluster < b.Cluster
+	})
+}
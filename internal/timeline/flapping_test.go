@@ -0,0 +1,50 @@
+package timeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func TestQueryFlapping(t *testing.T) {
+	noisy := topology.EdgeKey{Name: "order-service", Host: "pg", Port: "5432"}
+	stable := topology.EdgeKey{Name: "user-service", Host: "redis", Port: "6379"}
+	end := time.Date(2026, 1, 15, 13, 0, 0, 0, time.UTC)
+	base := end.Add(-20 * time.Minute)
+
+	toggling := []string{"ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok", "ok",
+		"timeout", "ok", "timeout", "ok", "timeout", "ok", "ok", "ok"}
+	steady := make([]string, 21)
+	for i := range steady {
+		steady[i] = "ok"
+	}
+	results := append(statusSeries(noisy, base, time.Minute, toggling...), statusSeries(stable, base, time.Minute, steady...)...)
+
+	mock := &mockPromClient{statusRange: results}
+	edges, err := QueryFlapping(context.Background(), mock, FlappingRequest{
+		Time:   end,
+		Config: config.FlappingConfig{Window: 10 * time.Minute, Threshold: 4, ClearThreshold: 1},
+	})
+	if err != nil {
+		t.Fatalf("QueryFlapping() error: %v", err)
+	}
+	if len(edges) != 1 {
+		t.Fatalf("edges = %+v, want only the toggling edge", edges)
+	}
+	e := edges[0]
+	if e.Service != "order-service" || e.Dependency != "postgres-main" || !e.Critical || e.Namespace != "prod" {
+		t.Errorf("edge identity = %+v", e)
+	}
+	if e.Transitions != 6 || e.Status != "ok" {
+		t.Errorf("transitions = %d, status = %q; want 6, ok", e.Transitions, e.Status)
+	}
+	if e.LastTransition == nil || !e.LastTransition.Equal(base.Add(18*time.Minute)) {
+		t.Errorf("lastTransition = %v, want %v", e.LastTransition, base.Add(18*time.Minute))
+	}
+	if e.Since.IsZero() {
+		t.Error("since should be set")
+	}
+}
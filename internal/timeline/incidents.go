@@ -0,0 +1,179 @@
+package timeline
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+// DefaultIncidentGap is the default time allowed between the end of one
+// outage and the start of the next for both to belong to the same incident.
+const DefaultIncidentGap = 5 * time.Minute
+
+// Incident groups edge outages that overlap in time (or follow each other
+// within the merge gap) into a single post-mortem unit.
+type Incident struct {
+	ID               string              `json:"id"`
+	Start            time.Time           `json:"start"`
+	End              *time.Time          `json:"end,omitempty"` // nil while ongoing
+	Ongoing          bool                `json:"ongoing,omitempty"`
+	Duration         string              `json:"duration"`
+	DurationSeconds  float64             `json:"durationSeconds"`
+	PeakState        string              `json:"peakState"`    // worst edge status during the incident
+	PeakSeverity     string              `json:"peakSeverity"` // "critical" when a critical dependency failed, else "warning"
+	AffectedServices []string            `json:"affectedServices"`
+	BlastRadius      int                 `json:"blastRadius"` // number of affected services
+	RootCauses       []cascade.RootCause `json:"rootCauses"`
+	AnalysisError    string              `json:"analysisError,omitempty"` // why root cause analysis is missing
+	Outages          []Outage            `json:"outages"`
+}
+
+// ClusterOutages merges outages into incidents. Outages are sorted by start;
+// an outage joins the current incident when it starts no later than gap after
+// the incident's latest end. Affected services are initially the services
+// owning the failed edges; AttachCascade extends them with upstream impact.
+func ClusterOutages(outages []Outage, gap time.Duration) []Incident {
+	sorted := make([]Outage, len(outages))
+	copy(sorted, outages)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	var incidents []Incident
+	var cur *Incident
+	var curEnd time.Time
+	for _, o := range sorted {
+		end := outageEnd(o)
+		if cur != nil && !o.Start.After(curEnd.Add(gap)) {
+			cur.Outages = append(cur.Outages, o)
+			if end.After(curEnd) {
+				curEnd = end
+			}
+			continue
+		}
+		if cur != nil {
+			finishIncident(cur, curEnd)
+			incidents = append(incidents, *cur)
+		}
+		cur = &Incident{
+			ID:         fmt.Sprintf("inc-%d", o.Start.Unix()),
+			Start:      o.Start,
+			RootCauses: []cascade.RootCause{},
+			Outages:    []Outage{o},
+		}
+		curEnd = end
+	}
+	if cur != nil {
+		finishIncident(cur, curEnd)
+		incidents = append(incidents, *cur)
+	}
+	return incidents
+}
+
+// outageEnd returns the end of o, or the range end it was measured to while ongoing.
+func outageEnd(o Outage) time.Time {
+	if o.End != nil {
+		return *o.End
+	}
+	return o.Start.Add(time.Duration(o.DurationSeconds * float64(time.Second)))
+}
+
+// finishIncident fills the derived fields of inc from its outages.
+func finishIncident(inc *Incident, end time.Time) {
+	seen := make(map[string]bool)
+	inc.PeakSeverity = "warning"
+	for _, o := range inc.Outages {
+		if o.Ongoing {
+			inc.Ongoing = true
+		}
+		if inc.PeakState == "" || statusSeverity(o.WorstState) > statusSeverity(inc.PeakState) {
+			inc.PeakState = o.WorstState
+		}
+		if o.Critical {
+			inc.PeakSeverity = "critical"
+		}
+		if !seen[o.Service] {
+			seen[o.Service] = true
+			inc.AffectedServices = append(inc.AffectedServices, o.Service)
+		}
+	}
+	if !inc.Ongoing {
+		inc.End = &end
+	}
+	d := end.Sub(inc.Start)
+	inc.Duration = d.Round(time.Second).String()
+	inc.DurationSeconds = d.Seconds()
+	sort.Strings(inc.AffectedServices)
+	inc.BlastRadius = len(inc.AffectedServices)
+}
+
+// AttachCascade records the root causes of a cascade analysis run on the
+// topology at incident start, keeping only those reachable from the failed
+// edges of the incident's outages, and adds the services they affect to the
+// blast radius. Root causes of unrelated failures elsewhere in the topology
+// are dropped.
+func (inc *Incident) AttachCascade(result *cascade.AnalysisResult, nodes []topology.Node, edges []topology.Edge) {
+	reachable := inc.reachable(nodes, edges)
+	inc.RootCauses = []cascade.RootCause{}
+	for _, rc := range result.RootCauses {
+		if reachable[rc.ID] {
+			inc.RootCauses = append(inc.RootCauses, rc)
+		}
+	}
+
+	seen := make(map[string]bool, len(inc.AffectedServices))
+	for _, s := range inc.AffectedServices {
+		seen[s] = true
+	}
+	for _, a := range result.AffectedServices {
+		if seen[a.Service] || !slices.ContainsFunc(a.RootCauses, func(id string) bool { return reachable[id] }) {
+			continue
+		}
+		seen[a.Service] = true
+		inc.AffectedServices = append(inc.AffectedServices, a.Service)
+	}
+	sort.Strings(inc.AffectedServices)
+	inc.BlastRadius = len(inc.AffectedServices)
+}
+
+// reachable returns the IDs of the nodes the incident's failed edges point
+// to, and of every node downstream of them.
+func (inc *Incident) reachable(nodes []topology.Node, edges []topology.Edge) map[string]bool {
+	nodeMap := make(map[string]topology.Node, len(nodes))
+	for _, n := range nodes {
+		nodeMap[n.ID] = n
+	}
+	outgoing := make(map[string][]string)
+	var queue []string
+	for _, e := range edges {
+		outgoing[e.Source] = append(outgoing[e.Source], e.Target)
+		for _, o := range inc.Outages {
+			if o.isEdge(nodeMap[e.Source], nodeMap[e.Target], e) {
+				queue = append(queue, e.Target)
+				break
+			}
+		}
+	}
+
+	reachable := make(map[string]bool)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		queue = append(queue, outgoing[id]...)
+	}
+	return reachable
+}
+
+// isEdge reports whether topology edge e from src to target is the edge of o.
+func (o Outage) isEdge(src, target topology.Node, e topology.Edge) bool {
+	if src.Label != o.Service || e.Cluster != o.Cluster || e.Dependency != o.Dependency {
+		return false
+	}
+	return target.Host == "" || (target.Host == o.Host && target.Port == o.Port)
+}
@@ -0,0 +1,133 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/cascade"
+	"github.com/BigKAA/dephealth-ui/internal/topology"
+)
+
+func testOutage(service string, start time.Time, d time.Duration, worst string, critical bool) Outage {
+	end := start.Add(d)
+	return Outage{
+		Service:         service,
+		Dependency:      "postgres-main",
+		Critical:        critical,
+		Start:           start,
+		End:             &end,
+		DurationSeconds: d.Seconds(),
+		WorstState:      worst,
+	}
+}
+
+func TestClusterOutages_MergesOverlapping(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	outages := []Outage{
+		testOutage("billing", base.Add(2*time.Minute), 10*time.Minute, "error", false),
+		testOutage("orders", base, 5*time.Minute, "timeout", true),
+		// Starts 3m after the first incident ends: within the 5m gap.
+		testOutage("orders", base.Add(15*time.Minute), time.Minute, "timeout", true),
+		// Starts well after: a separate incident.
+		testOutage("search", base.Add(time.Hour), 2*time.Minute, "timeout", false),
+	}
+
+	incidents := ClusterOutages(outages, 5*time.Minute)
+	if len(incidents) != 2 {
+		t.Fatalf("got %d incidents, want 2", len(incidents))
+	}
+
+	inc := incidents[0]
+	if !inc.Start.Equal(base) || inc.End == nil || !inc.End.Equal(base.Add(16*time.Minute)) {
+		t.Errorf("incident[0] = %v..%v, want %v..%v", inc.Start, inc.End, base, base.Add(16*time.Minute))
+	}
+	if inc.DurationSeconds != 960 {
+		t.Errorf("incident[0].DurationSeconds = %v, want 960", inc.DurationSeconds)
+	}
+	if len(inc.Outages) != 3 {
+		t.Errorf("incident[0] has %d outages, want 3", len(inc.Outages))
+	}
+	if inc.PeakState != "error" || inc.PeakSeverity != "critical" {
+		t.Errorf("incident[0] peak = %q/%q, want error/critical", inc.PeakState, inc.PeakSeverity)
+	}
+	if inc.BlastRadius != 2 || inc.AffectedServices[0] != "billing" || inc.AffectedServices[1] != "orders" {
+		t.Errorf("incident[0] affected = %v (%d), want [billing orders]", inc.AffectedServices, inc.BlastRadius)
+	}
+
+	if incidents[1].PeakSeverity != "warning" || incidents[1].ID == inc.ID {
+		t.Errorf("incident[1] = %+v, want a separate warning incident", incidents[1])
+	}
+}
+
+func TestClusterOutages_ZeroGapSplits(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	outages := []Outage{
+		testOutage("orders", base, 5*time.Minute, "timeout", true),
+		testOutage("billing", base.Add(6*time.Minute), time.Minute, "timeout", true),
+	}
+
+	if got := ClusterOutages(outages, 0); len(got) != 2 {
+		t.Errorf("got %d incidents, want 2", len(got))
+	}
+}
+
+func TestClusterOutages_Ongoing(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	ongoing := Outage{Service: "orders", Start: base, Ongoing: true, DurationSeconds: 600, WorstState: "error"}
+
+	incidents := ClusterOutages([]Outage{ongoing}, DefaultIncidentGap)
+	if len(incidents) != 1 {
+		t.Fatalf("got %d incidents, want 1", len(incidents))
+	}
+	if !incidents[0].Ongoing || incidents[0].End != nil || incidents[0].DurationSeconds != 600 {
+		t.Errorf("incident = %+v, want ongoing for 600s without end", incidents[0])
+	}
+}
+
+func TestIncidentAttachCascade(t *testing.T) {
+	base := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	o := testOutage("orders", base, time.Minute, "error", true)
+	o.Host, o.Port = "pg-main", "5432"
+	incidents := ClusterOutages([]Outage{o}, DefaultIncidentGap)
+	inc := &incidents[0]
+
+	// frontend → orders → pg-main is the incident; billing → redis failed
+	// at the same time but is unrelated.
+	nodes := []topology.Node{
+		{ID: "frontend", Label: "frontend", Type: "service"},
+		{ID: "orders", Label: "orders", Type: "service"},
+		{ID: "pg-main:5432", Label: "pg-main", Host: "pg-main", Port: "5432", State: "down"},
+		{ID: "billing", Label: "billing", Type: "service"},
+		{ID: "redis:6379", Label: "redis", Host: "redis", Port: "6379", State: "down"},
+	}
+	edges := []topology.Edge{
+		{Source: "frontend", Target: "orders", Dependency: "orders", Critical: true},
+		{Source: "orders", Target: "pg-main:5432", Dependency: "postgres-main", Critical: true},
+		{Source: "billing", Target: "redis:6379", Dependency: "redis", Critical: true},
+	}
+	inc.AttachCascade(&cascade.AnalysisResult{
+		RootCauses: []cascade.RootCause{
+			{ID: "pg-main:5432", Label: "pg-main", State: "down"},
+			{ID: "redis:6379", Label: "redis", State: "down"},
+		},
+		AffectedServices: []cascade.AffectedService{
+			{Service: "orders", RootCauses: []string{"pg-main:5432"}},
+			{Service: "frontend", RootCauses: []string{"pg-main:5432"}},
+			{Service: "billing", RootCauses: []string{"redis:6379"}},
+		},
+	}, nodes, edges)
+
+	if len(inc.RootCauses) != 1 || inc.RootCauses[0].Label != "pg-main" {
+		t.Errorf("RootCauses = %+v, want pg-main only", inc.RootCauses)
+	}
+	if inc.BlastRadius != 2 || inc.AffectedServices[0] != "frontend" || inc.AffectedServices[1] != "orders" {
+		t.Errorf("affected = %v (%d), want [frontend orders]", inc.AffectedServices, inc.BlastRadius)
+	}
+
+	// Without the failed edge in the topology no root cause is attributed.
+	other := ClusterOutages([]Outage{testOutage("search", base, time.Minute, "error", true)}, DefaultIncidentGap)
+	other[0].AttachCascade(&cascade.AnalysisResult{RootCauses: []cascade.RootCause{{ID: "redis:6379"}}}, nodes, edges)
+	if len(other[0].RootCauses) != 0 {
+		t.Errorf("RootCauses = %+v, want none for an unrelated incident", other[0].RootCauses)
+	}
+}
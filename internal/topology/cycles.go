@@ -0,0 +1,154 @@
+package topology
+
+import "sort"
+
+// Cycle is a strongly connected component of the dependency graph: a set of
+// nodes that all transitively depend on each other.
+type Cycle struct {
+	Nodes []string  `json:"nodes"` // sorted node IDs
+	Edges []EdgeRef `json:"edges"` // edges between members of the component
+	// Critical is set when the component contains a cycle made of critical
+	// edges only. Cascade analysis cannot pick a single root cause inside it.
+	Critical      bool      `json:"critical"`
+	CriticalEdges []EdgeRef `json:"criticalEdges,omitempty"` // edges on critical-only cycles
+}
+
+// FindCycles returns the dependency cycles of the graph, ordered by their
+// first node ID. A node depending on itself forms a cycle of one.
+func FindCycles(nodes []Node, edges []Edge) []Cycle {
+	all := components(nodes, edges, false)
+	crit := components(nodes, edges, true)
+
+	byComponent := make(map[int]*Cycle)
+	var order []int
+	for _, n := range nodes {
+		c, ok := all[n.ID]
+		if !ok {
+			continue
+		}
+		if byComponent[c] == nil {
+			byComponent[c] = &Cycle{Edges: []EdgeRef{}}
+			order = append(order, c)
+		}
+		byComponent[c].Nodes = append(byComponent[c].Nodes, n.ID)
+	}
+	for _, e := range edges {
+		if !sameComponent(all, e.Source, e.Target) {
+			continue
+		}
+		ref := EdgeRef{Source: e.Source, Target: e.Target}
+		cy := byComponent[all[e.Source]]
+		cy.Edges = append(cy.Edges, ref)
+		if e.Critical && sameComponent(crit, e.Source, e.Target) {
+			cy.Critical = true
+			cy.CriticalEdges = append(cy.CriticalEdges, ref)
+		}
+	}
+
+	cycles := make([]Cycle, 0, len(order))
+	for _, c := range order {
+		cy := byComponent[c]
+		sort.Strings(cy.Nodes)
+		sort.Slice(cy.Edges, func(i, j int) bool { return edgeRefLess(cy.Edges[i], cy.Edges[j]) })
+		sort.Slice(cy.CriticalEdges, func(i, j int) bool { return edgeRefLess(cy.CriticalEdges[i], cy.CriticalEdges[j]) })
+		cycles = append(cycles, *cy)
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i].Nodes[0] < cycles[j].Nodes[0] })
+	return cycles
+}
+
+// markCycles sets InCycle on nodes and edges that belong to a dependency
+// cycle, and CriticalCycle on edges of critical-only cycles.
+func markCycles(nodes []Node, edges []Edge) {
+	all := components(nodes, edges, false)
+	crit := components(nodes, edges, true)
+	for i := range nodes {
+		_, nodes[i].InCycle = all[nodes[i].ID]
+	}
+	for i := range edges {
+		e := &edges[i]
+		e.InCycle = sameComponent(all, e.Source, e.Target)
+		e.CriticalCycle = e.Critical && sameComponent(crit, e.Source, e.Target)
+	}
+}
+
+// sameComponent reports whether both nodes belong to the same cycle in comp.
+func sameComponent(comp map[string]int, a, b string) bool {
+	ca, ok := comp[a]
+	cb, okb := comp[b]
+	return ok && okb && ca == cb
+}
+
+// components runs Tarjan's strongly connected components algorithm and
+// returns the component index of every node that is part of a cycle; nodes
+// outside any cycle are absent. With criticalOnly set, non-critical edges
+// are ignored.
+func components(nodes []Node, edges []Edge, criticalOnly bool) map[string]int {
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n.ID] = true
+	}
+	graph := make(map[string][]string)
+	selfLoop := make(map[string]bool)
+	for _, e := range edges {
+		if (criticalOnly && !e.Critical) || !known[e.Source] || !known[e.Target] {
+			continue
+		}
+		if e.Source == e.Target {
+			selfLoop[e.Source] = true
+		}
+		graph[e.Source] = append(graph[e.Source], e.Target)
+	}
+
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	counter := 0
+	result := make(map[string]int)
+	component := 0
+
+	var connect func(v string)
+	connect = func(v string) {
+		index[v], low[v] = counter, counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range graph[v] {
+			if _, seen := index[w]; !seen {
+				connect(w)
+				low[v] = min(low[v], low[w])
+			} else if onStack[w] {
+				low[v] = min(low[v], index[w])
+			}
+		}
+
+		if low[v] != index[v] {
+			return
+		}
+		var members []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			members = append(members, w)
+			if w == v {
+				break
+			}
+		}
+		if len(members) > 1 || selfLoop[v] {
+			for _, m := range members {
+				result[m] = component
+			}
+			component++
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := index[n.ID]; !seen {
+			connect(n.ID)
+		}
+	}
+	return result
+}
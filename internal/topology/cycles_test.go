@@ -0,0 +1,66 @@
+package topology
+
+import "testing"
+
+func TestFindCycles(t *testing.T) {
+	nodes := []Node{{ID: "a"}, {ID: "b"}, {ID: "c"}, {ID: "d"}, {ID: "e"}, {ID: "pg"}}
+	edges := []Edge{
+		// a ⇄ b over critical edges; b → c → a closes a larger loop through a non-critical edge.
+		{Source: "a", Target: "b", Critical: true},
+		{Source: "b", Target: "a", Critical: true},
+		{Source: "b", Target: "c", Critical: true},
+		{Source: "c", Target: "a", Critical: false},
+		{Source: "c", Target: "pg", Critical: true},
+		// d ⇄ e only non-critical.
+		{Source: "d", Target: "e"},
+		{Source: "e", Target: "d"},
+	}
+
+	cycles := FindCycles(nodes, edges)
+	if len(cycles) != 2 {
+		t.Fatalf("cycles = %+v, want 2", cycles)
+	}
+
+	abc := cycles[0]
+	if len(abc.Nodes) != 3 || abc.Nodes[0] != "a" || abc.Nodes[2] != "c" {
+		t.Errorf("first cycle nodes = %v, want [a b c]", abc.Nodes)
+	}
+	if len(abc.Edges) != 4 {
+		t.Errorf("first cycle edges = %v, want 4", abc.Edges)
+	}
+	if !abc.Critical || len(abc.CriticalEdges) != 2 {
+		t.Errorf("first cycle critical = %v, criticalEdges = %v; want true, a⇄b", abc.Critical, abc.CriticalEdges)
+	}
+
+	de := cycles[1]
+	if de.Critical || len(de.Nodes) != 2 {
+		t.Errorf("second cycle = %+v, want non-critical d, e", de)
+	}
+
+	markCycles(nodes, edges)
+	for _, n := range nodes {
+		if want := n.ID != "pg"; n.InCycle != want {
+			t.Errorf("node %s InCycle = %v, want %v", n.ID, n.InCycle, want)
+		}
+	}
+	for _, e := range edges {
+		wantIn := e.Target != "pg"
+		wantCrit := (e.Source == "a" && e.Target == "b") || (e.Source == "b" && e.Target == "a")
+		if e.InCycle != wantIn || e.CriticalCycle != wantCrit {
+			t.Errorf("edge %s→%s InCycle = %v, CriticalCycle = %v; want %v, %v",
+				e.Source, e.Target, e.InCycle, e.CriticalCycle, wantIn, wantCrit)
+		}
+	}
+}
+
+func TestFindCycles_SelfLoopAndAcyclic(t *testing.T) {
+	nodes := []Node{{ID: "a"}, {ID: "b"}}
+	if cycles := FindCycles(nodes, []Edge{{Source: "a", Target: "b", Critical: true}}); len(cycles) != 0 {
+		t.Errorf("acyclic graph: cycles = %+v, want none", cycles)
+	}
+
+	cycles := FindCycles(nodes, []Edge{{Source: "a", Target: "a", Critical: true}})
+	if len(cycles) != 1 || len(cycles[0].Nodes) != 1 || !cycles[0].Critical {
+		t.Errorf("self loop: cycles = %+v, want one critical cycle of a", cycles)
+	}
+}
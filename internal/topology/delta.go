@@ -0,0 +1,157 @@
+package topology
+
+import "sort"
+
+// EdgeRef identifies a graph edge by its endpoints.
+type EdgeRef struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// NodeDelta lists node changes between two topology snapshots.
+// Changed nodes carry their full new representation.
+type NodeDelta struct {
+	Added   []Node   `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"` // node IDs
+	Changed []Node   `json:"changed,omitempty"`
+}
+
+// EdgeDelta lists edge changes between two topology snapshots.
+// Changed edges carry their full new representation.
+type EdgeDelta struct {
+	Added   []Edge    `json:"added,omitempty"`
+	Removed []EdgeRef `json:"removed,omitempty"`
+	Changed []Edge    `json:"changed,omitempty"`
+}
+
+// AlertDelta lists alerts that appeared or resolved between two topology snapshots.
+type AlertDelta struct {
+	Added   []AlertInfo `json:"added,omitempty"`
+	Removed []AlertInfo `json:"removed,omitempty"`
+}
+
+// TopologyDelta describes how to turn one TopologyResponse into the next.
+// Meta always reflects the newer snapshot.
+type TopologyDelta struct {
+	Nodes  NodeDelta    `json:"nodes"`
+	Edges  EdgeDelta    `json:"edges"`
+	Alerts AlertDelta   `json:"alerts"`
+	Meta   TopologyMeta `json:"meta"`
+}
+
+// Empty reports whether the delta contains no node, edge or alert changes.
+func (d *TopologyDelta) Empty() bool {
+	return len(d.Nodes.Added) == 0 && len(d.Nodes.Removed) == 0 && len(d.Nodes.Changed) == 0 &&
+		len(d.Edges.Added) == 0 && len(d.Edges.Removed) == 0 && len(d.Edges.Changed) == 0 &&
+		len(d.Alerts.Added) == 0 && len(d.Alerts.Removed) == 0
+}
+
+// ComputeDelta returns the node, edge and alert changes from prev to next.
+// Edges are compared without their latency, error ratio and transition count fields: these drift
+// on every build and are not considered a state change. Results are sorted by ID so the
+// output is deterministic.
+func ComputeDelta(prev, next *TopologyResponse) *TopologyDelta {
+	d := &TopologyDelta{Meta: next.Meta}
+
+	prevNodes := make(map[string]Node, len(prev.Nodes))
+	for _, n := range prev.Nodes {
+		prevNodes[n.ID] = n
+	}
+	nextNodes := make(map[string]bool, len(next.Nodes))
+	for _, n := range next.Nodes {
+		nextNodes[n.ID] = true
+		old, ok := prevNodes[n.ID]
+		switch {
+		case !ok:
+			d.Nodes.Added = append(d.Nodes.Added, n)
+		case old != n:
+			d.Nodes.Changed = append(d.Nodes.Changed, n)
+		}
+	}
+	for _, n := range prev.Nodes {
+		if !nextNodes[n.ID] {
+			d.Nodes.Removed = append(d.Nodes.Removed, n.ID)
+		}
+	}
+
+	prevEdges := make(map[EdgeRef]Edge, len(prev.Edges))
+	for _, e := range prev.Edges {
+		prevEdges[EdgeRef{e.Source, e.Target}] = e
+	}
+	nextEdges := make(map[EdgeRef]bool, len(next.Edges))
+	for _, e := range next.Edges {
+		ref := EdgeRef{e.Source, e.Target}
+		nextEdges[ref] = true
+		old, ok := prevEdges[ref]
+		switch {
+		case !ok:
+			d.Edges.Added = append(d.Edges.Added, e)
+		case withoutMeasurements(old) != withoutMeasurements(e):
+			d.Edges.Changed = append(d.Edges.Changed, e)
+		}
+	}
+	for _, e := range prev.Edges {
+		ref := EdgeRef{e.Source, e.Target}
+		if !nextEdges[ref] {
+			d.Edges.Removed = append(d.Edges.Removed, ref)
+		}
+	}
+
+	prevAlerts := make(map[AlertInfo]bool, len(prev.Alerts))
+	for _, a := range prev.Alerts {
+		prevAlerts[a] = true
+	}
+	nextAlerts := make(map[AlertInfo]bool, len(next.Alerts))
+	for _, a := range next.Alerts {
+		nextAlerts[a] = true
+		if !prevAlerts[a] {
+			d.Alerts.Added = append(d.Alerts.Added, a)
+		}
+	}
+	for _, a := range prev.Alerts {
+		if !nextAlerts[a] {
+			d.Alerts.Removed = append(d.Alerts.Removed, a)
+		}
+	}
+
+	sortNodes(d.Nodes.Added)
+	sortNodes(d.Nodes.Changed)
+	sort.Strings(d.Nodes.Removed)
+	sortEdges(d.Edges.Added)
+	sortEdges(d.Edges.Changed)
+	sort.Slice(d.Edges.Removed, func(i, j int) bool {
+		return edgeRefLess(d.Edges.Removed[i], d.Edges.Removed[j])
+	})
+
+	return d
+}
+
+// withoutMeasurements returns a copy of e with continuously varying
+// measurements (latency, error ratio) cleared for comparison.
+func withoutMeasurements(e Edge) Edge {
+	e.Latency = ""
+	e.LatencyRaw = 0
+	e.LatencyP50 = 0
+	e.LatencyP95 = 0
+	e.LatencyP99 = 0
+	e.ErrorRatio = 0
+	e.Transitions = 0
+	return e
+}
+
+func sortNodes(nodes []Node) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+}
+
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		return edgeRefLess(EdgeRef{edges[i].Source, edges[i].Target}, EdgeRef{edges[j].Source, edges[j].Target})
+	})
+}
+
+func edgeRefLess(a, b EdgeRef) bool {
+	if a.Source != b.Source {
+		return a.Source < b.Source
+	}
+	return a.Target < b.Target
+}
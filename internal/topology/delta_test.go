@@ -0,0 +1,88 @@
+package topology
+
+import "testing"
+
+func TestComputeDelta_NoChanges(t *testing.T) {
+	resp := &TopologyResponse{
+		Nodes:  []Node{{ID: "svc-a", State: "ok"}, {ID: "pg:5432", State: "ok"}},
+		Edges:  []Edge{{Source: "svc-a", Target: "pg:5432", State: "ok", Health: 1, LatencyRaw: 0.005, Latency: "5.0ms"}},
+		Alerts: []AlertInfo{{AlertName: "DependencyDown", Service: "svc-a", Dependency: "pg"}},
+	}
+	d := ComputeDelta(resp, resp)
+	if !d.Empty() {
+		t.Errorf("expected empty delta, got %+v", d)
+	}
+}
+
+func TestComputeDelta_IgnoresLatencyDrift(t *testing.T) {
+	prev := &TopologyResponse{
+		Edges: []Edge{{Source: "svc-a", Target: "pg:5432", State: "ok", LatencyRaw: 0.005, Latency: "5.0ms"}},
+	}
+	next := &TopologyResponse{
+		Edges: []Edge{{Source: "svc-a", Target: "pg:5432", State: "ok", LatencyRaw: 0.007, Latency: "7.0ms"}},
+	}
+	if d := ComputeDelta(prev, next); !d.Empty() {
+		t.Errorf("latency-only change should produce empty delta, got %+v", d.Edges)
+	}
+}
+
+func TestComputeDelta_AddedRemovedChanged(t *testing.T) {
+	prev := &TopologyResponse{
+		Nodes: []Node{
+			{ID: "svc-a", State: "ok"},
+			{ID: "svc-b", State: "ok"},
+			{ID: "redis:6379", State: "ok"},
+		},
+		Edges: []Edge{
+			{Source: "svc-a", Target: "svc-b", State: "ok", Health: 1},
+			{Source: "svc-b", Target: "redis:6379", State: "ok", Health: 1},
+		},
+		Alerts: []AlertInfo{{AlertName: "Old", Service: "svc-b"}},
+	}
+	next := &TopologyResponse{
+		Nodes: []Node{
+			{ID: "svc-a", State: "degraded"},
+			{ID: "svc-b", State: "ok"},
+			{ID: "pg:5432", State: "ok"},
+		},
+		Edges: []Edge{
+			{Source: "svc-a", Target: "svc-b", State: "down", Health: 0},
+			{Source: "svc-b", Target: "pg:5432", State: "ok", Health: 1},
+		},
+		Alerts: []AlertInfo{{AlertName: "New", Service: "svc-a"}},
+		Meta:   TopologyMeta{NodeCount: 3},
+	}
+
+	d := ComputeDelta(prev, next)
+
+	if len(d.Nodes.Added) != 1 || d.Nodes.Added[0].ID != "pg:5432" {
+		t.Errorf("Nodes.Added = %+v, want [pg:5432]", d.Nodes.Added)
+	}
+	if len(d.Nodes.Removed) != 1 || d.Nodes.Removed[0] != "redis:6379" {
+		t.Errorf("Nodes.Removed = %v, want [redis:6379]", d.Nodes.Removed)
+	}
+	if len(d.Nodes.Changed) != 1 || d.Nodes.Changed[0].ID != "svc-a" || d.Nodes.Changed[0].State != "degraded" {
+		t.Errorf("Nodes.Changed = %+v, want [svc-a degraded]", d.Nodes.Changed)
+	}
+	if len(d.Edges.Added) != 1 || d.Edges.Added[0].Target != "pg:5432" {
+		t.Errorf("Edges.Added = %+v, want svc-b→pg:5432", d.Edges.Added)
+	}
+	if len(d.Edges.Removed) != 1 || d.Edges.Removed[0] != (EdgeRef{Source: "svc-b", Target: "redis:6379"}) {
+		t.Errorf("Edges.Removed = %+v, want svc-b→redis:6379", d.Edges.Removed)
+	}
+	if len(d.Edges.Changed) != 1 || d.Edges.Changed[0].State != "down" {
+		t.Errorf("Edges.Changed = %+v, want svc-a→svc-b down", d.Edges.Changed)
+	}
+	if len(d.Alerts.Added) != 1 || d.Alerts.Added[0].AlertName != "New" {
+		t.Errorf("Alerts.Added = %+v, want [New]", d.Alerts.Added)
+	}
+	if len(d.Alerts.Removed) != 1 || d.Alerts.Removed[0].AlertName != "Old" {
+		t.Errorf("Alerts.Removed = %+v, want [Old]", d.Alerts.Removed)
+	}
+	if d.Meta.NodeCount != 3 {
+		t.Errorf("Meta.NodeCount = %d, want 3 (meta of next snapshot)", d.Meta.NodeCount)
+	}
+	if d.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+}
@@ -0,0 +1,202 @@
+package topology
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// NodeStateChange describes a node present at both points in time whose state changed.
+type NodeStateChange struct {
+	ID        string `json:"id"`
+	Label     string `json:"label"`
+	Type      string `json:"type"`
+	Namespace string `json:"namespace"`
+	FromState string `json:"fromState"`
+	ToState   string `json:"toState"`
+}
+
+// EdgeStateChange describes an edge present at both points in time whose state changed.
+type EdgeStateChange struct {
+	Source    string `json:"source"`
+	Target    string `json:"target"`
+	Type      string `json:"type,omitempty"`
+	FromState string `json:"fromState"`
+	ToState   string `json:"toState"`
+}
+
+// CriticalityChange describes an edge whose critical flag changed.
+type CriticalityChange struct {
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	Type         string `json:"type,omitempty"`
+	FromCritical bool   `json:"fromCritical"`
+	ToCritical   bool   `json:"toCritical"`
+}
+
+// LatencyChange describes the latency difference of an edge present at both points in time.
+// Latency values are in seconds.
+type LatencyChange struct {
+	Source      string  `json:"source"`
+	Target      string  `json:"target"`
+	Type        string  `json:"type,omitempty"`
+	FromLatency float64 `json:"fromLatency"`
+	ToLatency   float64 `json:"toLatency"`
+	Delta       float64 `json:"delta"`
+}
+
+// DiffSummary provides aggregate counts for a TopologyDiff.
+type DiffSummary struct {
+	AddedNodes         int `json:"addedNodes"`
+	RemovedNodes       int `json:"removedNodes"`
+	AddedEdges         int `json:"addedEdges"`
+	RemovedEdges       int `json:"removedEdges"`
+	NodeStateChanges   int `json:"nodeStateChanges"`
+	EdgeStateChanges   int `json:"edgeStateChanges"`
+	CriticalityChanges int `json:"criticalityChanges"`
+	LatencyChanges     int `json:"latencyChanges"`
+}
+
+// TopologyDiff describes how the dependency graph changed between two points in time.
+type TopologyDiff struct {
+	From               time.Time           `json:"from"`
+	To                 time.Time           `json:"to"`
+	AddedNodes         []Node              `json:"addedNodes"`
+	RemovedNodes       []Node              `json:"removedNodes"`
+	AddedEdges         []Edge              `json:"addedEdges"`
+	RemovedEdges       []Edge              `json:"removedEdges"`
+	NodeStateChanges   []NodeStateChange   `json:"nodeStateChanges"`
+	EdgeStateChanges   []EdgeStateChange   `json:"edgeStateChanges"`
+	CriticalityChanges []CriticalityChange `json:"criticalityChanges"`
+	LatencyChanges     []LatencyChange     `json:"latencyChanges"`
+	Summary            DiffSummary         `json:"summary"`
+}
+
+// DiffTopologies compares two topology snapshots taken at from and to.
+// Latency changes are reported for every edge present in both snapshots whose
+// latency differs, ordered by the absolute delta (largest first).
+func DiffTopologies(fromResp, toResp *TopologyResponse, from, to time.Time) *TopologyDiff {
+	d := &TopologyDiff{
+		From:               from,
+		To:                 to,
+		AddedNodes:         []Node{},
+		RemovedNodes:       []Node{},
+		AddedEdges:         []Edge{},
+		RemovedEdges:       []Edge{},
+		NodeStateChanges:   []NodeStateChange{},
+		EdgeStateChanges:   []EdgeStateChange{},
+		CriticalityChanges: []CriticalityChange{},
+		LatencyChanges:     []LatencyChange{},
+	}
+
+	fromNodes := make(map[string]Node, len(fromResp.Nodes))
+	for _, n := range fromResp.Nodes {
+		fromNodes[n.ID] = n
+	}
+	toNodes := make(map[string]bool, len(toResp.Nodes))
+	for _, n := range toResp.Nodes {
+		toNodes[n.ID] = true
+		old, ok := fromNodes[n.ID]
+		if !ok {
+			d.AddedNodes = append(d.AddedNodes, n)
+			continue
+		}
+		if old.State != n.State {
+			d.NodeStateChanges = append(d.NodeStateChanges, NodeStateChange{
+				ID:        n.ID,
+				Label:     n.Label,
+				Type:      n.Type,
+				Namespace: n.Namespace,
+				FromState: old.State,
+				ToState:   n.State,
+			})
+		}
+	}
+	for _, n := range fromResp.Nodes {
+		if !toNodes[n.ID] {
+			d.RemovedNodes = append(d.RemovedNodes, n)
+		}
+	}
+
+	fromEdges := make(map[EdgeRef]Edge, len(fromResp.Edges))
+	for _, e := range fromResp.Edges {
+		fromEdges[EdgeRef{e.Source, e.Target}] = e
+	}
+	toEdges := make(map[EdgeRef]bool, len(toResp.Edges))
+	for _, e := range toResp.Edges {
+		ref := EdgeRef{e.Source, e.Target}
+		toEdges[ref] = true
+		old, ok := fromEdges[ref]
+		if !ok {
+			d.AddedEdges = append(d.AddedEdges, e)
+			continue
+		}
+		if old.State != e.State {
+			d.EdgeStateChanges = append(d.EdgeStateChanges, EdgeStateChange{
+				Source:    e.Source,
+				Target:    e.Target,
+				Type:      e.Type,
+				FromState: old.State,
+				ToState:   e.State,
+			})
+		}
+		if old.Critical != e.Critical {
+			d.CriticalityChanges = append(d.CriticalityChanges, CriticalityChange{
+				Source:       e.Source,
+				Target:       e.Target,
+				Type:         e.Type,
+				FromCritical: old.Critical,
+				ToCritical:   e.Critical,
+			})
+		}
+		if old.LatencyRaw != e.LatencyRaw {
+			d.LatencyChanges = append(d.LatencyChanges, LatencyChange{
+				Source:      e.Source,
+				Target:      e.Target,
+				Type:        e.Type,
+				FromLatency: old.LatencyRaw,
+				ToLatency:   e.LatencyRaw,
+				Delta:       e.LatencyRaw - old.LatencyRaw,
+			})
+		}
+	}
+	for _, e := range fromResp.Edges {
+		if !toEdges[EdgeRef{e.Source, e.Target}] {
+			d.RemovedEdges = append(d.RemovedEdges, e)
+		}
+	}
+
+	sortNodes(d.AddedNodes)
+	sortNodes(d.RemovedNodes)
+	sortEdges(d.AddedEdges)
+	sortEdges(d.RemovedEdges)
+	sort.Slice(d.NodeStateChanges, func(i, j int) bool { return d.NodeStateChanges[i].ID < d.NodeStateChanges[j].ID })
+	sort.Slice(d.EdgeStateChanges, func(i, j int) bool {
+		a, b := d.EdgeStateChanges[i], d.EdgeStateChanges[j]
+		return edgeRefLess(EdgeRef{a.Source, a.Target}, EdgeRef{b.Source, b.Target})
+	})
+	sort.Slice(d.CriticalityChanges, func(i, j int) bool {
+		a, b := d.CriticalityChanges[i], d.CriticalityChanges[j]
+		return edgeRefLess(EdgeRef{a.Source, a.Target}, EdgeRef{b.Source, b.Target})
+	})
+	sort.Slice(d.LatencyChanges, func(i, j int) bool {
+		a, b := d.LatencyChanges[i], d.LatencyChanges[j]
+		if da, db := math.Abs(a.Delta), math.Abs(b.Delta); da != db {
+			return da > db
+		}
+		return edgeRefLess(EdgeRef{a.Source, a.Target}, EdgeRef{b.Source, b.Target})
+	})
+
+	d.Summary = DiffSummary{
+		AddedNodes:         len(d.AddedNodes),
+		RemovedNodes:       len(d.RemovedNodes),
+		AddedEdges:         len(d.AddedEdges),
+		RemovedEdges:       len(d.RemovedEdges),
+		NodeStateChanges:   len(d.NodeStateChanges),
+		EdgeStateChanges:   len(d.EdgeStateChanges),
+		CriticalityChanges: len(d.CriticalityChanges),
+		LatencyChanges:     len(d.LatencyChanges),
+	}
+
+	return d
+}
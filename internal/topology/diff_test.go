@@ -0,0 +1,115 @@
+package topology
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiffTopologies(t *testing.T) {
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	before := &TopologyResponse{
+		Nodes: []Node{
+			{ID: "svc-a", Label: "svc-a", Type: "service", State: "ok"},
+			{ID: "svc-b", Label: "svc-b", Type: "service", State: "ok"},
+			{ID: "redis:6379", Label: "redis", Type: "redis", State: "ok"},
+		},
+		Edges: []Edge{
+			{Source: "svc-a", Target: "svc-b", Type: "http", State: "ok", Critical: true, LatencyRaw: 0.010},
+			{Source: "svc-a", Target: "redis:6379", Type: "redis", State: "ok", Critical: false, LatencyRaw: 0.001},
+			{Source: "svc-b", Target: "redis:6379", Type: "redis", State: "ok", LatencyRaw: 0.002},
+		},
+	}
+	after := &TopologyResponse{
+		Nodes: []Node{
+			{ID: "svc-a", Label: "svc-a", Type: "service", State: "degraded"},
+			{ID: "svc-b", Label: "svc-b", Type: "service", State: "ok"},
+			{ID: "pg:5432", Label: "postgres", Type: "postgres", State: "ok"},
+		},
+		Edges: []Edge{
+			{Source: "svc-a", Target: "svc-b", Type: "http", State: "down", Critical: true, LatencyRaw: 0.050},
+			{Source: "svc-a", Target: "redis:6379", Type: "redis", State: "ok", Critical: true, LatencyRaw: 0.002},
+			{Source: "svc-b", Target: "pg:5432", Type: "postgres", State: "ok"},
+		},
+	}
+
+	d := DiffTopologies(before, after, from, to)
+
+	if !d.From.Equal(from) || !d.To.Equal(to) {
+		t.Errorf("From/To = %v/%v, want %v/%v", d.From, d.To, from, to)
+	}
+	if len(d.AddedNodes) != 1 || d.AddedNodes[0].ID != "pg:5432" {
+		t.Errorf("AddedNodes = %+v, want [pg:5432]", d.AddedNodes)
+	}
+	if len(d.RemovedNodes) != 1 || d.RemovedNodes[0].ID != "redis:6379" {
+		t.Errorf("RemovedNodes = %+v, want [redis:6379]", d.RemovedNodes)
+	}
+	if len(d.AddedEdges) != 1 || d.AddedEdges[0].Target != "pg:5432" {
+		t.Errorf("AddedEdges = %+v, want svc-b→pg:5432", d.AddedEdges)
+	}
+	if len(d.RemovedEdges) != 1 || d.RemovedEdges[0].Source != "svc-b" || d.RemovedEdges[0].Target != "redis:6379" {
+		t.Errorf("RemovedEdges = %+v, want svc-b→redis:6379", d.RemovedEdges)
+	}
+	if len(d.NodeStateChanges) != 1 || d.NodeStateChanges[0].FromState != "ok" || d.NodeStateChanges[0].ToState != "degraded" {
+		t.Errorf("NodeStateChanges = %+v, want svc-a ok→degraded", d.NodeStateChanges)
+	}
+	if len(d.EdgeStateChanges) != 1 || d.EdgeStateChanges[0].ToState != "down" {
+		t.Errorf("EdgeStateChanges = %+v, want svc-a→svc-b ok→down", d.EdgeStateChanges)
+	}
+	if len(d.CriticalityChanges) != 1 || d.CriticalityChanges[0].Target != "redis:6379" || !d.CriticalityChanges[0].ToCritical {
+		t.Errorf("CriticalityChanges = %+v, want svc-a→redis:6379 false→true", d.CriticalityChanges)
+	}
+	if len(d.LatencyChanges) != 2 {
+		t.Fatalf("LatencyChanges = %d, want 2", len(d.LatencyChanges))
+	}
+	if d.LatencyChanges[0].Target != "svc-b" {
+		t.Errorf("largest latency change target = %q, want svc-b", d.LatencyChanges[0].Target)
+	}
+	if got := d.LatencyChanges[0].Delta; got < 0.0399 || got > 0.0401 {
+		t.Errorf("latency delta = %v, want 0.04", got)
+	}
+
+	want := DiffSummary{
+		AddedNodes: 1, RemovedNodes: 1, AddedEdges: 1, RemovedEdges: 1,
+		NodeStateChanges: 1, EdgeStateChanges: 1, CriticalityChanges: 1, LatencyChanges: 2,
+	}
+	if d.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", d.Summary, want)
+	}
+}
+
+func TestDiffTopologies_LatencyTieOrder(t *testing.T) {
+	var before, after TopologyResponse
+	for _, target := range []string{"svc-d", "svc-b", "svc-e", "svc-a", "svc-c"} {
+		before.Edges = append(before.Edges, Edge{Source: "gw", Target: target, LatencyRaw: 0.010})
+		after.Edges = append(after.Edges, Edge{Source: "gw", Target: target, LatencyRaw: 0.020})
+	}
+	after.Edges = append(after.Edges, Edge{Source: "gw", Target: "svc-z", LatencyRaw: 0.5})
+	before.Edges = append(before.Edges, Edge{Source: "gw", Target: "svc-z", LatencyRaw: 0.1})
+
+	d := DiffTopologies(&before, &after, time.Time{}, time.Time{})
+	var got []string
+	for _, c := range d.LatencyChanges {
+		got = append(got, c.Target)
+	}
+	want := []string{"svc-z", "svc-a", "svc-b", "svc-c", "svc-d", "svc-e"}
+	if !slices.Equal(got, want) {
+		t.Errorf("latency change order = %v, want %v", got, want)
+	}
+}
+
+func TestDiffTopologies_Identical(t *testing.T) {
+	resp := &TopologyResponse{
+		Nodes: []Node{{ID: "svc-a", State: "ok"}},
+		Edges: []Edge{{Source: "svc-a", Target: "pg:5432", State: "ok", LatencyRaw: 0.005}},
+	}
+	d := DiffTopologies(resp, resp, time.Time{}, time.Time{})
+	if d.Summary != (DiffSummary{}) {
+		t.Errorf("Summary = %+v, want all zero", d.Summary)
+	}
+	if d.AddedNodes == nil || d.LatencyChanges == nil {
+		t.Error("slices should be non-nil for JSON serialization")
+	}
+}
@@ -0,0 +1,138 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// FlapState is the flap detection result for one edge at an evaluation time.
+type FlapState struct {
+	// Transitions is the number of status changes within the window ending
+	// at the evaluation time.
+	Transitions    int
+	Flapping       bool
+	Since          *time.Time // when the edge started flapping; nil when not flapping
+	LastTransition *time.Time // nil when the status never changed in the queried range
+}
+
+// FlapEdge is the flap state of an edge together with its identity labels.
+// Info.Status holds the last sampled status; Info.Values is not set.
+type FlapEdge struct {
+	Info RangeResult
+	FlapState
+}
+
+// flapStep returns the sample step used for flap detection: fine enough to
+// see short status toggles, coarse enough to stay well below the per-query
+// point limit of Prometheus for the longest allowed window.
+func flapStep(window time.Duration) time.Duration {
+	return max(15*time.Second, window/120)
+}
+
+// DetectFlapping queries the dependency status history for the flap window
+// ending at at, preceded by one more window so that the hysteresis state is
+// already established at the start of the reported window, and evaluates
+// every edge.
+func DetectFlapping(ctx context.Context, prom PrometheusClient, cfg config.FlappingConfig, at time.Time, namespace string) (map[EdgeKey]FlapEdge, error) {
+	start := at.Add(-2 * cfg.Window)
+	results, err := prom.QueryStatusRange(ctx, start, at, flapStep(cfg.Window), namespace)
+	if err != nil {
+		return nil, fmt.Errorf("querying status range: %w", err)
+	}
+	return evaluateFlapping(results, start, at, cfg), nil
+}
+
+// evaluateFlapping slides the flap window over the status history of each
+// edge. At every sample after the first full window the transitions within
+// the window are counted: an edge starts flapping when the count reaches
+// cfg.Threshold and stops when it drops to cfg.ClearThreshold or below.
+// The final state is evaluated at end.
+func evaluateFlapping(results []RangeResult, start, end time.Time, cfg config.FlappingConfig) map[EdgeKey]FlapEdge {
+	type series struct {
+		info   RangeResult
+		status map[int64]string
+	}
+	edges := make(map[EdgeKey]*series)
+	for _, r := range results {
+		s, ok := edges[r.Key]
+		if !ok {
+			info := r
+			info.Values = nil
+			s = &series{info: info, status: make(map[int64]string)}
+			edges[r.Key] = s
+		}
+		for _, tv := range r.Values {
+			if tv.Value == 1 {
+				s.status[tv.Timestamp.Unix()] = r.Status
+			}
+		}
+	}
+
+	window := int64(cfg.Window / time.Second)
+	warm := start.Unix() + window
+
+	out := make(map[EdgeKey]FlapEdge, len(edges))
+	for key, s := range edges {
+		if len(s.status) == 0 {
+			continue
+		}
+		samples := make([]int64, 0, len(s.status))
+		for ts := range s.status {
+			samples = append(samples, ts)
+		}
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+		var transitions []int64
+		prev := ""
+		for _, ts := range samples {
+			status := s.status[ts]
+			if prev != "" && status != prev {
+				transitions = append(transitions, ts)
+			}
+			prev = status
+		}
+
+		var state FlapState
+		var since int64
+		lo, hi := 0, 0
+		evaluate := func(t int64) {
+			for hi < len(transitions) && transitions[hi] <= t {
+				hi++
+			}
+			for lo < hi && transitions[lo] <= t-window {
+				lo++
+			}
+			state.Transitions = hi - lo
+			switch {
+			case !state.Flapping && state.Transitions >= cfg.Threshold:
+				state.Flapping = true
+				since = t
+			case state.Flapping && state.Transitions <= cfg.ClearThreshold:
+				state.Flapping = false
+			}
+		}
+		for _, ts := range samples {
+			if ts >= warm {
+				evaluate(ts)
+			}
+		}
+		evaluate(end.Unix())
+
+		if state.Flapping {
+			t := time.Unix(since, 0).UTC()
+			state.Since = &t
+		}
+		if n := len(transitions); n > 0 {
+			t := time.Unix(transitions[n-1], 0).UTC()
+			state.LastTransition = &t
+		}
+		info := s.info
+		info.Status = prev
+		out[key] = FlapEdge{Info: info, FlapState: state}
+	}
+	return out
+}
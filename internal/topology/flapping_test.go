@@ -0,0 +1,141 @@
+package topology
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+var testFlapConfig = config.FlappingConfig{Window: 10 * time.Minute, Threshold: 4, ClearThreshold: 1}
+
+// statusSeries builds the per-status range series of one edge sampled every
+// minute from start, with statuses[i] active at sample i.
+func statusSeries(key EdgeKey, start time.Time, statuses []string) []RangeResult {
+	byStatus := make(map[string]*RangeResult)
+	var order []string
+	for _, s := range statuses {
+		if _, ok := byStatus[s]; !ok {
+			byStatus[s] = &RangeResult{Key: key, Namespace: "default", Dependency: "postgres", Type: "postgres", Critical: true, Status: s}
+			order = append(order, s)
+		}
+	}
+	for i, active := range statuses {
+		ts := start.Add(time.Duration(i) * time.Minute)
+		for _, s := range order {
+			v := 0.0
+			if s == active {
+				v = 1
+			}
+			byStatus[s].Values = append(byStatus[s].Values, TimeValue{Timestamp: ts, Value: v})
+		}
+	}
+	results := make([]RangeResult, 0, len(order))
+	for _, s := range order {
+		results = append(results, *byStatus[s])
+	}
+	return results
+}
+
+// repeat returns n copies of s.
+func repeat(s string, n int) []string {
+	out := make([]string, n)
+	for i := range out {
+		out[i] = s
+	}
+	return out
+}
+
+func TestEvaluateFlapping(t *testing.T) {
+	key := EdgeKey{Name: "svc", Host: "pg", Port: "5432"}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		statuses     []string // one per minute, covering about two windows
+		wantFlapping bool
+		wantCount    int
+	}{
+		{
+			name:     "stable",
+			statuses: repeat("ok", 21),
+		},
+		{
+			name:      "single outage is not flapping",
+			statuses:  append(append(repeat("ok", 14), repeat("timeout", 3)...), repeat("ok", 4)...),
+			wantCount: 2,
+		},
+		{
+			name: "toggling in the last window",
+			statuses: append(repeat("ok", 13),
+				"timeout", "ok", "timeout", "ok", "timeout", "ok", "ok", "ok"),
+			wantFlapping: true,
+			wantCount:    6,
+		},
+		{
+			// Toggling stopped 8 minutes ago; 2 transitions remain in the window,
+			// above the clear threshold, so the edge keeps flapping (hysteresis).
+			name: "hysteresis keeps flapping above clear threshold",
+			statuses: append(append(repeat("ok", 8),
+				"timeout", "ok", "timeout", "ok", "timeout", "ok"), repeat("ok", 8)...),
+			wantFlapping: true,
+			wantCount:    2,
+		},
+		{
+			name: "clears at the clear threshold",
+			statuses: append(append(repeat("ok", 5),
+				"timeout", "ok", "timeout", "ok", "timeout", "ok"), repeat("ok", 10)...),
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := start.Add(time.Duration(len(tt.statuses)-1) * time.Minute)
+			got := evaluateFlapping(statusSeries(key, start, tt.statuses), start, end, testFlapConfig)
+			f, ok := got[key]
+			if !ok {
+				t.Fatal("edge missing from result")
+			}
+			if f.Flapping != tt.wantFlapping {
+				t.Errorf("Flapping = %v, want %v", f.Flapping, tt.wantFlapping)
+			}
+			if f.Transitions != tt.wantCount {
+				t.Errorf("Transitions = %d, want %d", f.Transitions, tt.wantCount)
+			}
+			if f.Flapping != (f.Since != nil) {
+				t.Errorf("Since = %v, want set only while flapping", f.Since)
+			}
+			if f.Info.Status != tt.statuses[len(tt.statuses)-1] || f.Info.Dependency != "postgres" {
+				t.Errorf("Info = %+v, want last status and edge labels", f.Info)
+			}
+		})
+	}
+}
+
+func TestGraphBuilder_Flapping(t *testing.T) {
+	key := EdgeKey{Name: "svc-go", Host: "pg-primary", Port: "5432"}
+	now := time.Now().Truncate(time.Minute)
+	statuses := append(repeat("ok", 13), "timeout", "ok", "timeout", "ok", "timeout", "ok", "ok", "ok")
+	mock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Namespace: "default", Dependency: "postgres", Type: "postgres", Host: "pg-primary", Port: "5432", Critical: true},
+		},
+		health:      map[EdgeKey]float64{key: 1},
+		statusRange: statusSeries(key, now.Add(-20*time.Minute), statuses),
+	}
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetFlapping(testFlapConfig)
+
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if len(resp.Edges) != 1 {
+		t.Fatalf("edges = %d, want 1", len(resp.Edges))
+	}
+	if e := resp.Edges[0]; !e.Flapping || e.Transitions != 6 {
+		t.Errorf("edge flapping = %v, transitions = %d; want true, 6", e.Flapping, e.Transitions)
+	}
+}
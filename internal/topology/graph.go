@@ -2,11 +2,13 @@ package topology
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/BigKAA/dephealth-ui/internal/alerts"
@@ -23,21 +25,72 @@ type GrafanaConfig struct {
 	LinksStatusDashUID    string
 }
 
-// depAlertKey maps alert labels (name + dependency name) to find the corresponding edge.
+// depAlertKey maps alert labels (name + dependency name, or name + host:port)
+// to find the corresponding edge.
 type depAlertKey struct {
 	Name       string
 	Dependency string
+	Host       string
+	Port       string
+}
+
+// latencyQuantiles lists the histogram quantiles queried for every edge.
+var latencyQuantiles = []struct {
+	name     string
+	quantile float64
+}{
+	{"p50", 0.50},
+	{"p95", 0.95},
+	{"p99", 0.99},
+}
+
+// edgePercentiles holds latency quantiles (seconds) for a single edge.
+type edgePercentiles struct {
+	p50, p95, p99 float64
+}
+
+func (p *edgePercentiles) set(name string, v float64) {
+	switch name {
+	case "p50":
+		p.p50 = v
+	case "p95":
+		p.p95 = v
+	case "p99":
+		p.p99 = v
+	}
+}
+
+func (p edgePercentiles) get(name string) float64 {
+	switch name {
+	case "p50":
+		return p.p50
+	case "p95":
+		return p.p95
+	default:
+		return p.p99
+	}
+}
+
+// Source is a named Prometheus/VictoriaMetrics datasource, e.g. one per cluster.
+type Source struct {
+	Name string
+	Prom PrometheusClient
 }
 
 // GraphBuilder constructs a TopologyResponse from Prometheus and AlertManager data.
 type GraphBuilder struct {
-	prom           PrometheusClient
-	am             alerts.AlertManagerClient
-	grafana        GrafanaConfig
-	ttl            time.Duration
-	lookback       time.Duration
-	logger         *slog.Logger
-	severityLevels []config.SeverityLevel
+	sources           []Source
+	am                alerts.AlertManagerClient
+	grafana           GrafanaConfig
+	ttl               time.Duration
+	lookback          time.Duration
+	logger            *slog.Logger
+	severityLevels    []config.SeverityLevel
+	latencyThresholds LatencyThresholds
+	thresholdRules    []config.ThresholdRule
+	alertMapping      alerts.Mapping
+	flapping          config.FlappingConfig
+	queries           config.QueriesConfig
 }
 
 // NewGraphBuilder creates a new GraphBuilder.
@@ -46,77 +99,405 @@ func NewGraphBuilder(prom PrometheusClient, am alerts.AlertManagerClient, grafan
 		logger = slog.Default()
 	}
 	return &GraphBuilder{
-		prom:           prom,
+		sources:        []Source{{Prom: prom}},
 		am:             am,
 		grafana:        grafana,
 		ttl:            ttl,
 		lookback:       lookback,
 		logger:         logger,
 		severityLevels: severityLevels,
+		alertMapping:   alerts.DefaultMapping(),
+	}
+}
+
+// SetLatencyThresholds enables latency-based degradation: an otherwise healthy
+// edge whose configured percentile exceeds the threshold for its type is "degraded".
+func (b *GraphBuilder) SetLatencyThresholds(t LatencyThresholds) {
+	b.latencyThresholds = t
+}
+
+// SetSources replaces the Prometheus datasources the topology is built from.
+// With more than one source, node IDs are prefixed with the source name and
+// every node and edge carries it in Cluster.
+func (b *GraphBuilder) SetSources(sources []Source) {
+	if len(sources) > 0 {
+		b.sources = sources
+	}
+}
+
+// Sources returns the Prometheus datasources the topology is built from,
+// skipping those that do not serve the tenant selected in ctx.
+func (b *GraphBuilder) Sources(ctx context.Context) []Source {
+	tenant := TenantFromContext(ctx)
+	if tenant == "" {
+		return b.sources
+	}
+	var out []Source
+	for _, src := range b.sources {
+		if servesTenant(src.Prom, tenant) {
+			out = append(out, src)
+		}
+	}
+	return out
+}
+
+// MultiSource reports whether several sources are configured. Their node IDs
+// are then prefixed with, and results tagged with, the source name.
+func (b *GraphBuilder) MultiSource() bool {
+	return len(b.sources) > 1
+}
+
+// SetAlertMapping sets the state rules that decide which alerts override
+// edge state, for both AlertManager alerts and alerts reconstructed from
+// the ALERTS metric in history mode.
+func (b *GraphBuilder) SetAlertMapping(m alerts.Mapping) {
+	b.alertMapping = m
+}
+
+// SetThresholdRules sets per-type, per-namespace and per-edge degradation rules.
+// A matching rule takes precedence over the per-type latency thresholds.
+func (b *GraphBuilder) SetThresholdRules(rules []config.ThresholdRule) {
+	b.thresholdRules = rules
+}
+
+// SetFlapping enables flap detection: every edge carries the number of status
+// transitions within the flap window and whether it is currently flapping.
+// A zero Window disables it.
+func (b *GraphBuilder) SetFlapping(cfg config.FlappingConfig) {
+	b.flapping = cfg
+}
+
+// SetQueryBudgets sets the per-query timeouts and the build deadline.
+// Without it, queries are bounded only by the datasource client timeouts.
+func (b *GraphBuilder) SetQueryBudgets(cfg config.QueriesConfig) {
+	b.queries = cfg
+}
+
+// Datasources returns the circuit breaker state, for the tenant selected in
+// ctx, of every source serving it whose client has one (see CircuitReporter).
+func (b *GraphBuilder) Datasources(ctx context.Context) []DatasourceStatus {
+	var out []DatasourceStatus
+	for _, src := range b.Sources(ctx) {
+		if r, ok := src.Prom.(CircuitReporter); ok {
+			out = append(out, DatasourceStatus{Name: src.Name, Circuit: r.CircuitState(ctx)})
+		}
 	}
+	return out
 }
 
 // Build queries Prometheus and AlertManager, then constructs the full topology response.
 // Only QueryTopologyEdges is fatal. Health, latency, and alert failures result in partial data.
+// With several sources, each is built in parallel and the results are merged;
+// a failing source is reported in Meta.Errors and only fails the build when
+// every source fails. Sources that do not serve the tenant selected in ctx
+// are skipped. All datasource queries run concurrently within the
+// budgets set by SetQueryBudgets; their timings are reported in Meta.Queries.
 func (b *GraphBuilder) Build(ctx context.Context, opts QueryOptions) (*TopologyResponse, error) {
-	var rawEdges []TopologyEdge
-	var err error
+	var cancel context.CancelFunc
+	if b.queries.BuildTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.queries.BuildTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	// Live alerts come from AlertManager and are shared by all sources;
+	// in history mode each source reconstructs its own from the ALERTS metric.
+	// The fetch runs alongside the source queries; sources wait for it only
+	// when they apply the alerts.
+	var liveAlerts []alerts.Alert
+	var alertErr error
+	alertQuery := newQueryRunner(ctx, b.queries, "")
+	if opts.Time == nil && b.am != nil {
+		alertQuery.run("alerts", func(ctx context.Context) error {
+			liveAlerts, alertErr = b.am.FetchAlerts(ctx)
+			return alertErr
+		})
+	}
+	waitAlerts := sync.OnceValue(func() []alerts.Alert {
+		alertQuery.wait()
+		if alertErr != nil {
+			b.logger.Warn("failed to fetch alerts from AlertManager", "error", alertErr)
+		}
+		return liveAlerts
+	})
 
-	if b.lookback > 0 {
-		rawEdges, err = b.prom.QueryTopologyEdgesLookback(ctx, opts, b.lookback)
+	var nodes []Node
+	var edges []Edge
+	var alertInfos []AlertInfo
+	var queryErrors []string
+	var timings []QueryTiming
+
+	sources := b.Sources(ctx)
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrTenantNotAllowed, TenantFromContext(ctx))
+	}
+	if !b.MultiSource() {
+		g, err := b.buildSource(ctx, sources[0], opts, waitAlerts)
+		if err != nil {
+			return nil, err
+		}
+		nodes, edges, alertInfos, queryErrors = g.nodes, g.edges, g.alerts, g.errors
+		timings = g.queries
 	} else {
-		rawEdges, err = b.prom.QueryTopologyEdges(ctx, opts)
+		results := make([]*sourceGraph, len(sources))
+		errs := make([]error, len(sources))
+		var wg sync.WaitGroup
+		for i, src := range sources {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				results[i], errs[i] = b.buildSource(ctx, src, opts, waitAlerts)
+			}()
+		}
+		wg.Wait()
+
+		failed := 0
+		haveAlerts := false
+		alertInfos = []AlertInfo{}
+		for i, src := range sources {
+			if errs[i] != nil {
+				failed++
+				b.logger.Warn("failed to build topology for source", "source", src.Name, "error", errs[i])
+				queryErrors = append(queryErrors, fmt.Sprintf("%s: %v", src.Name, errs[i]))
+				continue
+			}
+			g := results[i]
+			prefixSourceIDs(src.Name, g.nodes, g.edges)
+			nodes = append(nodes, g.nodes...)
+			edges = append(edges, g.edges...)
+			timings = append(timings, g.queries...)
+			for _, e := range g.errors {
+				queryErrors = append(queryErrors, fmt.Sprintf("%s: %s", src.Name, e))
+			}
+			// Live alert infos are identical for every source; historical ones are per source.
+			if opts.Time != nil || !haveAlerts {
+				alertInfos = append(alertInfos, g.alerts...)
+				haveAlerts = true
+			}
+		}
+		if failed == len(sources) {
+			return nil, fmt.Errorf("all sources failed: %s", strings.Join(queryErrors, "; "))
+		}
+	}
+
+	// Sources that failed or returned early never waited for the alerts;
+	// wait before reading alertErr.
+	waitAlerts()
+	var partialAlerts *alerts.PartialError
+	switch {
+	case errors.As(alertErr, &partialAlerts):
+		// Some AlertManager endpoints responded; name each one that did not.
+		for _, ep := range partialAlerts.Endpoints {
+			queryErrors = append(queryErrors, fmt.Sprintf("alerts: %v", ep))
+		}
+	case alertErr != nil:
+		queryErrors = append(queryErrors, fmt.Sprintf("alerts: %v", alertErr))
+	}
+
+	markCycles(nodes, edges)
+
+	timings = append(alertQuery.timings, timings...)
+
+	meta := TopologyMeta{
+		CachedAt:  time.Now().UTC(),
+		TTL:       int(b.ttl.Seconds()),
+		NodeCount: len(nodes),
+		EdgeCount: len(edges),
+		Partial:   len(queryErrors) > 0,
+		Errors:    queryErrors,
+		Queries:   timings,
+		// Read after the queries so the states reflect this build's outcome.
+		Datasources: b.Datasources(ctx),
+		Tenant:      TenantFromContext(ctx),
+	}
+	if opts.Time != nil {
+		meta.Time = opts.Time
+		meta.IsHistory = true
+	}
+
+	return &TopologyResponse{
+		Nodes:  nodes,
+		Edges:  edges,
+		Alerts: alertInfos,
+		Meta:   meta,
+	}, nil
+}
+
+// sourceGraph is the topology built from a single source.
+type sourceGraph struct {
+	nodes   []Node
+	edges   []Edge
+	alerts  []AlertInfo
+	errors  []string
+	queries []QueryTiming
+}
+
+// buildSource queries a single Prometheus source and builds its graph.
+// The alerts returned by liveAlerts are applied in live mode; in history
+// mode alerts are read from the source's ALERTS metric.
+func (b *GraphBuilder) buildSource(ctx context.Context, src Source, opts QueryOptions, liveAlerts func() []alerts.Alert) (*sourceGraph, error) {
+	// The queries are independent: run them concurrently, each under its own
+	// budget. A failing edge query fails the build, so it cancels the rest.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	q := newQueryRunner(ctx, b.queries, src.Name)
+	prom := src.Prom
+
+	var rawEdges []TopologyEdge
+	var edgesErr error
+	q.run("edges", func(ctx context.Context) error {
+		if b.lookback > 0 {
+			rawEdges, edgesErr = prom.QueryTopologyEdgesLookback(ctx, opts, b.lookback)
+		} else {
+			rawEdges, edgesErr = prom.QueryTopologyEdges(ctx, opts)
+		}
+		if edgesErr != nil {
+			cancel()
+		}
+		return edgesErr
+	})
+
+	var health map[EdgeKey]float64
+	var healthErr error
+	q.run("health", func(ctx context.Context) error {
+		health, healthErr = prom.QueryHealthState(ctx, opts)
+		return healthErr
+	})
+
+	var avgLatency map[EdgeKey]float64
+	var avgLatencyErr error
+	q.run("avgLatency", func(ctx context.Context) error {
+		avgLatency, avgLatencyErr = prom.QueryAvgLatency(ctx, opts)
+		return avgLatencyErr
+	})
+
+	quantileValues := make([]map[EdgeKey]float64, len(latencyQuantiles))
+	quantileErrs := make([]error, len(latencyQuantiles))
+	for i, lq := range latencyQuantiles {
+		q.run("latency"+strings.ToUpper(lq.name[:1])+lq.name[1:], func(ctx context.Context) error {
+			quantileValues[i], quantileErrs[i] = prom.QueryLatencyQuantile(ctx, opts, lq.quantile)
+			return quantileErrs[i]
+		})
+	}
+
+	var errorRatio map[EdgeKey]float64
+	var errorRatioErr error
+	if b.needsErrorRatio() {
+		q.run("errorRatio", func(ctx context.Context) error {
+			errorRatio, errorRatioErr = prom.QueryErrorRatio(ctx, opts)
+			return errorRatioErr
+		})
 	}
-	if err != nil {
-		return nil, fmt.Errorf("querying topology edges: %w", err)
+
+	var depStatus map[EdgeKey]string
+	var depStatusErr error
+	q.run("status", func(ctx context.Context) error {
+		depStatus, depStatusErr = prom.QueryDependencyStatus(ctx, opts)
+		return depStatusErr
+	})
+
+	var depStatusDetail map[EdgeKey]string
+	var depStatusDetailErr error
+	q.run("statusDetail", func(ctx context.Context) error {
+		depStatusDetail, depStatusDetailErr = prom.QueryDependencyStatusDetail(ctx, opts)
+		return depStatusDetailErr
+	})
+
+	var flaps map[EdgeKey]FlapEdge
+	var flapsErr error
+	if b.flapping.Window > 0 {
+		at := time.Now()
+		if opts.Time != nil {
+			at = *opts.Time
+		}
+		q.run("flapping", func(ctx context.Context) error {
+			flaps, flapsErr = DetectFlapping(ctx, prom, b.flapping, at, opts.Namespace)
+			return flapsErr
+		})
+	}
+
+	// In history mode alerts come from the source's ALERTS metric.
+	var histAlerts []HistoricalAlert
+	var histAlertsErr error
+	if opts.Time != nil {
+		q.run("historicalAlerts", func(ctx context.Context) error {
+			histAlerts, histAlertsErr = prom.QueryHistoricalAlerts(ctx, *opts.Time)
+			return histAlertsErr
+		})
+	}
+
+	timings := q.wait()
+	if edgesErr != nil {
+		return nil, fmt.Errorf("querying topology edges: %w", edgesErr)
 	}
 
 	var queryErrors []string
 
-	health, err := b.prom.QueryHealthState(ctx, opts)
-	if err != nil {
-		b.logger.Warn("failed to query health state, using defaults", "error", err)
+	if healthErr != nil {
+		b.logger.Warn("failed to query health state, using defaults", "error", healthErr)
 		health = make(map[EdgeKey]float64)
-		queryErrors = append(queryErrors, fmt.Sprintf("health state: %v", err))
+		queryErrors = append(queryErrors, fmt.Sprintf("health state: %v", healthErr))
 	}
 
-	avgLatency, err := b.prom.QueryAvgLatency(ctx, opts)
-	if err != nil {
-		b.logger.Warn("failed to query avg latency, using defaults", "error", err)
+	if avgLatencyErr != nil {
+		b.logger.Warn("failed to query avg latency, using defaults", "error", avgLatencyErr)
 		avgLatency = make(map[EdgeKey]float64)
-		queryErrors = append(queryErrors, fmt.Sprintf("avg latency: %v", err))
+		queryErrors = append(queryErrors, fmt.Sprintf("avg latency: %v", avgLatencyErr))
+	}
+
+	percentiles := make(map[EdgeKey]edgePercentiles)
+	for i, lq := range latencyQuantiles {
+		if qErr := quantileErrs[i]; qErr != nil {
+			b.logger.Warn("failed to query latency quantile, using defaults", "quantile", lq.name, "error", qErr)
+			queryErrors = append(queryErrors, fmt.Sprintf("latency %s: %v", lq.name, qErr))
+			continue
+		}
+		for k, v := range quantileValues[i] {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			p := percentiles[k]
+			p.set(lq.name, v)
+			percentiles[k] = p
+		}
 	}
 
-	depStatus, err := b.prom.QueryDependencyStatus(ctx, opts)
-	if err != nil {
-		b.logger.Warn("failed to query dependency status, using defaults", "error", err)
+	if errorRatioErr != nil {
+		b.logger.Warn("failed to query error ratio, using defaults", "error", errorRatioErr)
+		errorRatio = nil
+		queryErrors = append(queryErrors, fmt.Sprintf("error ratio: %v", errorRatioErr))
+	}
+
+	if depStatusErr != nil {
+		b.logger.Warn("failed to query dependency status, using defaults", "error", depStatusErr)
 		depStatus = make(map[EdgeKey]string)
-		queryErrors = append(queryErrors, fmt.Sprintf("dependency status: %v", err))
+		queryErrors = append(queryErrors, fmt.Sprintf("dependency status: %v", depStatusErr))
 	}
 
-	depStatusDetail, err := b.prom.QueryDependencyStatusDetail(ctx, opts)
-	if err != nil {
-		b.logger.Warn("failed to query dependency status detail, using defaults", "error", err)
+	if depStatusDetailErr != nil {
+		b.logger.Warn("failed to query dependency status detail, using defaults", "error", depStatusDetailErr)
 		depStatusDetail = make(map[EdgeKey]string)
-		queryErrors = append(queryErrors, fmt.Sprintf("dependency status detail: %v", err))
+		queryErrors = append(queryErrors, fmt.Sprintf("dependency status detail: %v", depStatusDetailErr))
+	}
+
+	if flapsErr != nil {
+		b.logger.Warn("failed to detect flapping edges", "error", flapsErr)
+		flaps = nil
+		queryErrors = append(queryErrors, fmt.Sprintf("flapping: %v", flapsErr))
 	}
 
-	// Fetch alerts: use historical ALERTS metric when in history mode,
-	// otherwise fetch from AlertManager (live mode).
+	// Alerts: the historical ALERTS metric in history mode, otherwise the
+	// alerts fetched from AlertManager (live mode).
 	var fetchedAlerts []alerts.Alert
-	if opts.Time != nil {
-		histAlerts, hErr := b.prom.QueryHistoricalAlerts(ctx, *opts.Time)
-		if hErr != nil {
-			b.logger.Warn("failed to query historical alerts", "error", hErr)
-			queryErrors = append(queryErrors, fmt.Sprintf("historical alerts: %v", hErr))
+	if opts.Time == nil {
+		fetchedAlerts = liveAlerts()
+	} else {
+		if histAlertsErr != nil {
+			b.logger.Warn("failed to query historical alerts", "error", histAlertsErr)
+			queryErrors = append(queryErrors, fmt.Sprintf("historical alerts: %v", histAlertsErr))
 		} else {
-			fetchedAlerts = historicalToAlerts(histAlerts)
-		}
-	} else if b.am != nil {
-		fetchedAlerts, err = b.am.FetchAlerts(ctx)
-		if err != nil {
-			b.logger.Warn("failed to fetch alerts from AlertManager", "error", err)
-			queryErrors = append(queryErrors, fmt.Sprintf("alerts: %v", err))
+			fetchedAlerts = b.historicalToAlerts(histAlerts)
 		}
 	}
 
@@ -131,29 +512,32 @@ func (b *GraphBuilder) Build(ctx context.Context, opts QueryOptions) (*TopologyR
 		}
 	}
 
-	nodes, edges, depLookup := b.buildGraph(rawEdges, health, avgLatency, currentEdgeKeys, depStatus, depStatusDetail)
+	nodes, edges, depLookup := b.buildGraph(rawEdges, health, avgLatency, percentiles, errorRatio, currentEdgeKeys, depStatus, depStatusDetail, flaps)
 
 	alertInfos := b.enrichWithAlerts(nodes, edges, fetchedAlerts, depLookup)
 
-	meta := TopologyMeta{
-		CachedAt:  time.Now().UTC(),
-		TTL:       int(b.ttl.Seconds()),
-		NodeCount: len(nodes),
-		EdgeCount: len(edges),
-		Partial:   len(queryErrors) > 0,
-		Errors:    queryErrors,
-	}
-	if opts.Time != nil {
-		meta.Time = opts.Time
-		meta.IsHistory = true
+	if src.Name != "" {
+		for i := range nodes {
+			nodes[i].Cluster = src.Name
+		}
+		for i := range edges {
+			edges[i].Cluster = src.Name
+		}
 	}
 
-	return &TopologyResponse{
-		Nodes:  nodes,
-		Edges:  edges,
-		Alerts: alertInfos,
-		Meta:   meta,
-	}, nil
+	return &sourceGraph{nodes: nodes, edges: edges, alerts: alertInfos, errors: queryErrors, queries: timings}, nil
+}
+
+// prefixSourceIDs makes node IDs unique across sources by prefixing them
+// with the source name ("<source>/<id>"). Labels are left unchanged.
+func prefixSourceIDs(source string, nodes []Node, edges []Edge) {
+	for i := range nodes {
+		nodes[i].ID = source + "/" + nodes[i].ID
+	}
+	for i := range edges {
+		edges[i].Source = source + "/" + edges[i].Source
+		edges[i].Target = source + "/" + edges[i].Target
+	}
 }
 
 // buildGraph constructs nodes and edges from raw topology data.
@@ -163,9 +547,12 @@ func (b *GraphBuilder) buildGraph(
 	rawEdges []TopologyEdge,
 	health map[EdgeKey]float64,
 	avgLatency map[EdgeKey]float64,
+	percentiles map[EdgeKey]edgePercentiles,
+	errorRatio map[EdgeKey]float64,
 	currentEdgeKeys map[EdgeKey]bool,
 	depStatus map[EdgeKey]string,
 	depStatusDetail map[EdgeKey]string,
+	flaps map[EdgeKey]FlapEdge,
 ) ([]Node, []Edge, map[depAlertKey]string) {
 	// First pass: collect all known service names (sources that report metrics).
 	serviceNames := make(map[string]bool)
@@ -189,6 +576,7 @@ func (b *GraphBuilder) buildGraph(
 	// Track edge health per source node (outgoing, with critical flag) and per target node (incoming).
 	nodeOutgoingHealth := make(map[string][]edgeHealthInfo)
 	nodeIncomingHealth := make(map[string][]float64)
+	nodeIncomingDegraded := make(map[string]bool)
 
 	// Build unique edges keyed by {Name, Host, Port}.
 	edgeMap := make(map[EdgeKey]TopologyEdge)
@@ -213,8 +601,12 @@ func (b *GraphBuilder) buildGraph(
 
 		depNodeID := resolveTarget(e)
 
-		// Build reverse lookup for alerts: maps (name, dependency) → target node ID.
+		// Build reverse lookup for alerts: maps (name, dependency) and
+		// (name, host, port) → target node ID.
 		depLookup[depAlertKey{Name: e.Name, Dependency: e.Dependency}] = depNodeID
+		if e.Host != "" {
+			depLookup[depAlertKey{Name: e.Name, Host: e.Host, Port: e.Port}] = depNodeID
+		}
 
 		// Register source node (service).
 		if _, ok := nodeMap[e.Name]; !ok {
@@ -273,6 +665,7 @@ func (b *GraphBuilder) buildGraph(
 				Source:     raw.Name,
 				Target:     depNodeID,
 				Type:       raw.Type,
+				Dependency: raw.Dependency,
 				Latency:    "",
 				LatencyRaw: 0,
 				Health:     -1,
@@ -292,17 +685,30 @@ func (b *GraphBuilder) buildGraph(
 				lat = v
 			}
 
+			pct := percentiles[key]
+			errRatio, hasErrRatio := errorRatio[key]
+			if math.IsNaN(errRatio) || math.IsInf(errRatio, 0) {
+				errRatio, hasErrRatio = 0, false
+			}
+
 			state := "ok"
+			var reason string
 			if h == 0 {
 				state = "down"
+			} else if reason = b.degradedReason(raw, pct, errRatio); reason != "" {
+				state = "degraded"
 			}
 
 			edge = Edge{
 				Source:     raw.Name,
 				Target:     depNodeID,
 				Type:       raw.Type,
+				Dependency: raw.Dependency,
 				Latency:    formatLatency(lat),
 				LatencyRaw: lat,
+				LatencyP50: pct.p50,
+				LatencyP95: pct.p95,
+				LatencyP99: pct.p99,
 				Health:     h,
 				State:      state,
 				Critical:   raw.Critical,
@@ -314,6 +720,16 @@ func (b *GraphBuilder) buildGraph(
 			if d, ok := depStatusDetail[key]; ok {
 				edge.Detail = d
 			}
+			if f, ok := flaps[key]; ok {
+				edge.Flapping = f.Flapping
+				edge.Transitions = f.Transitions
+			}
+			if reason != "" {
+				edge.DegradedReason = reason
+			}
+			if hasErrRatio {
+				edge.ErrorRatio = errRatio
+			}
 		}
 
 		// Deduplicate by (Source, Target): when multiple EdgeKeys resolve to the
@@ -338,8 +754,11 @@ func (b *GraphBuilder) buildGraph(
 			nodeStaleIncoming[edge.Target]++
 			nodeTotalIncoming[edge.Target]++
 		} else {
-			nodeOutgoingHealth[edge.Source] = append(nodeOutgoingHealth[edge.Source], edgeHealthInfo{Health: edge.Health, Critical: edge.Critical})
+			nodeOutgoingHealth[edge.Source] = append(nodeOutgoingHealth[edge.Source], edgeHealthInfo{Health: edge.Health, Critical: edge.Critical, Degraded: edge.State == "degraded"})
 			nodeIncomingHealth[edge.Target] = append(nodeIncomingHealth[edge.Target], edge.Health)
+			if edge.State == "degraded" {
+				nodeIncomingDegraded[edge.Target] = true
+			}
 			nodeTotalOutgoing[edge.Source]++
 			nodeTotalIncoming[edge.Target]++
 		}
@@ -394,6 +813,9 @@ func (b *GraphBuilder) buildGraph(
 				stale = true
 			} else {
 				state = calcNodeState(nodeIncomingHealth[id])
+				if state == "ok" && nodeIncomingDegraded[id] {
+					state = "degraded"
+				}
 			}
 		}
 
@@ -436,6 +858,7 @@ func (b *GraphBuilder) buildGraph(
 type edgeHealthInfo struct {
 	Health   float64
 	Critical bool
+	Degraded bool // edge is up but degraded (e.g. latency threshold exceeded)
 }
 
 // edgeBetter returns true if candidate should replace existing during deduplication.
@@ -480,6 +903,7 @@ func calcNodeState(healthValues []float64) string {
 //   - No critical edges at all → always "ok" (non-critical failures don't affect state)
 //   - Any critical edge down (health=0) → "down"
 //   - All critical edges healthy, some non-critical down → "degraded"
+//   - Any edge degraded (e.g. slow) → "degraded"
 //   - All edges healthy → "ok"
 func calcServiceNodeState(edges []edgeHealthInfo) string {
 	if len(edges) == 0 {
@@ -487,7 +911,7 @@ func calcServiceNodeState(edges []edgeHealthInfo) string {
 	}
 
 	for _, e := range edges {
-		if e.Health == 0 {
+		if e.Health == 0 || e.Degraded {
 			return "degraded"
 		}
 	}
@@ -565,12 +989,40 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 		edgeIdx[edgeRef{e.Source, e.Target}] = i
 	}
 
+	// Namespace per node, to reject alerts whose namespace label disagrees.
+	nodeNamespace := make(map[string]string, len(nodes))
+	for _, n := range nodes {
+		nodeNamespace[n.ID] = n.Namespace
+	}
+
 	// Track alert-based health overrides per source node.
 	nodeAlertHealth := make(map[string][]float64)
 	// Track alert counts and worst severity per node and edge.
 	nodeAlertCounts := make(map[string]int)
-	nodeWorstSeverity := make(map[string]int)  // node ID → best (lowest) severity priority
-	edgeWorstSeverity := make(map[int]int)      // edge index → best (lowest) severity priority
+	nodeWorstSeverity := make(map[string]int) // node ID → best (lowest) severity priority
+	edgeWorstSeverity := make(map[int]int)    // edge index → best (lowest) severity priority
+
+	nodeAckCounts := make(map[string]int)
+
+	// alertEdgeIdx translates alert labels (name, dependency_name) to an edge
+	// via reverse lookup. depLookup maps (service, dependency) → target node ID
+	// (works for both dependency nodes and service-to-service edges). Alerts
+	// without a dependency label fall back to the host:port of the endpoint.
+	alertEdgeIdx := func(a alerts.Alert) (int, bool) {
+		alertKey := depAlertKey{Name: a.Service, Dependency: a.Dependency}
+		if a.Dependency == "" {
+			alertKey = depAlertKey{Name: a.Service, Host: a.Host, Port: a.Port}
+		}
+		targetNodeID, ok := depLookup[alertKey]
+		if !ok {
+			return 0, false
+		}
+		if a.Namespace != "" && nodeNamespace[a.Service] != "" && nodeNamespace[a.Service] != a.Namespace {
+			return 0, false
+		}
+		idx, ok := edgeIdx[edgeRef{source: a.Service, target: targetNodeID}]
+		return idx, ok
+	}
 
 	var alertInfos []AlertInfo
 	for _, a := range fetched {
@@ -582,8 +1034,22 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 			State:      a.State,
 			Since:      a.Since,
 			Summary:    a.Summary,
+			// Silence details, set only for silenced alerts.
+			SilenceID:        a.SilenceID,
+			SilenceCreatedBy: a.SilenceCreatedBy,
+			SilenceEndsAt:    a.SilenceEndsAt,
 		})
 
+		// Silenced and inhibited alerts are shown as acknowledged and do not
+		// affect counts, severity or state.
+		if a.Acknowledged() {
+			nodeAckCounts[a.Service]++
+			if idx, ok := alertEdgeIdx(a); ok {
+				edges[idx].AckCount++
+			}
+			continue
+		}
+
 		// Count alert per service node.
 		nodeAlertCounts[a.Service]++
 
@@ -594,17 +1060,7 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 			}
 		}
 
-		// Translate alert labels (name, dependency_name) to edge via reverse lookup.
-		// depLookup maps (service, dependency) → target node ID (works for both
-		// dependency nodes and service-to-service edges).
-		alertKey := depAlertKey{Name: a.Service, Dependency: a.Dependency}
-		targetNodeID, ok := depLookup[alertKey]
-		if !ok {
-			continue
-		}
-
-		ref := edgeRef{source: a.Service, target: targetNodeID}
-		idx, ok := edgeIdx[ref]
+		idx, ok := alertEdgeIdx(a)
 		if !ok {
 			continue
 		}
@@ -618,12 +1074,12 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 		}
 
 		// Alert-based state override (alerts are more authoritative).
-		switch a.AlertName {
-		case "DependencyDown":
+		switch b.alertMapping.EdgeState(alertLabels(a)) {
+		case alerts.EdgeStateDown:
 			edges[idx].State = "down"
 			edges[idx].Health = 0
 			nodeAlertHealth[a.Service] = append(nodeAlertHealth[a.Service], 0)
-		case "DependencyDegraded":
+		case alerts.EdgeStateDegraded:
 			if edges[idx].State != "down" {
 				edges[idx].State = "degraded"
 			}
@@ -650,6 +1106,11 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 			nodes[idx].AlertCount = count
 		}
 	}
+	for nodeID, count := range nodeAckCounts {
+		if idx, ok := nodeIdx[nodeID]; ok {
+			nodes[idx].AckCount = count
+		}
+	}
 	for nodeID, pri := range nodeWorstSeverity {
 		if idx, ok := nodeIdx[nodeID]; ok {
 			if pri < len(b.severityLevels) {
@@ -665,7 +1126,7 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 		// Collect edge health+critical per source node (with alert overrides applied).
 		nodeEdgeHealth := make(map[string][]edgeHealthInfo)
 		for _, e := range edges {
-			nodeEdgeHealth[e.Source] = append(nodeEdgeHealth[e.Source], edgeHealthInfo{Health: e.Health, Critical: e.Critical})
+			nodeEdgeHealth[e.Source] = append(nodeEdgeHealth[e.Source], edgeHealthInfo{Health: e.Health, Critical: e.Critical, Degraded: e.State == "degraded"})
 		}
 
 		for nodeID := range nodeAlertHealth {
@@ -678,23 +1139,66 @@ func (b *GraphBuilder) enrichWithAlerts(nodes []Node, edges []Edge, fetched []al
 	return alertInfos
 }
 
+// alertLabels returns the labels that state rules are matched against.
+// Alerts without labels (e.g. from tests or older clients) match on name only.
+func alertLabels(a alerts.Alert) map[string]string {
+	if a.Labels != nil {
+		return a.Labels
+	}
+	return map[string]string{"alertname": a.AlertName}
+}
+
 // historicalToAlerts converts HistoricalAlert slice to alerts.Alert slice
-// for compatibility with enrichWithAlerts.
-func historicalToAlerts(hist []HistoricalAlert) []alerts.Alert {
+// for compatibility with enrichWithAlerts. Alerts with labels go through the
+// alert mapping like live ones, so both modes attach an alert to the same
+// edge; alerts without labels (e.g. from tests) keep their own fields.
+func (b *GraphBuilder) historicalToAlerts(hist []HistoricalAlert) []alerts.Alert {
 	result := make([]alerts.Alert, 0, len(hist))
 	for _, h := range hist {
-		result = append(result, alerts.Alert{
-			AlertName:  h.AlertName,
-			Service:    h.Service,
-			Dependency: h.Dependency,
-			Severity:   h.Severity,
-			State:      "firing",
-		})
+		if h.Labels == nil {
+			result = append(result, alerts.Alert{
+				AlertName:  h.AlertName,
+				Service:    h.Service,
+				Dependency: h.Dependency,
+				Namespace:  h.Namespace,
+				Severity:   h.Severity,
+				State:      alerts.StateFiring,
+			})
+			continue
+		}
+		a, ok := b.alertMapping.Map(h.Labels)
+		if !ok {
+			continue
+		}
+		a.State = alerts.StateFiring
+		result = append(result, a)
 	}
 	return result
 }
 
 // QueryInstances returns all instances (pods/containers) for a given service.
-func (b *GraphBuilder) QueryInstances(ctx context.Context, serviceName string) ([]Instance, error) {
-	return b.prom.QueryInstances(ctx, serviceName)
+// With several sources, serviceID is a node ID of the merged topology: the
+// instances are queried on the source named by its prefix. An unprefixed
+// service name is looked up on every source serving the selected tenant.
+func (b *GraphBuilder) QueryInstances(ctx context.Context, serviceID string) ([]Instance, error) {
+	if len(b.sources) == 1 {
+		return b.sources[0].Prom.QueryInstances(ctx, serviceID)
+	}
+	if name, service, ok := strings.Cut(serviceID, "/"); ok {
+		for _, src := range b.sources {
+			if src.Name == name {
+				return src.Prom.QueryInstances(ctx, service)
+			}
+		}
+	}
+
+	var instances []Instance
+	for _, src := range b.Sources(ctx) {
+		found, err := src.Prom.QueryInstances(ctx, serviceID)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", src.Name, err)
+		}
+		instances = append(instances, found...)
+	}
+	return instances, nil
 }
@@ -3,6 +3,8 @@ package topology
 import (
 	"context"
 	"errors"
+	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,10 +27,14 @@ type mockPrometheusClient struct {
 	lookbackEdges    []TopologyEdge // edges returned by lookback query (nil = same as edges)
 	health           map[EdgeKey]float64
 	avg              map[EdgeKey]float64
-	p99              map[EdgeKey]float64
+	quantiles        map[float64]map[EdgeKey]float64 // latency quantile → values
+	quantileErr      error                           // override for QueryLatencyQuantile
+	errorRatio       map[EdgeKey]float64
+	errorRatioErr    error              // override for QueryErrorRatio
 	depStatus        map[EdgeKey]string // SDK v0.4.1 dependency status
 	depDetail        map[EdgeKey]string // SDK v0.4.1 dependency status detail
 	historicalAlerts []HistoricalAlert  // historical alerts for history mode
+	statusRange      []RangeResult      // series returned by QueryStatusRange
 	err              error              // default error for all methods
 	edgesErr         error              // override for QueryTopologyEdges
 	lookbackErr      error              // override for QueryTopologyEdgesLookback
@@ -70,8 +76,18 @@ func (m *mockPrometheusClient) QueryAvgLatency(_ context.Context, _ QueryOptions
 	return m.avg, m.err
 }
 
-func (m *mockPrometheusClient) QueryP99Latency(_ context.Context, _ QueryOptions) (map[EdgeKey]float64, error) {
-	return m.p99, m.err
+func (m *mockPrometheusClient) QueryLatencyQuantile(_ context.Context, _ QueryOptions, quantile float64) (map[EdgeKey]float64, error) {
+	if m.quantileErr != nil {
+		return nil, m.quantileErr
+	}
+	return m.quantiles[quantile], m.err
+}
+
+func (m *mockPrometheusClient) QueryErrorRatio(_ context.Context, _ QueryOptions) (map[EdgeKey]float64, error) {
+	if m.errorRatioErr != nil {
+		return nil, m.errorRatioErr
+	}
+	return m.errorRatio, m.err
 }
 
 func (m *mockPrometheusClient) QueryInstances(_ context.Context, _ string) ([]Instance, error) {
@@ -100,6 +116,10 @@ func (m *mockPrometheusClient) QueryHistoricalAlerts(_ context.Context, _ time.T
 }
 
 func (m *mockPrometheusClient) QueryStatusRange(_ context.Context, _, _ time.Time, _ time.Duration, _ string) ([]RangeResult, error) {
+	return m.statusRange, m.err
+}
+
+func (m *mockPrometheusClient) QueryStatusDetailRange(_ context.Context, _, _ time.Time, _ time.Duration, _ string) ([]RangeResult, error) {
 	return nil, m.err
 }
 
@@ -111,13 +131,13 @@ func TestGraphBuilder_Build(t *testing.T) {
 			{Name: "svc-python", Namespace: "default", Dependency: "postgres", Type: "postgres", Host: "pg-primary", Port: "5432", Critical: true},
 		},
 		health: map[EdgeKey]float64{
-			{Name: "svc-go", Host: "pg-primary", Port: "5432"}:    1,
-			{Name: "svc-go", Host: "redis", Port: "6379"}:         0,
+			{Name: "svc-go", Host: "pg-primary", Port: "5432"}:     1,
+			{Name: "svc-go", Host: "redis", Port: "6379"}:          0,
 			{Name: "svc-python", Host: "pg-primary", Port: "5432"}: 1,
 		},
 		avg: map[EdgeKey]float64{
-			{Name: "svc-go", Host: "pg-primary", Port: "5432"}:    0.0052,
-			{Name: "svc-go", Host: "redis", Port: "6379"}:         0.001,
+			{Name: "svc-go", Host: "pg-primary", Port: "5432"}:     0.0052,
+			{Name: "svc-go", Host: "redis", Port: "6379"}:          0.001,
 			{Name: "svc-python", Host: "pg-primary", Port: "5432"}: 0.003,
 		},
 	}
@@ -248,7 +268,7 @@ func TestDepNodeDedup_SameEndpointMerges(t *testing.T) {
 			{Name: "svc-python", Dependency: "redis-cache", Type: "redis", Host: "redis-host", Port: "6379"},
 		},
 		health: map[EdgeKey]float64{
-			{Name: "svc-go", Host: "redis-host", Port: "6379"}:    1,
+			{Name: "svc-go", Host: "redis-host", Port: "6379"}:     1,
 			{Name: "svc-python", Host: "redis-host", Port: "6379"}: 0,
 		},
 		avg: map[EdgeKey]float64{},
@@ -634,6 +654,153 @@ func TestBuildWithAlerts(t *testing.T) {
 	}
 }
 
+func TestBuildWithCustomAlertMapping(t *testing.T) {
+	promMock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+			{Name: "svc-go", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379", Critical: false},
+		},
+		health: map[EdgeKey]float64{
+			{Name: "svc-go", Host: "pg", Port: "5432"}:    1,
+			{Name: "svc-go", Host: "redis", Port: "6379"}: 1,
+		},
+		avg: map[EdgeKey]float64{},
+	}
+
+	mapping := alerts.Mapping{
+		ServiceLabels:  []string{"app"},
+		EndpointLabels: []string{"endpoint"},
+		StateRules: []alerts.StateRule{
+			{Matchers: map[string]string{"impact": "outage"}, State: alerts.EdgeStateDown},
+		},
+	}
+	pgAlert, _ := mapping.Map(map[string]string{"alertname": "PgUnavailable", "app": "svc-go", "endpoint": "pg:5432", "impact": "outage"})
+	// DependencyDown no longer overrides state once the rule table replaces the defaults.
+	redisAlert, _ := mapping.Map(map[string]string{"alertname": "DependencyDown", "app": "svc-go", "endpoint": "redis:6379"})
+	amMock := &mockAlertManagerClient{alerts: []alerts.Alert{pgAlert, redisAlert}}
+
+	builder := NewGraphBuilder(promMock, amMock, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetAlertMapping(mapping)
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	edgeByTarget := make(map[string]Edge)
+	for _, e := range resp.Edges {
+		edgeByTarget[e.Target] = e
+	}
+	if got := edgeByTarget["pg:5432"]; got.State != "down" || got.AlertCount != 1 {
+		t.Errorf("pg:5432 edge State = %q, AlertCount = %d, want down, 1", got.State, got.AlertCount)
+	}
+	if got := edgeByTarget["redis:6379"]; got.State != "ok" || got.AlertCount != 1 {
+		t.Errorf("redis:6379 edge State = %q, AlertCount = %d, want ok, 1", got.State, got.AlertCount)
+	}
+}
+
+func TestBuildWithCustomAlertMapping_HistoryMode(t *testing.T) {
+	promMock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+			{Name: "svc-go", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379", Critical: false},
+		},
+		health: map[EdgeKey]float64{
+			{Name: "svc-go", Host: "pg", Port: "5432"}:    1,
+			{Name: "svc-go", Host: "redis", Port: "6379"}: 1,
+		},
+		avg: map[EdgeKey]float64{},
+		// ALERTS series as QueryHistoricalAlerts returns them: the service
+		// is only in the "app" label, which the default lookup does not know.
+		historicalAlerts: []HistoricalAlert{
+			{AlertName: "PgUnavailable", Labels: map[string]string{"alertname": "PgUnavailable", "app": "svc-go", "endpoint": "pg:5432", "impact": "outage", "level": "critical"}},
+			{AlertName: "DependencyDown", Service: "svc-go", Dependency: "redis", Labels: map[string]string{"alertname": "DependencyDown", "job": "svc-go", "dependency": "redis"}},
+		},
+	}
+
+	mapping := alerts.Mapping{
+		ServiceLabels:  []string{"app"},
+		EndpointLabels: []string{"endpoint"},
+		SeverityLabel:  "level",
+		StateRules: []alerts.StateRule{
+			{Matchers: map[string]string{"impact": "outage"}, State: alerts.EdgeStateDown},
+		},
+	}
+	builder := NewGraphBuilder(promMock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetAlertMapping(mapping)
+
+	ts := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	resp, err := builder.Build(context.Background(), QueryOptions{Time: &ts})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	edgeByTarget := make(map[string]Edge)
+	for _, e := range resp.Edges {
+		edgeByTarget[e.Target] = e
+	}
+	if got := edgeByTarget["pg:5432"]; got.State != "down" || got.AlertCount != 1 || got.AlertSeverity != "critical" {
+		t.Errorf("pg:5432 edge State = %q, AlertCount = %d, AlertSeverity = %q, want down, 1, critical", got.State, got.AlertCount, got.AlertSeverity)
+	}
+	// The mapping has no "job" service label, so the redis alert is not attached.
+	if got := edgeByTarget["redis:6379"]; got.State != "ok" || got.AlertCount != 0 {
+		t.Errorf("redis:6379 edge State = %q, AlertCount = %d, want ok, 0", got.State, got.AlertCount)
+	}
+	if len(resp.Alerts) != 1 || resp.Alerts[0].AlertName != "PgUnavailable" {
+		t.Errorf("Alerts = %+v, want only PgUnavailable", resp.Alerts)
+	}
+}
+
+func TestBuildWithAcknowledgedAlerts(t *testing.T) {
+	promMock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+		},
+		health: map[EdgeKey]float64{
+			{Name: "svc-go", Host: "pg", Port: "5432"}: 1,
+		},
+		avg: map[EdgeKey]float64{},
+	}
+	amMock := &mockAlertManagerClient{
+		alerts: []alerts.Alert{
+			{
+				AlertName:        "DependencyDown",
+				Service:          "svc-go",
+				Dependency:       "postgres",
+				Severity:         "critical",
+				State:            alerts.StateSilenced,
+				SilenceID:        "s-1",
+				SilenceCreatedBy: "alice",
+				SilenceEndsAt:    "2026-02-08T12:00:00Z",
+			},
+		},
+	}
+
+	builder := NewGraphBuilder(promMock, amMock, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	if len(resp.Alerts) != 1 || resp.Alerts[0].State != alerts.StateSilenced || resp.Alerts[0].SilenceCreatedBy != "alice" {
+		t.Fatalf("Alerts = %+v, want one silenced alert created by alice", resp.Alerts)
+	}
+
+	// Silenced alert is acknowledged: it neither overrides state nor counts as active.
+	e := resp.Edges[0]
+	if e.State != "ok" || e.AlertCount != 0 || e.AlertSeverity != "" || e.AckCount != 1 {
+		t.Errorf("edge State=%q AlertCount=%d AlertSeverity=%q AckCount=%d, want ok/0/\"\"/1",
+			e.State, e.AlertCount, e.AlertSeverity, e.AckCount)
+	}
+	for _, n := range resp.Nodes {
+		if n.ID != "svc-go" {
+			continue
+		}
+		if n.State != "ok" || n.AlertCount != 0 || n.AckCount != 1 {
+			t.Errorf("svc-go State=%q AlertCount=%d AckCount=%d, want ok/0/1", n.State, n.AlertCount, n.AckCount)
+		}
+	}
+}
+
 func TestBuildWithNilAlertManager(t *testing.T) {
 	promMock := &mockPrometheusClient{
 		edges: []TopologyEdge{
@@ -656,6 +823,105 @@ func TestBuildWithNilAlertManager(t *testing.T) {
 	}
 }
 
+func TestGraphBuilder_LatencyPercentiles(t *testing.T) {
+	pgKey := EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}
+	redisKey := EdgeKey{Name: "svc-go", Host: "redis", Port: "6379"}
+	mock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+			{Name: "svc-go", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"},
+		},
+		health: map[EdgeKey]float64{pgKey: 1, redisKey: 1},
+		avg:    map[EdgeKey]float64{pgKey: 0.004, redisKey: 0.001},
+		quantiles: map[float64]map[EdgeKey]float64{
+			0.50: {pgKey: 0.003, redisKey: 0.001},
+			0.95: {pgKey: 0.020, redisKey: 0.002},
+			0.99: {pgKey: 0.250, redisKey: math.NaN()},
+		},
+	}
+
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetLatencyThresholds(LatencyThresholds{
+		Percentile: "p99",
+		ByType:     map[string]time.Duration{"default": 100 * time.Millisecond, "postgres": 200 * time.Millisecond},
+	})
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	edgeByTarget := make(map[string]Edge)
+	for _, e := range resp.Edges {
+		edgeByTarget[e.Target] = e
+	}
+
+	pg := edgeByTarget["pg:5432"]
+	if pg.LatencyP50 != 0.003 || pg.LatencyP95 != 0.020 || pg.LatencyP99 != 0.250 {
+		t.Errorf("pg percentiles = %v/%v/%v, want 0.003/0.020/0.250", pg.LatencyP50, pg.LatencyP95, pg.LatencyP99)
+	}
+	if pg.State != "degraded" {
+		t.Errorf("pg edge State = %q, want degraded (p99 250ms > 200ms)", pg.State)
+	}
+
+	redis := edgeByTarget["redis:6379"]
+	if redis.LatencyP99 != 0 {
+		t.Errorf("redis LatencyP99 = %v, want 0 (NaN ignored)", redis.LatencyP99)
+	}
+	if redis.State != "ok" {
+		t.Errorf("redis edge State = %q, want ok", redis.State)
+	}
+
+	nodeByID := make(map[string]Node)
+	for _, n := range resp.Nodes {
+		nodeByID[n.ID] = n
+	}
+	if got := nodeByID["svc-go"].State; got != "degraded" {
+		t.Errorf("svc-go State = %q, want degraded", got)
+	}
+	if got := nodeByID["pg:5432"].State; got != "degraded" {
+		t.Errorf("pg:5432 State = %q, want degraded", got)
+	}
+	if got := nodeByID["redis:6379"].State; got != "ok" {
+		t.Errorf("redis:6379 State = %q, want ok", got)
+	}
+}
+
+func TestGraphBuilder_LatencyThresholdsDisabled(t *testing.T) {
+	key := EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}
+	mock := &mockPrometheusClient{
+		edges:     []TopologyEdge{{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432"}},
+		health:    map[EdgeKey]float64{key: 1},
+		quantiles: map[float64]map[EdgeKey]float64{0.99: {key: 5}},
+	}
+
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if resp.Edges[0].State != "ok" {
+		t.Errorf("edge State = %q, want ok without thresholds", resp.Edges[0].State)
+	}
+}
+
+func TestGraphBuilder_LatencyQuantileError(t *testing.T) {
+	key := EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}
+	mock := &mockPrometheusClient{
+		edges:       []TopologyEdge{{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432"}},
+		health:      map[EdgeKey]float64{key: 1},
+		quantileErr: errors.New("no histogram"),
+	}
+
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !resp.Meta.Partial || len(resp.Meta.Errors) != 3 {
+		t.Errorf("Meta = %+v, want partial with 3 quantile errors", resp.Meta)
+	}
+}
+
 func TestGraphBuilder_ConnectedGraph(t *testing.T) {
 	// Service-to-service edges should produce a connected (through) graph.
 	// uniproxy-01 → uniproxy-02 → redis
@@ -905,6 +1171,44 @@ func TestBuildPartialWithAlertFailure(t *testing.T) {
 	}
 }
 
+func TestBuildPartialAlertEndpoints(t *testing.T) {
+	mock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+		},
+		health: map[EdgeKey]float64{
+			{Name: "svc-go", Host: "pg", Port: "5432"}: 0,
+		},
+		avg: map[EdgeKey]float64{},
+	}
+
+	amMock := &mockAlertManagerClient{
+		alerts: []alerts.Alert{
+			{AlertName: "DependencyDown", Service: "svc-go", Dependency: "postgres", Severity: "critical"},
+		},
+		err: &alerts.PartialError{Endpoints: []*alerts.EndpointError{
+			{URL: "http://am-2:9093", Err: errors.New("connection refused")},
+			{URL: "http://am-eu:9093", Err: errors.New("timeout")},
+		}},
+	}
+
+	builder := NewGraphBuilder(mock, amMock, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resp.Alerts) != 1 {
+		t.Errorf("got %d alerts, want 1 from the reachable endpoint", len(resp.Alerts))
+	}
+	if len(resp.Meta.Errors) != 2 {
+		t.Fatalf("Meta.Errors = %v, want one entry per failed endpoint", resp.Meta.Errors)
+	}
+	if !strings.Contains(resp.Meta.Errors[0], "http://am-2:9093") {
+		t.Errorf("Meta.Errors[0] = %q, want the failed endpoint URL", resp.Meta.Errors[0])
+	}
+}
+
 func TestAlertSeverityPriority(t *testing.T) {
 	// Two alerts on the same service: warning + critical → worst should be critical.
 	promMock := &mockPrometheusClient{
@@ -1239,12 +1543,12 @@ func TestBuildWithDependencyStatus(t *testing.T) {
 		},
 		health: map[EdgeKey]float64{
 			{Name: "svc-go", Host: "pg", Port: "5432"}:    1,
-			{Name: "svc-go", Host: "redis", Port: "6379"}:  0,
-			{Name: "svc-old", Host: "pg", Port: "5432"}:    1,
+			{Name: "svc-go", Host: "redis", Port: "6379"}: 0,
+			{Name: "svc-old", Host: "pg", Port: "5432"}:   1,
 		},
 		avg: map[EdgeKey]float64{},
 		depStatus: map[EdgeKey]string{
-			{Name: "svc-go", Host: "pg", Port: "5432"}:   "ok",
+			{Name: "svc-go", Host: "pg", Port: "5432"}:    "ok",
 			{Name: "svc-go", Host: "redis", Port: "6379"}: "timeout",
 		},
 		depDetail: map[EdgeKey]string{
@@ -1331,8 +1635,8 @@ func TestStaleDetection_AllStale(t *testing.T) {
 			{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432"},
 			{Name: "svc-go", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"},
 		},
-		health:     map[EdgeKey]float64{}, // empty — nothing is current
-		avg:        map[EdgeKey]float64{},
+		health: map[EdgeKey]float64{}, // empty — nothing is current
+		avg:    map[EdgeKey]float64{},
 	}
 
 	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, time.Hour, nil, testSeverityLevels())
@@ -1483,7 +1787,7 @@ func TestGroupPropagation(t *testing.T) {
 			{Name: "svc-python", Namespace: "ns1", Group: "cluster-2", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"},
 		},
 		health: map[EdgeKey]float64{
-			{Name: "svc-go", Host: "pg", Port: "5432"}:    1,
+			{Name: "svc-go", Host: "pg", Port: "5432"}:        1,
 			{Name: "svc-python", Host: "redis", Port: "6379"}: 1,
 		},
 		avg: map[EdgeKey]float64{},
@@ -1699,8 +2003,8 @@ func TestIsEntryFromLabel(t *testing.T) {
 		},
 		health: map[EdgeKey]float64{
 			{Name: "svc-a", Host: "svc-b.ns1.svc", Port: "8080"}: 1,
-			{Name: "svc-b", Host: "redis-host", Port: "6379"}:     1,
-			{Name: "svc-d", Host: "redis-host", Port: "6379"}:     1,
+			{Name: "svc-b", Host: "redis-host", Port: "6379"}:    1,
+			{Name: "svc-d", Host: "redis-host", Port: "6379"}:    1,
 		},
 		avg: map[EdgeKey]float64{},
 	}
@@ -1930,3 +2234,86 @@ func TestEdgeBetter(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildMultipleSources(t *testing.T) {
+	newSource := func(svc string) *mockPrometheusClient {
+		return &mockPrometheusClient{
+			edges: []TopologyEdge{
+				{Name: svc, Namespace: "prod", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+			},
+			health: map[EdgeKey]float64{{Name: svc, Host: "pg", Port: "5432"}: 1},
+			avg:    map[EdgeKey]float64{},
+		}
+	}
+
+	builder := NewGraphBuilder(nil, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetSources([]Source{
+		{Name: "eu", Prom: newSource("svc-a")},
+		{Name: "us", Prom: newSource("svc-a")},
+	})
+
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if len(resp.Nodes) != 4 {
+		t.Fatalf("got %d nodes, want 4 (service + postgres per source)", len(resp.Nodes))
+	}
+	if len(resp.Edges) != 2 {
+		t.Fatalf("got %d edges, want 2", len(resp.Edges))
+	}
+
+	nodeMap := make(map[string]Node, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		nodeMap[n.ID] = n
+	}
+	for _, id := range []string{"eu/svc-a", "us/svc-a", "eu/pg:5432", "us/pg:5432"} {
+		if _, ok := nodeMap[id]; !ok {
+			t.Errorf("missing node %q", id)
+		}
+	}
+	if n := nodeMap["us/svc-a"]; n.Cluster != "us" || n.Label != "svc-a" {
+		t.Errorf("node us/svc-a: Cluster=%q Label=%q, want us/svc-a", n.Cluster, n.Label)
+	}
+	for _, e := range resp.Edges {
+		if e.Source != e.Cluster+"/svc-a" || e.Target != e.Cluster+"/pg:5432" {
+			t.Errorf("edge %s→%s does not match its cluster %q", e.Source, e.Target, e.Cluster)
+		}
+	}
+	if resp.Meta.Partial {
+		t.Errorf("Meta.Partial = true, want false; errors: %v", resp.Meta.Errors)
+	}
+}
+
+func TestBuildMultipleSources_PartialFailure(t *testing.T) {
+	ok := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-a", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"},
+		},
+		health: map[EdgeKey]float64{{Name: "svc-a", Host: "redis", Port: "6379"}: 1},
+		avg:    map[EdgeKey]float64{},
+	}
+	down := &mockPrometheusClient{edgesErr: errors.New("connection refused")}
+
+	builder := NewGraphBuilder(nil, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetSources([]Source{{Name: "eu", Prom: ok}, {Name: "us", Prom: down}})
+
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if len(resp.Nodes) != 2 {
+		t.Errorf("got %d nodes, want 2 from the healthy source", len(resp.Nodes))
+	}
+	if !resp.Meta.Partial {
+		t.Error("expected Meta.Partial = true when a source fails")
+	}
+	if len(resp.Meta.Errors) != 1 || !strings.HasPrefix(resp.Meta.Errors[0], "us: ") {
+		t.Errorf("Meta.Errors = %v, want one error prefixed with the failed source", resp.Meta.Errors)
+	}
+
+	builder.SetSources([]Source{{Name: "eu", Prom: down}, {Name: "us", Prom: down}})
+	if _, err := builder.Build(context.Background(), QueryOptions{}); err == nil {
+		t.Error("expected error when all sources fail")
+	}
+}
@@ -6,8 +6,8 @@ import "time"
 type Node struct {
 	ID              string `json:"id"`
 	Label           string `json:"label"`
-	State           string `json:"state"`           // "ok", "degraded", "down", "unknown"
-	Type            string `json:"type"`            // "service" or dependency type
+	State           string `json:"state"` // "ok", "degraded", "down", "unknown"
+	Type            string `json:"type"`  // "service" or dependency type
 	Namespace       string `json:"namespace"`
 	Group           string `json:"group,omitempty"`
 	Host            string `json:"host,omitempty"`
@@ -15,27 +15,42 @@ type Node struct {
 	DependencyCount int    `json:"dependencyCount"`
 	Stale           bool   `json:"stale,omitempty"`
 	IsEntry         bool   `json:"isEntry,omitempty"`
+	InCycle         bool   `json:"inCycle,omitempty"` // part of a dependency cycle, see FindCycles
 	GrafanaURL      string `json:"grafanaUrl,omitempty"`
 	AlertCount      int    `json:"alertCount,omitempty"`
 	AlertSeverity   string `json:"alertSeverity,omitempty"`
+	AckCount        int    `json:"acknowledgedCount,omitempty"` // silenced or inhibited alerts
+	Cluster         string `json:"cluster,omitempty"`           // Name of the Prometheus source (when set).
 }
 
 // Edge represents a directed dependency edge between two nodes.
 type Edge struct {
-	Source        string  `json:"source"`
-	Target        string  `json:"target"`
-	Type          string  `json:"type,omitempty"`  // grpc, http, postgres, redis, etc.
-	Latency       string  `json:"latency"`         // human-readable "5.2ms"
-	LatencyRaw    float64 `json:"latencyRaw"`
-	Health        float64 `json:"health"`          // 0 or 1; -1 for stale
-	State         string  `json:"state"`           // "ok", "degraded", "down", "unknown"
-	Critical      bool    `json:"critical"`
-	Status        string  `json:"status,omitempty"`  // SDK v0.4.1: ok, timeout, connection_error, dns_error, auth_error, tls_error, unhealthy, error
-	Detail        string  `json:"detail,omitempty"`  // SDK v0.4.1: e.g. http_503, grpc_not_serving, connection_refused
-	Stale         bool    `json:"stale,omitempty"`
-	GrafanaURL    string  `json:"grafanaUrl,omitempty"`
-	AlertCount    int     `json:"alertCount,omitempty"`
-	AlertSeverity string  `json:"alertSeverity,omitempty"`
+	Source         string  `json:"source"`
+	Target         string  `json:"target"`
+	Type           string  `json:"type,omitempty"`       // grpc, http, postgres, redis, etc.
+	Dependency     string  `json:"dependency,omitempty"` // dependency name as reported by the source service
+	Latency        string  `json:"latency"`              // human-readable "5.2ms"
+	LatencyRaw     float64 `json:"latencyRaw"`
+	LatencyP50     float64 `json:"latencyP50,omitempty"`     // seconds, from latency histogram
+	LatencyP95     float64 `json:"latencyP95,omitempty"`     // seconds, from latency histogram
+	LatencyP99     float64 `json:"latencyP99,omitempty"`     // seconds, from latency histogram
+	ErrorRatio     float64 `json:"errorRatio,omitempty"`     // fraction of time in a non-ok status (only when error ratio thresholds are configured)
+	Health         float64 `json:"health"`                   // 0 or 1; -1 for stale
+	State          string  `json:"state"`                    // "ok", "degraded", "down", "unknown"
+	DegradedReason string  `json:"degradedReason,omitempty"` // why a healthy edge is "degraded", e.g. "p99 latency > 200ms"
+	Critical       bool    `json:"critical"`
+	Status         string  `json:"status,omitempty"` // SDK v0.4.1: ok, timeout, connection_error, dns_error, auth_error, tls_error, unhealthy, error
+	Detail         string  `json:"detail,omitempty"` // SDK v0.4.1: e.g. http_503, grpc_not_serving, connection_refused
+	Stale          bool    `json:"stale,omitempty"`
+	Flapping       bool    `json:"flapping,omitempty"`      // status toggles too often, see topology.flapping
+	Transitions    int     `json:"transitions,omitempty"`   // status transitions within the flap window
+	InCycle        bool    `json:"inCycle,omitempty"`       // both ends are in the same dependency cycle
+	CriticalCycle  bool    `json:"criticalCycle,omitempty"` // on a cycle of critical edges only
+	GrafanaURL     string  `json:"grafanaUrl,omitempty"`
+	AlertCount     int     `json:"alertCount,omitempty"`
+	AlertSeverity  string  `json:"alertSeverity,omitempty"`
+	AckCount       int     `json:"acknowledgedCount,omitempty"` // silenced or inhibited alerts
+	Cluster        string  `json:"cluster,omitempty"`           // Name of the Prometheus source (when set).
 }
 
 // AlertInfo represents an active alert associated with the topology.
@@ -44,9 +59,13 @@ type AlertInfo struct {
 	Service    string `json:"service"`
 	Dependency string `json:"dependency"`
 	Severity   string `json:"severity"`
-	State      string `json:"state"`
+	State      string `json:"state"` // "firing", "silenced", "inhibited"
 	Since      string `json:"since"`
 	Summary    string `json:"summary,omitempty"`
+	// Set for silenced alerts: the silence that expires last.
+	SilenceID        string `json:"silenceId,omitempty"`
+	SilenceCreatedBy string `json:"silenceCreatedBy,omitempty"`
+	SilenceEndsAt    string `json:"silenceEndsAt,omitempty"`
 }
 
 // TopologyMeta holds metadata about the topology response.
@@ -59,15 +78,25 @@ type TopologyMeta struct {
 	Errors    []string   `json:"errors,omitempty"`
 	Time      *time.Time `json:"time,omitempty"`      // Historical timestamp when set.
 	IsHistory bool       `json:"isHistory,omitempty"` // True when viewing historical data.
+	Stale     bool       `json:"stale,omitempty"`     // True when serving last-known-good data after a failed rebuild.
+	// Set when a historical response was served from the snapshot store: the time the snapshot was taken.
+	SnapshotAt *time.Time `json:"snapshotAt,omitempty"`
+	// Datasource queries of the build, with their durations and errors.
+	Queries []QueryTiming `json:"queries,omitempty"`
+	// Circuit breaker state of each Prometheus source after the build.
+	Datasources []DatasourceStatus `json:"datasources,omitempty"`
+	// Tenant selected for the build with WithTenant (omitted for the defaults).
+	Tenant string `json:"tenant,omitempty"`
 }
 
 // HistoricalAlert represents an alert reconstructed from the ALERTS metric at a historical timestamp.
 type HistoricalAlert struct {
 	AlertName  string
 	Namespace  string
-	Service    string // "name", "service", or "job" label
+	Service    string // "name", "service", or "job" label; see QueryHistoricalAlerts
 	Dependency string // "dependency" label (target)
 	Severity   string
+	Labels     map[string]string // all labels of the ALERTS series, for state rules
 }
 
 // TimeValue is a single data point in a range query result.
@@ -78,9 +107,14 @@ type TimeValue struct {
 
 // RangeResult represents a single time series from a range query, identified by EdgeKey and status label.
 type RangeResult struct {
-	Key    EdgeKey
-	Status string
-	Values []TimeValue
+	Key        EdgeKey
+	Namespace  string
+	Dependency string
+	Type       string
+	Critical   bool
+	Status     string // status label (QueryStatusRange)
+	Detail     string // detail label (QueryStatusDetailRange)
+	Values     []TimeValue
 }
 
 // TopologyResponse is the complete topology API response.
@@ -120,8 +154,8 @@ type QueryOptions struct {
 
 // Instance represents a single instance (pod or container) of a service.
 type Instance struct {
-	Instance string `json:"instance"`           // Required: host:port or instance identifier
-	Pod      string `json:"pod,omitempty"`      // Optional: Kubernetes pod name
-	Job      string `json:"job,omitempty"`      // Optional: Prometheus job label
-	Service  string `json:"service,omitempty"`  // Service name this instance belongs to
+	Instance string `json:"instance"`          // Required: host:port or instance identifier
+	Pod      string `json:"pod,omitempty"`     // Optional: Kubernetes pod name
+	Job      string `json:"job,omitempty"`     // Optional: Prometheus job label
+	Service  string `json:"service,omitempty"` // Service name this instance belongs to
 }
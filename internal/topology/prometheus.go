@@ -3,13 +3,18 @@ package topology
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
 )
 
 // PrometheusClient queries Prometheus/VictoriaMetrics for topology data.
@@ -23,8 +28,13 @@ type PrometheusClient interface {
 	// QueryAvgLatency returns the average latency per edge.
 	QueryAvgLatency(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error)
 
-	// QueryP99Latency returns the P99 latency per edge.
-	QueryP99Latency(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error)
+	// QueryLatencyQuantile returns the given latency quantile (e.g. 0.99) per edge,
+	// computed from the app_dependency_latency_seconds histogram.
+	QueryLatencyQuantile(ctx context.Context, opts QueryOptions, quantile float64) (map[EdgeKey]float64, error)
+
+	// QueryErrorRatio returns, per edge, the fraction of instance-time within the
+	// latency window during which app_dependency_status reported a non-ok status.
+	QueryErrorRatio(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error)
 
 	// QueryTopologyEdgesLookback returns all unique topology edges seen within
 	// the given lookback window, including stale (disappeared) series.
@@ -49,6 +59,10 @@ type PrometheusClient interface {
 	// QueryStatusRange queries app_dependency_status == 1 over a time range
 	// and returns per-edge time series data for transition detection.
 	QueryStatusRange(ctx context.Context, start, end time.Time, step time.Duration, namespace string) ([]RangeResult, error)
+
+	// QueryStatusDetailRange queries app_dependency_status_detail == 1 over a
+	// time range and returns per-edge detail series.
+	QueryStatusDetailRange(ctx context.Context, start, end time.Time, step time.Duration, namespace string) ([]RangeResult, error)
 }
 
 // PrometheusConfig holds Prometheus connection settings.
@@ -57,11 +71,28 @@ type PrometheusConfig struct {
 	Username string
 	Password string
 	Timeout  time.Duration
+	// LatencyWindow is the rate() window for latency queries (default: 5m).
+	LatencyWindow time.Duration
+	// Transport carries authentication, TLS and proxy settings (nil = http.DefaultTransport).
+	Transport http.RoundTripper
+	// Tenant selects the tenant of a multi-tenant backend; zero value disables tenancy.
+	Tenant config.TenantConfig
+	// Retry controls retries of failed queries; zero value disables them.
+	Retry config.RetryConfig
+	// CircuitBreaker controls failing fast while the datasource is
+	// unavailable; zero value disables it.
+	CircuitBreaker config.CircuitBreakerConfig
+	// Metrics holds metric and label names; empty fields take the SDK defaults.
+	Metrics config.MetricsConfig
 }
 
 type prometheusClient struct {
 	cfg    PrometheusConfig
 	client *http.Client
+	q      promQL
+
+	breakersMu sync.Mutex
+	breakers   map[string]*breaker // per queried tenant, see breakerFor
 }
 
 // NewPrometheusClient creates a new Prometheus client.
@@ -70,28 +101,43 @@ func NewPrometheusClient(cfg PrometheusConfig) PrometheusClient {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
+	if cfg.LatencyWindow == 0 {
+		cfg.LatencyWindow = 5 * time.Minute
+	}
+	if cfg.Tenant.Mode == config.TenantModeHeader && cfg.Tenant.Header == "" {
+		cfg.Tenant.Header = defaultTenantHeader
+	}
 	return &prometheusClient{
-		cfg:    cfg,
-		client: &http.Client{Timeout: timeout},
-	}
-}
-
-// PromQL query templates for topology construction.
-// When namespace is provided, a label filter is injected.
-const (
-	queryTopologyEdges = `group by (name, namespace, group, dependency, type, host, port, critical, isentry) (app_dependency_health%s)`
-	queryHealthState   = `app_dependency_health%s`
-	queryAvgLatency    = `rate(app_dependency_latency_seconds_sum%s[5m]) / rate(app_dependency_latency_seconds_count%s[5m])`
-	queryP99Latency    = `histogram_quantile(0.99, rate(app_dependency_latency_seconds_bucket%s[5m]))`
-	queryInstances     = `group by (instance, pod, job) (app_dependency_health{name="%s"})`
-	// queryTopologyEdgesLookback uses last_over_time to include stale series.
-	queryTopologyEdgesLookback = `group by (name, namespace, group, dependency, type, host, port, critical, isentry) (last_over_time(app_dependency_health%s[%s]))`
-	// SDK v0.4.1: dependency status (enum pattern, exactly one series == 1 per endpoint).
-	queryDependencyStatus       = `app_dependency_status%s == 1`
-	queryDependencyStatusDetail = `app_dependency_status_detail%s == 1`
-	// ALERTS metric is automatically generated by Prometheus/VM for all alerting rules.
-	queryHistoricalAlerts = `ALERTS{alertstate="firing"}`
-)
+		cfg:      cfg,
+		client:   &http.Client{Timeout: timeout, Transport: cfg.Transport},
+		q:        newPromQL(cfg.Metrics),
+		breakers: make(map[string]*breaker),
+	}
+}
+
+// CircuitState returns the state of the circuit breaker of the tenant
+// selected in ctx. A tenant the client does not serve reports closed.
+func (c *prometheusClient) CircuitState(ctx context.Context) CircuitState {
+	tenant, err := c.tenant(ctx)
+	if err != nil {
+		return CircuitClosed
+	}
+	return c.breakerFor(tenant).current()
+}
+
+// breakerFor returns the circuit breaker of tenant, so that an overloaded
+// tenant does not fail the queries of the others. The tenants are limited
+// to the configured ones, so the map stays bounded.
+func (c *prometheusClient) breakerFor(tenant string) *breaker {
+	c.breakersMu.Lock()
+	defer c.breakersMu.Unlock()
+	b, ok := c.breakers[tenant]
+	if !ok {
+		b = newBreaker(c.cfg.CircuitBreaker)
+		c.breakers[tenant] = b
+	}
+	return b
+}
 
 // sanitizePromQLValue escapes special characters in a PromQL label matcher value
 // to prevent PromQL injection via user-supplied filter parameters.
@@ -102,33 +148,6 @@ func sanitizePromQLValue(s string) string {
 	return s
 }
 
-// nsFilter returns a PromQL label filter for the given namespace.
-// Returns empty string if namespace is empty.
-func nsFilter(ns string) string {
-	if ns == "" {
-		return ""
-	}
-	return fmt.Sprintf(`{namespace="%s"}`, sanitizePromQLValue(ns))
-}
-
-// optFilter returns a PromQL label filter combining namespace and group from QueryOptions.
-// Returns empty string if neither is set. Examples:
-//
-//	{namespace="prod"}, {group="cluster-1"}, {namespace="prod",group="cluster-1"}
-func optFilter(opts QueryOptions) string {
-	var parts []string
-	if opts.Namespace != "" {
-		parts = append(parts, fmt.Sprintf(`namespace="%s"`, sanitizePromQLValue(opts.Namespace)))
-	}
-	if opts.Group != "" {
-		parts = append(parts, fmt.Sprintf(`group="%s"`, sanitizePromQLValue(opts.Group)))
-	}
-	if len(parts) == 0 {
-		return ""
-	}
-	return "{" + strings.Join(parts, ",") + "}"
-}
-
 // promResponse represents Prometheus API v1 instant query response.
 type promResponse struct {
 	Status string   `json:"status"`
@@ -146,20 +165,96 @@ type promResult struct {
 }
 
 func (c *prometheusClient) query(ctx context.Context, promql string, at *time.Time) ([]promResult, error) {
+	params := url.Values{"query": {promql}}
+	if at != nil {
+		params.Set("time", fmt.Sprintf("%d", at.Unix()))
+	}
+	body, err := c.get(ctx, "/api/v1/query", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr promResponse
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return nil, fmt.Errorf("parsing response: %w", err)
+	}
+
+	if pr.Status != "success" {
+		return nil, fmt.Errorf("prometheus query failed: status=%s", pr.Status)
+	}
+
+	return pr.Data.Result, nil
+}
+
+// get sends a GET request to the API path and returns the body of a 200
+// response. Queries are idempotent, so network errors, 429 and 5xx responses
+// are retried with jittered exponential backoff, waiting at least as long as
+// a Retry-After header asks; a retry that would outlast the context deadline
+// is not attempted. The outcome feeds the circuit breaker: while it is open,
+// get fails immediately with ErrCircuitOpen. Multi-tenant datasources query
+// the tenant selected in ctx (see WithTenant), each with its own breaker.
+func (c *prometheusClient) get(ctx context.Context, path string, params url.Values) ([]byte, error) {
 	u, err := url.Parse(c.cfg.URL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid prometheus URL: %w", err)
 	}
-	u.Path = "/api/v1/query"
-	params := url.Values{"query": {promql}}
-	if at != nil {
-		params.Set("time", fmt.Sprintf("%d", at.Unix()))
+	tenant, err := c.tenant(ctx)
+	if err != nil {
+		return nil, err
 	}
+	u.Path = c.tenantPath(tenant, path)
 	u.RawQuery = params.Encode()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	cb := c.breakerFor(tenant)
+	if err := cb.allow(); err != nil {
+		return nil, err
+	}
+	attempts := max(c.cfg.Retry.MaxAttempts, 1)
+	for attempt := 0; ; attempt++ {
+		body, retryAfter, err := c.do(ctx, u.String(), tenant)
+		if err == nil {
+			cb.success()
+			return body, nil
+		}
+		if ctx.Err() != nil {
+			cb.neutral()
+			return nil, err
+		}
+		var se *statusError
+		if errors.As(err, &se) && !se.retryable() {
+			// The datasource is up; the query itself was rejected.
+			cb.success()
+			return nil, err
+		}
+		if attempt+1 >= attempts || cb.current() == CircuitOpen {
+			cb.failure(retryAfter)
+			return nil, err
+		}
+		wait := max(backoff(c.cfg.Retry, attempt), retryAfter)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			cb.failure(retryAfter)
+			return nil, err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			cb.neutral()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// do performs a single request attempt. For a failed response it also
+// returns the wait requested by its Retry-After header.
+func (c *prometheusClient) do(ctx context.Context, rawURL, tenant string) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+	if c.cfg.Tenant.Mode == config.TenantModeHeader {
+		req.Header.Set(c.cfg.Tenant.Header, tenant)
 	}
 
 	if c.cfg.Username != "" {
@@ -168,29 +263,20 @@ func (c *prometheusClient) query(ctx context.Context, promql string, at *time.Ti
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("querying prometheus: %w", err)
+		return nil, 0, fmt.Errorf("querying prometheus: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return nil, 0, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("prometheus returned %d: %s", resp.StatusCode, string(body))
-	}
-
-	var pr promResponse
-	if err := json.Unmarshal(body, &pr); err != nil {
-		return nil, fmt.Errorf("parsing response: %w", err)
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			&statusError{code: resp.StatusCode, body: string(body)}
 	}
-
-	if pr.Status != "success" {
-		return nil, fmt.Errorf("prometheus query failed: status=%s", pr.Status)
-	}
-
-	return pr.Data.Result, nil
+	return body, 0, nil
 }
 
 // promRangeResponse represents Prometheus API v1 range query response.
@@ -205,45 +291,19 @@ type promRangeData struct {
 }
 
 type promMatrixEntry struct {
-	Metric map[string]string    `json:"metric"`
-	Values []json.RawMessage    `json:"values"` // each element is [timestamp, "value"]
+	Metric map[string]string `json:"metric"`
+	Values []json.RawMessage `json:"values"` // each element is [timestamp, "value"]
 }
 
 func (c *prometheusClient) queryRange(ctx context.Context, promql string, start, end time.Time, step time.Duration) ([]promMatrixEntry, error) {
-	u, err := url.Parse(c.cfg.URL)
-	if err != nil {
-		return nil, fmt.Errorf("invalid prometheus URL: %w", err)
-	}
-	u.Path = "/api/v1/query_range"
-	u.RawQuery = url.Values{
+	body, err := c.get(ctx, "/api/v1/query_range", url.Values{
 		"query": {promql},
 		"start": {fmt.Sprintf("%d", start.Unix())},
 		"end":   {fmt.Sprintf("%d", end.Unix())},
 		"step":  {fmt.Sprintf("%d", int(step.Seconds()))},
-	}.Encode()
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
-
-	if c.cfg.Username != "" {
-		req.SetBasicAuth(c.cfg.Username, c.cfg.Password)
-	}
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("querying prometheus range: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	body, err := io.ReadAll(resp.Body)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("prometheus returned %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
 	var pr promRangeResponse
@@ -261,35 +321,48 @@ func (c *prometheusClient) queryRange(ctx context.Context, promql string, start,
 // QueryStatusRange queries app_dependency_status == 1 over a range and returns
 // per-edge time series with status labels and timestamped values.
 func (c *prometheusClient) QueryStatusRange(ctx context.Context, start, end time.Time, step time.Duration, namespace string) ([]RangeResult, error) {
-	f := nsFilter(namespace)
-	promql := fmt.Sprintf(queryDependencyStatus, f)
-
-	entries, err := c.queryRange(ctx, promql, start, end, step)
+	f := c.q.nsFilter(namespace)
+	entries, err := c.queryRange(ctx, c.q.dependencyStatus(f), start, end, step)
 	if err != nil {
 		return nil, fmt.Errorf("querying status range: %w", err)
 	}
+	return c.q.parseRangeResults(entries), nil
+}
+
+// QueryStatusDetailRange queries app_dependency_status_detail == 1 over a range
+// and returns the raw time series grouped by edge key and detail label.
+func (c *prometheusClient) QueryStatusDetailRange(ctx context.Context, start, end time.Time, step time.Duration, namespace string) ([]RangeResult, error) {
+	f := c.q.nsFilter(namespace)
+	entries, err := c.queryRange(ctx, c.q.dependencyStatusDetail(f), start, end, step)
+	if err != nil {
+		return nil, fmt.Errorf("querying status detail range: %w", err)
+	}
+	return c.q.parseRangeResults(entries), nil
+}
 
+// parseRangeResults converts matrix entries into RangeResults, keeping the
+// edge identity labels. Entries with unparsable values are skipped.
+func (q promQL) parseRangeResults(entries []promMatrixEntry) []RangeResult {
 	results := make([]RangeResult, 0, len(entries))
 	for _, entry := range entries {
-		key := EdgeKey{
-			Name: entry.Metric["name"],
-			Host: entry.Metric["host"],
-			Port: entry.Metric["port"],
-		}
-		status := entry.Metric["status"]
-
 		values, err := parseMatrixValues(entry.Values)
 		if err != nil {
 			continue
 		}
 
+		edge := q.topologyEdge(entry.Metric)
 		results = append(results, RangeResult{
-			Key:    key,
-			Status: status,
-			Values: values,
+			Key:        q.edgeKey(entry.Metric),
+			Namespace:  edge.Namespace,
+			Dependency: edge.Dependency,
+			Type:       edge.Type,
+			Critical:   edge.Critical,
+			Status:     q.status(entry.Metric),
+			Detail:     q.detail(entry.Metric),
+			Values:     values,
 		})
 	}
-	return results, nil
+	return results
 }
 
 // parseMatrixValues parses the raw JSON values from a Prometheus matrix response.
@@ -326,50 +399,28 @@ func parseMatrixValues(raw []json.RawMessage) ([]TimeValue, error) {
 }
 
 func (c *prometheusClient) QueryTopologyEdges(ctx context.Context, opts QueryOptions) ([]TopologyEdge, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryTopologyEdges, f), opts.Time)
+	results, err := c.query(ctx, c.q.topologyEdges(c.q.optFilter(opts)), opts.Time)
 	if err != nil {
 		return nil, err
 	}
 
 	edges := make([]TopologyEdge, 0, len(results))
 	for _, r := range results {
-		edges = append(edges, TopologyEdge{
-			Name:       r.Metric["name"],
-			Namespace:  r.Metric["namespace"],
-			Group:      r.Metric["group"],
-			Dependency: r.Metric["dependency"],
-			Type:       r.Metric["type"],
-			Host:       r.Metric["host"],
-			Port:       r.Metric["port"],
-			Critical:   r.Metric["critical"] == "yes",
-			IsEntry:    r.Metric["isentry"] == "yes",
-		})
+		edges = append(edges, c.q.topologyEdge(r.Metric))
 	}
 	return edges, nil
 }
 
 func (c *prometheusClient) QueryTopologyEdgesLookback(ctx context.Context, opts QueryOptions, lookback time.Duration) ([]TopologyEdge, error) {
-	f := optFilter(opts)
 	lb := formatPromDuration(lookback)
-	results, err := c.query(ctx, fmt.Sprintf(queryTopologyEdgesLookback, f, lb), opts.Time)
+	results, err := c.query(ctx, c.q.topologyEdgesLookback(c.q.optFilter(opts), lb), opts.Time)
 	if err != nil {
 		return nil, err
 	}
 
 	edges := make([]TopologyEdge, 0, len(results))
 	for _, r := range results {
-		edges = append(edges, TopologyEdge{
-			Name:       r.Metric["name"],
-			Namespace:  r.Metric["namespace"],
-			Group:      r.Metric["group"],
-			Dependency: r.Metric["dependency"],
-			Type:       r.Metric["type"],
-			Host:       r.Metric["host"],
-			Port:       r.Metric["port"],
-			Critical:   r.Metric["critical"] == "yes",
-			IsEntry:    r.Metric["isentry"] == "yes",
-		})
+		edges = append(edges, c.q.topologyEdge(r.Metric))
 	}
 	return edges, nil
 }
@@ -386,35 +437,45 @@ func formatPromDuration(d time.Duration) string {
 }
 
 func (c *prometheusClient) QueryHealthState(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryHealthState, f), opts.Time)
+	results, err := c.query(ctx, c.q.healthState(c.q.optFilter(opts)), opts.Time)
 	if err != nil {
 		return nil, err
 	}
-	return parseEdgeValues(results)
+	// The worst (lowest) health across instances wins.
+	return c.q.parseEdgeValues(results, math.Min)
 }
 
 func (c *prometheusClient) QueryAvgLatency(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryAvgLatency, f, f), opts.Time)
+	w := formatPromDuration(c.cfg.LatencyWindow)
+	results, err := c.query(ctx, c.q.avgLatency(c.q.optFilter(opts), w), opts.Time)
+	if err != nil {
+		return nil, err
+	}
+	return c.q.parseEdgeValues(results, math.Max)
+}
+
+func (c *prometheusClient) QueryLatencyQuantile(ctx context.Context, opts QueryOptions, quantile float64) (map[EdgeKey]float64, error) {
+	w := formatPromDuration(c.cfg.LatencyWindow)
+	q := strconv.FormatFloat(quantile, 'f', -1, 64)
+	results, err := c.query(ctx, c.q.latencyQuantile(q, c.q.optFilter(opts), w), opts.Time)
 	if err != nil {
 		return nil, err
 	}
-	return parseEdgeValues(results)
+	return c.q.parseEdgeValues(results, math.Max)
 }
 
-func (c *prometheusClient) QueryP99Latency(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryP99Latency, f), opts.Time)
+func (c *prometheusClient) QueryErrorRatio(ctx context.Context, opts QueryOptions) (map[EdgeKey]float64, error) {
+	w := formatPromDuration(c.cfg.LatencyWindow)
+	results, err := c.query(ctx, c.q.errorRatio(opts, w), opts.Time)
 	if err != nil {
 		return nil, err
 	}
-	return parseEdgeValues(results)
+	return c.q.parseEdgeValues(results, math.Max)
 }
 
 // QueryInstances returns all instances (pods/containers) for a given service.
 func (c *prometheusClient) QueryInstances(ctx context.Context, serviceName string) ([]Instance, error) {
-	results, err := c.query(ctx, fmt.Sprintf(queryInstances, sanitizePromQLValue(serviceName)), nil)
+	results, err := c.query(ctx, c.q.instances(serviceName), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -438,33 +499,27 @@ func (c *prometheusClient) QueryInstances(ctx context.Context, serviceName strin
 }
 
 func (c *prometheusClient) QueryDependencyStatus(ctx context.Context, opts QueryOptions) (map[EdgeKey]string, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryDependencyStatus, f), opts.Time)
+	results, err := c.query(ctx, c.q.dependencyStatus(c.q.optFilter(opts)), opts.Time)
 	if err != nil {
 		return nil, err
 	}
-	return parseEdgeStringValues(results, "status"), nil
+	return c.q.parseEdgeStringValues(results, c.q.status), nil
 }
 
 func (c *prometheusClient) QueryDependencyStatusDetail(ctx context.Context, opts QueryOptions) (map[EdgeKey]string, error) {
-	f := optFilter(opts)
-	results, err := c.query(ctx, fmt.Sprintf(queryDependencyStatusDetail, f), opts.Time)
+	results, err := c.query(ctx, c.q.dependencyStatusDetail(c.q.optFilter(opts)), opts.Time)
 	if err != nil {
 		return nil, err
 	}
-	return parseEdgeStringValues(results, "detail"), nil
+	return c.q.parseEdgeStringValues(results, c.q.detail), nil
 }
 
-// parseEdgeStringValues extracts a named label value per EdgeKey from promResults.
-func parseEdgeStringValues(results []promResult, label string) map[EdgeKey]string {
+// parseEdgeStringValues extracts a label value, read by value, per EdgeKey from promResults.
+func (q promQL) parseEdgeStringValues(results []promResult, value func(map[string]string) string) map[EdgeKey]string {
 	m := make(map[EdgeKey]string, len(results))
 	for _, r := range results {
-		key := EdgeKey{
-			Name: r.Metric["name"],
-			Host: r.Metric["host"],
-			Port: r.Metric["port"],
-		}
-		if v := r.Metric[label]; v != "" {
+		key := q.edgeKey(r.Metric)
+		if v := value(r.Metric); v != "" {
 			m[key] = v
 		}
 	}
@@ -472,45 +527,42 @@ func parseEdgeStringValues(results []promResult, label string) map[EdgeKey]strin
 }
 
 // QueryHistoricalAlerts queries the ALERTS metric at a historical timestamp
-// and returns reconstructed alerts. Labels typically include: alertname,
-// namespace, name (or service), severity.
+// and returns reconstructed alerts. Every series is returned with all of its
+// labels; GraphBuilder attaches them to the topology with its alert mapping.
+// Service falls back to the name, service, then job label.
 func (c *prometheusClient) QueryHistoricalAlerts(ctx context.Context, at time.Time) ([]HistoricalAlert, error) {
-	results, err := c.query(ctx, queryHistoricalAlerts, &at)
+	results, err := c.query(ctx, c.q.historicalAlerts(), &at)
 	if err != nil {
 		return nil, fmt.Errorf("querying historical alerts: %w", err)
 	}
 
 	alerts := make([]HistoricalAlert, 0, len(results))
 	for _, r := range results {
-		svc := r.Metric["name"]
+		svc := r.Metric[c.q.m.Labels.Name]
 		if svc == "" {
 			svc = r.Metric["service"]
 		}
 		if svc == "" {
 			svc = r.Metric["job"]
 		}
-		if svc == "" {
-			continue
-		}
 		alerts = append(alerts, HistoricalAlert{
 			AlertName:  r.Metric["alertname"],
-			Namespace:  r.Metric["namespace"],
+			Namespace:  r.Metric[c.q.m.Labels.Namespace],
 			Service:    svc,
-			Dependency: r.Metric["dependency"],
+			Dependency: r.Metric[c.q.m.Labels.Dependency],
 			Severity:   r.Metric["severity"],
+			Labels:     r.Metric,
 		})
 	}
 	return alerts, nil
 }
 
-func parseEdgeValues(results []promResult) (map[EdgeKey]float64, error) {
+// parseEdgeValues returns the values of results by EdgeKey. merge combines
+// the values of several series of the same edge, e.g. one per instance.
+func (q promQL) parseEdgeValues(results []promResult, merge func(a, b float64) float64) (map[EdgeKey]float64, error) {
 	m := make(map[EdgeKey]float64, len(results))
 	for _, r := range results {
-		key := EdgeKey{
-			Name: r.Metric["name"],
-			Host: r.Metric["host"],
-			Port: r.Metric["port"],
-		}
+		key := q.edgeKey(r.Metric)
 
 		var valStr string
 		if err := json.Unmarshal(r.Value[1], &valStr); err != nil {
@@ -520,12 +572,8 @@ func parseEdgeValues(results []promResult) (map[EdgeKey]float64, error) {
 		if err != nil {
 			continue
 		}
-		// For health: take minimum (worst) across instances.
-		// For latency: take maximum across instances.
 		if existing, ok := m[key]; ok {
-			if val < existing {
-				m[key] = val
-			}
+			m[key] = merge(existing, val)
 		} else {
 			m[key] = val
 		}
@@ -6,6 +6,8 @@ import (
 	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
 )
 
 const topologyEdgesResponse = `{
@@ -154,6 +156,95 @@ func TestQueryLatency(t *testing.T) {
 	}
 }
 
+func TestQueryLatencyWindowAndQuantile(t *testing.T) {
+	var capturedQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedQuery = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(latencyResponse))
+	}))
+	defer srv.Close()
+
+	// Default window is 5m.
+	client := NewPrometheusClient(PrometheusConfig{URL: srv.URL})
+	_, _ = client.QueryAvgLatency(context.Background(), QueryOptions{})
+	want := `rate(app_dependency_latency_seconds_sum[5m]) / rate(app_dependency_latency_seconds_count[5m])`
+	if capturedQuery != want {
+		t.Errorf("avg latency query = %q, want %q", capturedQuery, want)
+	}
+
+	client = NewPrometheusClient(PrometheusConfig{URL: srv.URL, LatencyWindow: 2 * time.Minute})
+	p95, err := client.QueryLatencyQuantile(context.Background(), QueryOptions{Namespace: "prod"}, 0.95)
+	if err != nil {
+		t.Fatalf("QueryLatencyQuantile() error: %v", err)
+	}
+	want = `histogram_quantile(0.95, sum by (le, name, host, port) (rate(app_dependency_latency_seconds_bucket{namespace="prod"}[2m])))`
+	if capturedQuery != want {
+		t.Errorf("quantile query = %q, want %q", capturedQuery, want)
+	}
+	if len(p95) != 2 {
+		t.Errorf("got %d entries, want 2", len(p95))
+	}
+}
+
+func TestQueryLatencyAcrossInstances(t *testing.T) {
+	// Two instances of svc-go talk to the same postgres: pod-a answers within
+	// 5ms, pod-b's buckets sit around 400ms.
+	srv := newTestPromServer(`{"status":"success","data":{"resultType":"vector","result":[
+		{"metric":{"name":"svc-go","host":"pg-primary","port":"5432","instance":"pod-a"},"value":[1700000000,"0.005"]},
+		{"metric":{"name":"svc-go","host":"pg-primary","port":"5432","instance":"pod-b"},"value":[1700000000,"0.4"]}
+	]}}`)
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{URL: srv.URL})
+	key := EdgeKey{Name: "svc-go", Host: "pg-primary", Port: "5432"}
+
+	avg, err := client.QueryAvgLatency(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryAvgLatency() error: %v", err)
+	}
+	if avg[key] != 0.4 {
+		t.Errorf("avg latency = %v, want the slowest instance 0.4", avg[key])
+	}
+	p99, err := client.QueryLatencyQuantile(context.Background(), QueryOptions{}, 0.99)
+	if err != nil {
+		t.Fatalf("QueryLatencyQuantile() error: %v", err)
+	}
+	if p99[key] != 0.4 {
+		t.Errorf("p99 = %v, want 0.4", p99[key])
+	}
+	health, err := client.QueryHealthState(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if health[key] != 0.005 {
+		t.Errorf("health = %v, want the lowest value 0.005", health[key])
+	}
+}
+
+func TestQueryErrorRatio(t *testing.T) {
+	var capturedQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedQuery = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(latencyResponse))
+	}))
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{URL: srv.URL})
+	ratio, err := client.QueryErrorRatio(context.Background(), QueryOptions{Namespace: "prod"})
+	if err != nil {
+		t.Fatalf("QueryErrorRatio() error: %v", err)
+	}
+	want := `sum by (name, host, port) (avg_over_time(app_dependency_status{namespace="prod",status!="ok"}[5m])) / sum by (name, host, port) (avg_over_time(app_dependency_status{namespace="prod"}[5m]))`
+	if capturedQuery != want {
+		t.Errorf("error ratio query = %q, want %q", capturedQuery, want)
+	}
+	if len(ratio) != 2 {
+		t.Errorf("got %d entries, want 2", len(ratio))
+	}
+}
+
 func TestQueryBasicAuth(t *testing.T) {
 	var gotUser, gotPass string
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -200,10 +291,10 @@ func TestQueryWithNamespaceFilter(t *testing.T) {
 }
 
 func TestNsFilter(t *testing.T) {
-	if got := nsFilter(""); got != "" {
+	if got := newPromQL(config.MetricsConfig{}).nsFilter(""); got != "" {
 		t.Errorf("nsFilter(\"\") = %q, want \"\"", got)
 	}
-	if got := nsFilter("prod"); got != `{namespace="prod"}` {
+	if got := newPromQL(config.MetricsConfig{}).nsFilter("prod"); got != `{namespace="prod"}` {
 		t.Errorf("nsFilter(\"prod\") = %q, want {namespace=\"prod\"}", got)
 	}
 }
@@ -350,7 +441,8 @@ func TestParseEdgeStringValues(t *testing.T) {
 		{Metric: map[string]string{"name": "svc-c", "host": "h3", "port": "8080"}}, // no status label
 	}
 
-	m := parseEdgeStringValues(results, "status")
+	q := newPromQL(config.MetricsConfig{})
+	m := q.parseEdgeStringValues(results, q.status)
 	if len(m) != 2 {
 		t.Fatalf("got %d entries, want 2", len(m))
 	}
@@ -425,9 +517,9 @@ func TestQueryHistoricalAlerts(t *testing.T) {
 		t.Fatalf("QueryHistoricalAlerts() error: %v", err)
 	}
 
-	// Third entry has no name/service label, should be skipped.
-	if len(alerts) != 2 {
-		t.Fatalf("got %d alerts, want 2", len(alerts))
+	// Every series is returned; the alert mapping decides which ones attach.
+	if len(alerts) != 3 {
+		t.Fatalf("got %d alerts, want 3", len(alerts))
 	}
 
 	a0 := alerts[0]
@@ -449,6 +541,11 @@ func TestQueryHistoricalAlerts(t *testing.T) {
 	if a1.Service != "svc-python" {
 		t.Errorf("alert[1].Service = %q, want svc-python (from service label)", a1.Service)
 	}
+
+	// Third alert has no service label but keeps its labels for the mapping.
+	if a2 := alerts[2]; a2.Service != "" || a2.Labels["alertname"] != "SomeOtherAlert" {
+		t.Errorf("alert[2] = %+v, want no service and the series labels", a2)
+	}
 }
 
 func TestQueryHistoricalAlertsEmpty(t *testing.T) {
@@ -472,7 +569,7 @@ const queryRangeResponse = `{
     "resultType": "matrix",
     "result": [
       {
-        "metric": {"name": "svc-go", "host": "pg", "port": "5432", "status": "ok"},
+        "metric": {"name": "svc-go", "namespace": "prod", "dependency": "postgres", "type": "postgres", "host": "pg", "port": "5432", "critical": "yes", "status": "ok"},
         "values": [[1700000000, "1"], [1700000015, "1"], [1700000030, "0"]]
       },
       {
@@ -517,6 +614,9 @@ func TestQueryStatusRange(t *testing.T) {
 	if r0.Status != "ok" {
 		t.Errorf("result[0].Status = %q, want ok", r0.Status)
 	}
+	if r0.Namespace != "prod" || r0.Dependency != "postgres" || r0.Type != "postgres" || !r0.Critical {
+		t.Errorf("result[0] identity = %+v, want prod/postgres/postgres/critical", r0)
+	}
 	if len(r0.Values) != 3 {
 		t.Fatalf("result[0] has %d values, want 3", len(r0.Values))
 	}
@@ -552,6 +652,35 @@ func TestQueryStatusRangeWithNamespace(t *testing.T) {
 	}
 }
 
+func TestQueryStatusDetailRange(t *testing.T) {
+	var capturedQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		capturedQuery = r.URL.Query().Get("query")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[
+			{"metric":{"name":"svc-go","host":"pg","port":"5432","detail":"connection_refused"},"values":[[1700000000,"1"]]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{URL: srv.URL})
+	start := time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 11, 15, 1, 0, 0, 0, time.UTC)
+
+	results, err := client.QueryStatusDetailRange(context.Background(), start, end, time.Minute, "prod")
+	if err != nil {
+		t.Fatalf("QueryStatusDetailRange() error: %v", err)
+	}
+
+	want := `app_dependency_status_detail{namespace="prod"} == 1`
+	if capturedQuery != want {
+		t.Errorf("query = %q, want %q", capturedQuery, want)
+	}
+	if len(results) != 1 || results[0].Detail != "connection_refused" {
+		t.Errorf("results = %+v, want one series with detail connection_refused", results)
+	}
+}
+
 func TestQueryErrorStatus(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusServiceUnavailable)
@@ -639,7 +768,7 @@ func TestOptFilter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := optFilter(tt.opts)
+			got := newPromQL(config.MetricsConfig{}).optFilter(tt.opts)
 			if got != tt.want {
 				t.Errorf("optFilter(%+v) = %q, want %q", tt.opts, got, tt.want)
 			}
@@ -672,3 +801,50 @@ func TestQueryWithGroupFilter(t *testing.T) {
 		t.Errorf("combined filter query = %q, want %q", capturedQuery, want)
 	}
 }
+
+func TestQueryCustomMetrics(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[
+			{"metric":{"svc":"svc-go","ns":"prod","dep":"postgres","kind":"postgres","addr":"pg","port":"5432","is_critical":"true","state":"healthy"},"value":[1700000000,"1"]},
+			{"metric":{"svc":"svc-go","ns":"prod","dep":"redis","kind":"redis","addr":"redis","port":"6379","is_critical":"false","state":"timeout"},"value":[1700000000,"1"]}
+		]}}`))
+	}))
+	defer srv.Close()
+
+	m := config.MetricsConfig{
+		Names:    config.MetricNamesConfig{Health: "my_health", Status: "my_status"},
+		Labels:   config.MetricLabelsConfig{Name: "svc", Namespace: "ns", Dependency: "dep", Type: "kind", Host: "addr", Critical: "is_critical", Status: "state"},
+		Values:   config.MetricValuesConfig{Critical: []string{"true"}, StatusOK: "healthy"},
+		Matchers: []string{`env="prod"`},
+	}
+	client := NewPrometheusClient(PrometheusConfig{URL: srv.URL, Metrics: m})
+
+	edges, err := client.QueryTopologyEdges(context.Background(), QueryOptions{Namespace: "prod"})
+	if err != nil {
+		t.Fatalf("QueryTopologyEdges() error: %v", err)
+	}
+	want := `group by (svc, ns, group, dep, kind, addr, port, is_critical, isentry) (my_health{ns="prod",env="prod"})`
+	if queries[0] != want {
+		t.Errorf("query = %q, want %q", queries[0], want)
+	}
+	if len(edges) != 2 || edges[0].Name != "svc-go" || edges[0].Host != "pg" || !edges[0].Critical || edges[1].Critical {
+		t.Errorf("edges = %+v, want svc-go critical on pg and non-critical on redis", edges)
+	}
+
+	statuses, err := client.QueryDependencyStatus(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryDependencyStatus() error: %v", err)
+	}
+	if want := `my_status{env="prod"} == 1`; queries[1] != want {
+		t.Errorf("query = %q, want %q", queries[1], want)
+	}
+	if s := statuses[EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}]; s != "ok" {
+		t.Errorf("status = %q, want ok for the configured healthy value", s)
+	}
+	if s := statuses[EdgeKey{Name: "svc-go", Host: "redis", Port: "6379"}]; s != "timeout" {
+		t.Errorf("status = %q, want timeout", s)
+	}
+}
@@ -0,0 +1,159 @@
+package topology
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// promQL renders the PromQL queries of a client and reads their results,
+// using the metric and label names from config.MetricsConfig.
+type promQL struct {
+	m config.MetricsConfig
+	// edgeLabels and keyLabels are the comma-separated label lists of the
+	// topology edge and EdgeKey aggregations.
+	edgeLabels string
+	keyLabels  string
+}
+
+func newPromQL(m config.MetricsConfig) promQL {
+	m = m.WithDefaults()
+	l := m.Labels
+	return promQL{
+		m:          m,
+		edgeLabels: strings.Join([]string{l.Name, l.Namespace, l.Group, l.Dependency, l.Type, l.Host, l.Port, l.Critical, l.IsEntry}, ", "),
+		keyLabels:  strings.Join([]string{l.Name, l.Host, l.Port}, ", "),
+	}
+}
+
+// topologyEdges groups the health series by edge identity.
+func (q promQL) topologyEdges(f string) string {
+	return fmt.Sprintf(`group by (%s) (%s%s)`, q.edgeLabels, q.m.Names.Health, f)
+}
+
+// topologyEdgesLookback uses last_over_time to include stale series.
+func (q promQL) topologyEdgesLookback(f, lookback string) string {
+	return fmt.Sprintf(`group by (%s) (last_over_time(%s%s[%s]))`, q.edgeLabels, q.m.Names.Health, f, lookback)
+}
+
+func (q promQL) healthState(f string) string {
+	return q.m.Names.Health + f
+}
+
+func (q promQL) avgLatency(f, window string) string {
+	n := q.m.Names.Latency
+	return fmt.Sprintf(`rate(%s_sum%s[%s]) / rate(%s_count%s[%s])`, n, f, window, n, f, window)
+}
+
+// latencyQuantile computes a latency quantile from the histogram buckets,
+// summed across the instances of each edge.
+func (q promQL) latencyQuantile(quantile, f, window string) string {
+	return fmt.Sprintf(`histogram_quantile(%s, sum by (le, %s) (rate(%s_bucket%s[%s])))`,
+		quantile, q.keyLabels, q.m.Names.Latency, f, window)
+}
+
+// errorRatio divides time spent in non-ok statuses by total status time per edge.
+func (q promQL) errorRatio(opts QueryOptions, window string) string {
+	errF := q.filter(opts, fmt.Sprintf(`%s!="%s"`, q.m.Labels.Status, sanitizePromQLValue(q.m.Values.StatusOK)))
+	f := q.filter(opts)
+	n := q.m.Names.Status
+	return fmt.Sprintf(`sum by (%s) (avg_over_time(%s%s[%s])) / sum by (%s) (avg_over_time(%s%s[%s]))`,
+		q.keyLabels, n, errF, window, q.keyLabels, n, f, window)
+}
+
+func (q promQL) instances(service string) string {
+	f := q.filter(QueryOptions{}, fmt.Sprintf(`%s="%s"`, q.m.Labels.Name, sanitizePromQLValue(service)))
+	return fmt.Sprintf(`group by (instance, pod, job) (%s%s)`, q.m.Names.Health, f)
+}
+
+// dependencyStatus selects the active value of the status enum (exactly one
+// series == 1 per endpoint).
+func (q promQL) dependencyStatus(f string) string {
+	return q.m.Names.Status + f + " == 1"
+}
+
+func (q promQL) dependencyStatusDetail(f string) string {
+	return q.m.Names.StatusDetail + f + " == 1"
+}
+
+// historicalAlerts selects the firing series of the ALERTS metric that
+// Prometheus/VictoriaMetrics generates for all alerting rules.
+func (q promQL) historicalAlerts() string {
+	return "ALERTS" + q.filter(QueryOptions{}, `alertstate="firing"`)
+}
+
+// nsFilter returns a PromQL label filter for the given namespace.
+// Returns empty string if namespace is empty and no matchers are configured.
+func (q promQL) nsFilter(ns string) string {
+	return q.filter(QueryOptions{Namespace: ns})
+}
+
+// optFilter returns a PromQL label filter combining namespace and group from QueryOptions.
+// Returns empty string if neither is set. Examples:
+//
+//	{namespace="prod"}, {group="cluster-1"}, {namespace="prod",group="cluster-1"}
+func (q promQL) optFilter(opts QueryOptions) string {
+	return q.filter(opts)
+}
+
+// filter is like optFilter but appends extra raw label matchers, e.g.
+// q.filter(opts, `status!="ok"`), followed by the configured matchers.
+func (q promQL) filter(opts QueryOptions, extra ...string) string {
+	var parts []string
+	if opts.Namespace != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, q.m.Labels.Namespace, sanitizePromQLValue(opts.Namespace)))
+	}
+	if opts.Group != "" {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, q.m.Labels.Group, sanitizePromQLValue(opts.Group)))
+	}
+	parts = append(parts, extra...)
+	for _, m := range q.m.Matchers {
+		parts = append(parts, strings.TrimSpace(m))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// edgeKey returns the EdgeKey of a series.
+func (q promQL) edgeKey(metric map[string]string) EdgeKey {
+	return EdgeKey{
+		Name: metric[q.m.Labels.Name],
+		Host: metric[q.m.Labels.Host],
+		Port: metric[q.m.Labels.Port],
+	}
+}
+
+// topologyEdge returns the TopologyEdge of a series of the edge queries.
+func (q promQL) topologyEdge(metric map[string]string) TopologyEdge {
+	l := q.m.Labels
+	return TopologyEdge{
+		Name:       metric[l.Name],
+		Namespace:  metric[l.Namespace],
+		Group:      metric[l.Group],
+		Dependency: metric[l.Dependency],
+		Type:       metric[l.Type],
+		Host:       metric[l.Host],
+		Port:       metric[l.Port],
+		Critical:   slices.Contains(q.m.Values.Critical, metric[l.Critical]),
+		IsEntry:    slices.Contains(q.m.Values.IsEntry, metric[l.IsEntry]),
+	}
+}
+
+// detail returns the status detail label of a series.
+func (q promQL) detail(metric map[string]string) string {
+	return metric[q.m.Labels.Detail]
+}
+
+// status returns the status label of a series, reporting the configured
+// healthy value as "ok".
+func (q promQL) status(metric map[string]string) string {
+	s := metric[q.m.Labels.Status]
+	if s == q.m.Values.StatusOK {
+		return "ok"
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package topology
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// QueryTiming records one datasource query of a topology build.
+type QueryTiming struct {
+	Query      string `json:"query"`            // name from config.TopologyQueryNames
+	Source     string `json:"source,omitempty"` // Prometheus source name (when set)
+	DurationMs int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+// queryRunner runs the independent datasource queries of a build
+// concurrently, each under its own timeout, and records their timings.
+type queryRunner struct {
+	ctx     context.Context
+	budgets config.QueriesConfig
+	source  string
+
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	timings []QueryTiming
+}
+
+func newQueryRunner(ctx context.Context, budgets config.QueriesConfig, source string) *queryRunner {
+	return &queryRunner{ctx: ctx, budgets: budgets, source: source}
+}
+
+// run starts fn in its own goroutine. fn stores its result itself and
+// returns the query error for the timing record.
+func (r *queryRunner) run(name string, fn func(ctx context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx := r.ctx
+		if d := r.budgets.QueryTimeout(name); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		start := time.Now()
+		err := fn(ctx)
+		r.record(name, time.Since(start), err)
+	}()
+}
+
+func (r *queryRunner) record(name string, d time.Duration, err error) {
+	t := QueryTiming{Query: name, Source: r.source, DurationMs: d.Milliseconds()}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.timings = append(r.timings, t)
+	r.mu.Unlock()
+}
+
+// wait blocks until every started query has returned and returns their
+// timings in the order of config.TopologyQueryNames.
+func (r *queryRunner) wait() []QueryTiming {
+	r.wg.Wait()
+	sortTimings(r.timings)
+	return r.timings
+}
+
+func sortTimings(timings []QueryTiming) {
+	sort.SliceStable(timings, func(i, j int) bool {
+		if timings[i].Source != timings[j].Source {
+			return timings[i].Source < timings[j].Source
+		}
+		return slices.Index(config.TopologyQueryNames, timings[i].Query) < slices.Index(config.TopologyQueryNames, timings[j].Query)
+	})
+}
@@ -0,0 +1,110 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// blockingHealthClient blocks QueryHealthState until its context is done and
+// records the context error it saw.
+type blockingHealthClient struct {
+	*mockPrometheusClient
+	healthCtxErr chan error
+}
+
+func (c *blockingHealthClient) QueryHealthState(ctx context.Context, _ QueryOptions) (map[EdgeKey]float64, error) {
+	<-ctx.Done()
+	c.healthCtxErr <- ctx.Err()
+	return nil, ctx.Err()
+}
+
+func newBlockingHealthClient(m *mockPrometheusClient) *blockingHealthClient {
+	return &blockingHealthClient{mockPrometheusClient: m, healthCtxErr: make(chan error, 1)}
+}
+
+func TestBuild_QueryTimeout(t *testing.T) {
+	prom := newBlockingHealthClient(&mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Namespace: "default", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+		},
+	})
+	builder := NewGraphBuilder(prom, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetQueryBudgets(config.QueriesConfig{
+		Timeout:  5 * time.Second,
+		Timeouts: map[string]time.Duration{"health": 50 * time.Millisecond},
+	})
+
+	start := time.Now()
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Build() took %s, want the health budget to cut it short", elapsed)
+	}
+	if got := <-prom.healthCtxErr; !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("health query context error = %v, want deadline exceeded", got)
+	}
+	if !resp.Meta.Partial || len(resp.Meta.Errors) != 1 || !strings.HasPrefix(resp.Meta.Errors[0], "health state:") {
+		t.Errorf("Meta.Errors = %v, want the health timeout", resp.Meta.Errors)
+	}
+
+	var names []string
+	for _, q := range resp.Meta.Queries {
+		names = append(names, q.Query)
+		if (q.Query == "health") != (q.Error != "") {
+			t.Errorf("query %s error = %q, want an error only for health", q.Query, q.Error)
+		}
+	}
+	want := "edges health avgLatency latencyP50 latencyP95 latencyP99 status statusDetail"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("Meta.Queries = %s, want %s", got, want)
+	}
+}
+
+func TestBuild_EdgeFailureCancelsQueries(t *testing.T) {
+	prom := newBlockingHealthClient(&mockPrometheusClient{edgesErr: errors.New("connection refused")})
+	builder := NewGraphBuilder(prom, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := builder.Build(context.Background(), QueryOptions{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("Build() error = %v, want the edge query error", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Build() did not return: remaining queries were not cancelled")
+	}
+	if got := <-prom.healthCtxErr; !errors.Is(got, context.Canceled) {
+		t.Errorf("health query context error = %v, want canceled", got)
+	}
+}
+
+func TestBuild_BuildTimeout(t *testing.T) {
+	prom := newBlockingHealthClient(&mockPrometheusClient{
+		edges: []TopologyEdge{{Name: "svc-go", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"}},
+	})
+	builder := NewGraphBuilder(prom, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetQueryBudgets(config.QueriesConfig{BuildTimeout: 50 * time.Millisecond})
+
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if got := <-prom.healthCtxErr; !errors.Is(got, context.DeadlineExceeded) {
+		t.Errorf("health query context error = %v, want deadline exceeded", got)
+	}
+	if !resp.Meta.Partial {
+		t.Error("Meta.Partial = false, want true after the build deadline")
+	}
+}
@@ -0,0 +1,185 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// CircuitState is the state of a datasource circuit breaker.
+type CircuitState string
+
+// Circuit breaker states.
+const (
+	CircuitClosed   CircuitState = "closed"    // queries pass through
+	CircuitOpen     CircuitState = "open"      // queries fail immediately
+	CircuitHalfOpen CircuitState = "half-open" // a single probe query decides
+)
+
+// ErrCircuitOpen is returned for queries rejected by an open circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitReporter is implemented by datasource clients with a circuit breaker.
+type CircuitReporter interface {
+	// CircuitState returns the breaker state of the tenant selected in ctx.
+	CircuitState(ctx context.Context) CircuitState
+}
+
+// DatasourceStatus reports the circuit breaker state of a Prometheus source.
+type DatasourceStatus struct {
+	Name    string       `json:"name,omitempty"` // source name (empty for the single default source)
+	Circuit CircuitState `json:"circuit"`
+}
+
+// breaker counts consecutive failed queries and opens after threshold of
+// them. While open it rejects queries; once the open period has passed it
+// lets one probe through, whose outcome closes or re-opens it. A zero
+// threshold disables it.
+type breaker struct {
+	threshold int
+	openFor   time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newBreaker(cfg config.CircuitBreakerConfig) *breaker {
+	return &breaker{
+		threshold: cfg.FailureThreshold,
+		openFor:   cfg.OpenDuration,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// allow reports whether a query may be sent, returning ErrCircuitOpen if not.
+func (b *breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && !b.now().Before(b.openUntil) {
+		b.state = CircuitHalfOpen
+	}
+	switch {
+	case b.state == CircuitOpen:
+		return fmt.Errorf("%w until %s", ErrCircuitOpen, b.openUntil.UTC().Format(time.RFC3339))
+	case b.state == CircuitHalfOpen && b.probing:
+		return fmt.Errorf("%w: probe in progress", ErrCircuitOpen)
+	case b.state == CircuitHalfOpen:
+		b.probing = true
+	}
+	return nil
+}
+
+// success records a query that reached a healthy datasource.
+func (b *breaker) success() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// failure records a failed query. retryAfter, when longer than the open
+// duration, keeps the circuit open for that long.
+func (b *breaker) failure(retryAfter time.Duration) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state != CircuitHalfOpen && b.failures < b.threshold {
+		return
+	}
+	b.state = CircuitOpen
+	b.openUntil = b.now().Add(max(b.openFor, retryAfter))
+	b.probing = false
+}
+
+// neutral records a query whose outcome says nothing about the datasource,
+// e.g. one cancelled by its caller. A pending probe may be retried.
+func (b *breaker) neutral() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// current returns the state, reporting an open circuit whose open period has
+// passed as half-open.
+func (b *breaker) current() CircuitState {
+	if b.threshold <= 0 {
+		return CircuitClosed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitOpen && !b.now().Before(b.openUntil) {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// statusError is a non-200 response from Prometheus.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("prometheus returned %d: %s", e.code, e.body)
+}
+
+// retryable reports whether the response signals overload or a server-side
+// failure that may go away, as opposed to a bad query.
+func (e *statusError) retryable() bool {
+	return e.code == http.StatusTooManyRequests || e.code >= 500
+}
+
+// backoff returns the jittered wait before retry number attempt (0-based):
+// the exponential delay capped at MaxBackoff, of which the upper half is random.
+func backoff(cfg config.RetryConfig, attempt int) time.Duration {
+	d := cfg.Backoff << attempt
+	if d <= 0 || (cfg.MaxBackoff > 0 && d > cfg.MaxBackoff) {
+		d = cfg.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date. It returns 0 when the header is absent or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0)
+	}
+	return 0
+}
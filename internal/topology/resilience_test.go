@@ -0,0 +1,175 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// newFlakyPromServer answers the first failures requests with status (and
+// the Retry-After header, if set), then serves response.
+func newFlakyPromServer(failures int, status int, retryAfter string, response string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if int(calls.Add(1)) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	return srv, &calls
+}
+
+func TestQueryRetriesServerErrors(t *testing.T) {
+	srv, calls := newFlakyPromServer(2, http.StatusServiceUnavailable, "", healthStateResponse)
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:   srv.URL,
+		Retry: config.RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+	})
+	health, err := client.QueryHealthState(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if len(health) == 0 {
+		t.Error("expected health data after retries")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestQueryDoesNotRetryBadRequest(t *testing.T) {
+	srv, calls := newFlakyPromServer(1, http.StatusBadRequest, "", healthStateResponse)
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:            srv.URL,
+		Retry:          config.RetryConfig{MaxAttempts: 3, Backoff: time.Millisecond},
+		CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	})
+	if _, err := client.QueryHealthState(context.Background(), QueryOptions{}); err == nil {
+		t.Fatal("expected error for 400 response")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+	if got := client.(CircuitReporter).CircuitState(context.Background()); got != CircuitClosed {
+		t.Errorf("circuit = %q, want closed after a rejected query", got)
+	}
+}
+
+func TestQueryHonoursRetryAfter(t *testing.T) {
+	srv, calls := newFlakyPromServer(1, http.StatusTooManyRequests, "1", healthStateResponse)
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:   srv.URL,
+		Retry: config.RetryConfig{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	start := time.Now()
+	if _, err := client.QueryHealthState(context.Background(), QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %v, want at least the 1s Retry-After", elapsed)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// A Retry-After beyond the context deadline is not waited for.
+	srv2, calls2 := newFlakyPromServer(1, http.StatusTooManyRequests, "60", healthStateResponse)
+	defer srv2.Close()
+	client = NewPrometheusClient(PrometheusConfig{
+		URL:   srv2.URL,
+		Retry: config.RetryConfig{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := client.QueryHealthState(ctx, QueryOptions{}); err == nil {
+		t.Fatal("expected 429 error when Retry-After exceeds the deadline")
+	}
+	if got := calls2.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	srv, calls := newFlakyPromServer(2, http.StatusInternalServerError, "", healthStateResponse)
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:            srv.URL,
+		Retry:          config.RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 2, OpenDuration: time.Minute},
+	}).(*prometheusClient)
+	now := time.Now()
+	client.breakerFor("").now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := client.QueryHealthState(ctx, QueryOptions{}); err == nil {
+			t.Fatal("expected error for 500 response")
+		}
+	}
+	if got := client.CircuitState(ctx); got != CircuitOpen {
+		t.Fatalf("circuit = %q, want open", got)
+	}
+
+	// While open, queries fail without reaching the datasource.
+	_, err := client.QueryHealthState(ctx, QueryOptions{})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// After the open period a probe goes through and closes the circuit.
+	now = now.Add(time.Minute)
+	if got := client.CircuitState(ctx); got != CircuitHalfOpen {
+		t.Errorf("circuit = %q, want half-open", got)
+	}
+	if _, err := client.QueryHealthState(ctx, QueryOptions{}); err != nil {
+		t.Fatalf("probe error: %v", err)
+	}
+	if got := client.CircuitState(ctx); got != CircuitClosed {
+		t.Errorf("circuit = %q, want closed", got)
+	}
+}
+
+func TestBreakerRetryAfterExtendsOpenPeriod(t *testing.T) {
+	b := newBreaker(config.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Second})
+	now := time.Now()
+	b.now = func() time.Time { return now }
+
+	b.failure(time.Minute)
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() = %v, want ErrCircuitOpen within Retry-After", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() = %v, want probe allowed", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() = %v, want a second probe rejected", err)
+	}
+	b.failure(0)
+	if got := b.current(); got != CircuitOpen {
+		t.Errorf("state = %q, want open after failed probe", got)
+	}
+}
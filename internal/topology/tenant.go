@@ -0,0 +1,84 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// ErrTenantNotAllowed is returned for queries selecting a tenant that the
+// datasource does not allow.
+var ErrTenantNotAllowed = errors.New("tenant not allowed")
+
+// defaultTenantHeader is the tenant header of Mimir, Cortex and Thanos.
+const defaultTenantHeader = "X-Scope-OrgID"
+
+type tenantKey struct{}
+
+// WithTenant returns a context selecting tenant for the queries of
+// multi-tenant datasources made with it. An empty tenant selects each
+// datasource's default.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	if tenant == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant selected with WithTenant, or "".
+func TenantFromContext(ctx context.Context) string {
+	t, _ := ctx.Value(tenantKey{}).(string)
+	return t
+}
+
+// TenantServer is implemented by datasource clients that can tell whether
+// they serve a tenant. Clients that do not implement it serve every tenant.
+type TenantServer interface {
+	ServesTenant(tenant string) bool
+}
+
+// servesTenant reports whether prom serves tenant ("" selects the defaults).
+func servesTenant(prom PrometheusClient, tenant string) bool {
+	ts, ok := prom.(TenantServer)
+	return !ok || ts.ServesTenant(tenant)
+}
+
+// ServesTenant reports whether tenant may be selected on the datasource.
+// Single-tenant datasources ignore the tenant and serve all of them.
+func (c *prometheusClient) ServesTenant(tenant string) bool {
+	_, err := c.resolveTenant(tenant)
+	return err == nil
+}
+
+// tenant returns the tenant to query: the one selected in ctx or the
+// configured default. It is empty for single-tenant datasources.
+func (c *prometheusClient) tenant(ctx context.Context) (string, error) {
+	return c.resolveTenant(TenantFromContext(ctx))
+}
+
+// resolveTenant returns the tenant to query for the selected tenant t.
+func (c *prometheusClient) resolveTenant(t string) (string, error) {
+	cfg := c.cfg.Tenant
+	if cfg.Mode == "" {
+		return "", nil
+	}
+	if t == "" || t == cfg.ID {
+		return cfg.ID, nil
+	}
+	if slices.Contains(cfg.Allowed, t) {
+		return t, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrTenantNotAllowed, t)
+}
+
+// tenantPath returns the API path for tenant: VictoriaMetrics cluster serves
+// each tenant's Prometheus API under /select/<tenant>/prometheus.
+func (c *prometheusClient) tenantPath(tenant, path string) string {
+	if c.cfg.Tenant.Mode != config.TenantModePath {
+		return path
+	}
+	return "/select/" + tenant + "/prometheus" + path
+}
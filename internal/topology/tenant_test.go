@@ -0,0 +1,157 @@
+package topology
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+func TestQueryTenantHeader(t *testing.T) {
+	var orgID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID = r.Header.Get("X-Scope-OrgID")
+		_, _ = w.Write([]byte(healthStateResponse))
+	}))
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:    srv.URL,
+		Tenant: config.TenantConfig{Mode: config.TenantModeHeader, ID: "team-a", Allowed: []string{"team-b"}},
+	})
+
+	if _, err := client.QueryHealthState(context.Background(), QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if orgID != "team-a" {
+		t.Errorf("X-Scope-OrgID = %q, want default tenant %q", orgID, "team-a")
+	}
+
+	if _, err := client.QueryHealthState(WithTenant(context.Background(), "team-b"), QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if orgID != "team-b" {
+		t.Errorf("X-Scope-OrgID = %q, want selected tenant %q", orgID, "team-b")
+	}
+
+	orgID = ""
+	_, err := client.QueryHealthState(WithTenant(context.Background(), "team-c"), QueryOptions{})
+	if !errors.Is(err, ErrTenantNotAllowed) {
+		t.Errorf("error = %v, want ErrTenantNotAllowed", err)
+	}
+	if orgID != "" {
+		t.Error("a query for a tenant that is not allowed must not be sent")
+	}
+}
+
+func TestQueryTenantPath(t *testing.T) {
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		_, _ = w.Write([]byte(healthStateResponse))
+	}))
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:    srv.URL,
+		Tenant: config.TenantConfig{Mode: config.TenantModePath, ID: "0", Allowed: []string{"42:1"}},
+	})
+	if _, err := client.QueryHealthState(WithTenant(context.Background(), "42:1"), QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if want := "/select/42:1/prometheus/api/v1/query"; path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+
+	// Single-tenant datasources ignore the selected tenant.
+	client = NewPrometheusClient(PrometheusConfig{URL: srv.URL})
+	if _, err := client.QueryHealthState(WithTenant(context.Background(), "42:1"), QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	if path != "/api/v1/query" {
+		t.Errorf("path = %q, want %q", path, "/api/v1/query")
+	}
+}
+
+func TestBuildSkipsSourcesNotServingTenant(t *testing.T) {
+	var mu sync.Mutex
+	var queried []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queried = append(queried, r.Header.Get("X-Scope-OrgID"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer srv.Close()
+
+	newClient := func(allowed ...string) PrometheusClient {
+		return NewPrometheusClient(PrometheusConfig{
+			URL:    srv.URL,
+			Tenant: config.TenantConfig{Mode: config.TenantModeHeader, ID: "team-a", Allowed: allowed},
+		})
+	}
+	builder := NewGraphBuilder(nil, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetSources([]Source{{Name: "eu", Prom: newClient("team-b")}, {Name: "us", Prom: newClient()}})
+
+	resp, err := builder.Build(WithTenant(context.Background(), "team-b"), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if resp.Meta.Partial {
+		t.Errorf("Meta.Partial = true, want false; errors: %v", resp.Meta.Errors)
+	}
+	if len(resp.Meta.Datasources) != 1 || resp.Meta.Datasources[0].Name != "eu" {
+		t.Errorf("Meta.Datasources = %v, want only the eu source", resp.Meta.Datasources)
+	}
+	for _, orgID := range queried {
+		if orgID != "team-b" {
+			t.Errorf("queried tenant %q, want only team-b", orgID)
+		}
+	}
+	if sources := builder.Sources(context.Background()); len(sources) != 2 {
+		t.Errorf("Sources() = %d sources for the default tenants, want 2", len(sources))
+	}
+}
+
+func TestCircuitBreakerPerTenant(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Scope-OrgID") == "team-b" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(healthStateResponse))
+	}))
+	defer srv.Close()
+
+	client := NewPrometheusClient(PrometheusConfig{
+		URL:            srv.URL,
+		Tenant:         config.TenantConfig{Mode: config.TenantModeHeader, ID: "team-a", Allowed: []string{"team-b"}},
+		Retry:          config.RetryConfig{MaxAttempts: 1},
+		CircuitBreaker: config.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	})
+	teamB := WithTenant(context.Background(), "team-b")
+	if _, err := client.QueryHealthState(teamB, QueryOptions{}); err == nil {
+		t.Fatal("expected error for 503 response")
+	}
+	if _, err := client.QueryHealthState(teamB, QueryOptions{}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("error = %v, want ErrCircuitOpen", err)
+	}
+
+	// The default tenant keeps its own, closed breaker.
+	ctx := context.Background()
+	if _, err := client.QueryHealthState(ctx, QueryOptions{}); err != nil {
+		t.Fatalf("QueryHealthState() error: %v", err)
+	}
+	r := client.(CircuitReporter)
+	if got := r.CircuitState(ctx); got != CircuitClosed {
+		t.Errorf("default tenant circuit = %q, want closed", got)
+	}
+	if got := r.CircuitState(teamB); got != CircuitOpen {
+		t.Errorf("team-b circuit = %q, want open", got)
+	}
+}
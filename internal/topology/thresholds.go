@@ -0,0 +1,105 @@
+package topology
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+// LatencyThresholds configures latency-based edge degradation.
+type LatencyThresholds struct {
+	// Percentile compared against the thresholds: "p50", "p95" or "p99" (default "p99").
+	Percentile string
+	// ByType maps dependency type to the latency above which an edge is degraded.
+	// The "default" key applies to types without an explicit entry.
+	ByType map[string]time.Duration
+}
+
+// threshold returns the latency threshold for a dependency type,
+// or 0 when no threshold applies.
+func (t LatencyThresholds) threshold(depType string) time.Duration {
+	if d, ok := t.ByType[depType]; ok {
+		return d
+	}
+	return t.ByType["default"]
+}
+
+// matchThresholdRule returns the most specific rule matching the edge, or nil.
+// Specificity: dependency > service > namespace > type; ties go to the first rule.
+func matchThresholdRule(rules []config.ThresholdRule, e TopologyEdge) *config.ThresholdRule {
+	var best *config.ThresholdRule
+	bestScore := -1
+	for i := range rules {
+		r := &rules[i]
+		score := 0
+		if r.Type != "" {
+			if r.Type != e.Type {
+				continue
+			}
+			score++
+		}
+		if r.Namespace != "" {
+			if r.Namespace != e.Namespace {
+				continue
+			}
+			score += 2
+		}
+		if r.Service != "" {
+			if r.Service != e.Name {
+				continue
+			}
+			score += 4
+		}
+		if r.Dependency != "" {
+			if r.Dependency != e.Dependency {
+				continue
+			}
+			score += 8
+		}
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+// needsErrorRatio reports whether any threshold rule uses an error ratio limit.
+func (b *GraphBuilder) needsErrorRatio() bool {
+	for _, r := range b.thresholdRules {
+		if r.ErrorRatio > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// degradedReason evaluates latency and error ratio limits for a healthy edge
+// and returns a human-readable reason when any is breached, or "" otherwise.
+// A matching threshold rule wins over the per-type latency thresholds.
+func (b *GraphBuilder) degradedReason(e TopologyEdge, pct edgePercentiles, errRatio float64) string {
+	percentile := b.latencyThresholds.Percentile
+	if percentile == "" {
+		percentile = "p99"
+	}
+	latencyLimit := b.latencyThresholds.threshold(e.Type)
+	var errLimit float64
+
+	if r := matchThresholdRule(b.thresholdRules, e); r != nil {
+		latencyLimit = r.Latency
+		errLimit = r.ErrorRatio
+		if r.Percentile != "" {
+			percentile = r.Percentile
+		}
+	}
+
+	var reasons []string
+	if latencyLimit > 0 && pct.get(percentile) > latencyLimit.Seconds() {
+		reasons = append(reasons, fmt.Sprintf("%s latency > %s", percentile, latencyLimit))
+	}
+	if errLimit > 0 && errRatio > errLimit {
+		reasons = append(reasons, fmt.Sprintf("error ratio > %g%%", errLimit*100))
+	}
+	return strings.Join(reasons, "; ")
+}
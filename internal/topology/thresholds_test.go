@@ -0,0 +1,136 @@
+package topology
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/BigKAA/dephealth-ui/internal/config"
+)
+
+func TestMatchThresholdRule(t *testing.T) {
+	rules := []config.ThresholdRule{
+		{Latency: time.Second},                                  // 0: global
+		{Type: "postgres", Latency: 200 * time.Millisecond},     // 1: type
+		{Namespace: "prod", Latency: 100 * time.Millisecond},    // 2: namespace
+		{Service: "svc-a", Dependency: "pg", ErrorRatio: 0.01},  // 3: exact edge
+		{Type: "postgres", Namespace: "prod", ErrorRatio: 0.05}, // 4: type + namespace
+		{Namespace: "prod", Latency: 50 * time.Millisecond},     // 5: duplicate of 2
+	}
+
+	tests := []struct {
+		name string
+		edge TopologyEdge
+		want int // index into rules, -1 for nil
+	}{
+		{"global fallback", TopologyEdge{Name: "svc-b", Type: "redis", Namespace: "dev"}, 0},
+		{"type", TopologyEdge{Name: "svc-b", Type: "postgres", Namespace: "dev"}, 1},
+		{"namespace beats type, first wins ties", TopologyEdge{Name: "svc-b", Type: "redis", Namespace: "prod"}, 2},
+		{"type and namespace", TopologyEdge{Name: "svc-b", Type: "postgres", Namespace: "prod"}, 4},
+		{"exact edge", TopologyEdge{Name: "svc-a", Dependency: "pg", Type: "postgres", Namespace: "prod"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchThresholdRule(rules, tt.edge)
+			if got != &rules[tt.want] {
+				t.Errorf("matchThresholdRule() = %+v, want rules[%d]", got, tt.want)
+			}
+		})
+	}
+
+	if got := matchThresholdRule(rules[1:2], TopologyEdge{Type: "redis"}); got != nil {
+		t.Errorf("matchThresholdRule() = %+v, want nil", got)
+	}
+}
+
+func TestDegradedReason(t *testing.T) {
+	b := NewGraphBuilder(nil, nil, GrafanaConfig{}, 0, 0, nil, nil)
+	b.SetLatencyThresholds(LatencyThresholds{ByType: map[string]time.Duration{"redis": 10 * time.Millisecond}})
+	b.SetThresholdRules([]config.ThresholdRule{
+		{Namespace: "prod", Latency: 100 * time.Millisecond, Percentile: "p95", ErrorRatio: 0.05},
+	})
+
+	pct := edgePercentiles{p50: 0.02, p95: 0.15, p99: 0.3}
+
+	// Per-type latency threshold applies when no rule matches (default percentile p99).
+	if got := b.degradedReason(TopologyEdge{Type: "redis", Namespace: "dev"}, pct, 0); got != "p99 latency > 10ms" {
+		t.Errorf("reason = %q, want %q", got, "p99 latency > 10ms")
+	}
+	// No rule and no type threshold → healthy.
+	if got := b.degradedReason(TopologyEdge{Type: "http", Namespace: "dev"}, pct, 0.5); got != "" {
+		t.Errorf("reason = %q, want empty", got)
+	}
+	// Matching rule uses its own percentile and error ratio.
+	got := b.degradedReason(TopologyEdge{Type: "redis", Namespace: "prod"}, pct, 0.2)
+	if want := "p95 latency > 100ms; error ratio > 5%"; got != want {
+		t.Errorf("reason = %q, want %q", got, want)
+	}
+	if got := b.degradedReason(TopologyEdge{Type: "redis", Namespace: "prod"}, edgePercentiles{p95: 0.05}, 0.01); got != "" {
+		t.Errorf("reason = %q, want empty below limits", got)
+	}
+}
+
+func TestGraphBuilder_ThresholdRules(t *testing.T) {
+	pgKey := EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}
+	redisKey := EdgeKey{Name: "svc-go", Host: "redis", Port: "6379"}
+	mock := &mockPrometheusClient{
+		edges: []TopologyEdge{
+			{Name: "svc-go", Namespace: "prod", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432", Critical: true},
+			{Name: "svc-go", Namespace: "prod", Dependency: "redis", Type: "redis", Host: "redis", Port: "6379"},
+		},
+		health:     map[EdgeKey]float64{pgKey: 1, redisKey: 0},
+		errorRatio: map[EdgeKey]float64{pgKey: 0.12, redisKey: 1},
+	}
+
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetThresholdRules([]config.ThresholdRule{{Type: "postgres", ErrorRatio: 0.1}})
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+
+	edgeByTarget := make(map[string]Edge)
+	for _, e := range resp.Edges {
+		edgeByTarget[e.Target] = e
+	}
+	pg := edgeByTarget["pg:5432"]
+	if pg.State != "degraded" || pg.DegradedReason != "error ratio > 10%" {
+		t.Errorf("pg edge State/DegradedReason = %q/%q, want degraded/%q", pg.State, pg.DegradedReason, "error ratio > 10%")
+	}
+	if pg.ErrorRatio != 0.12 {
+		t.Errorf("pg edge ErrorRatio = %v, want 0.12", pg.ErrorRatio)
+	}
+	// Down edges stay down without a degraded reason.
+	redis := edgeByTarget["redis:6379"]
+	if redis.State != "down" || redis.DegradedReason != "" {
+		t.Errorf("redis edge State/DegradedReason = %q/%q, want down/empty", redis.State, redis.DegradedReason)
+	}
+}
+
+func TestGraphBuilder_ErrorRatioQueriedOnlyWhenNeeded(t *testing.T) {
+	key := EdgeKey{Name: "svc-go", Host: "pg", Port: "5432"}
+	mock := &mockPrometheusClient{
+		edges:         []TopologyEdge{{Name: "svc-go", Dependency: "postgres", Type: "postgres", Host: "pg", Port: "5432"}},
+		health:        map[EdgeKey]float64{key: 1},
+		errorRatioErr: context.DeadlineExceeded,
+	}
+
+	builder := NewGraphBuilder(mock, nil, GrafanaConfig{}, 15*time.Second, 0, nil, testSeverityLevels())
+	builder.SetThresholdRules([]config.ThresholdRule{{Type: "postgres", Latency: time.Second}})
+	resp, err := builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if resp.Meta.Partial {
+		t.Errorf("Meta.Errors = %v, error ratio should not be queried without errorRatio rules", resp.Meta.Errors)
+	}
+
+	builder.SetThresholdRules([]config.ThresholdRule{{Type: "postgres", ErrorRatio: 0.1}})
+	resp, err = builder.Build(context.Background(), QueryOptions{})
+	if err != nil {
+		t.Fatalf("Build() error: %v", err)
+	}
+	if !resp.Meta.Partial || len(resp.Meta.Errors) != 1 {
+		t.Errorf("Meta.Errors = %v, want one error ratio error", resp.Meta.Errors)
+	}
+}